@@ -0,0 +1,4 @@
+// Package adv1 holds the Go code generated from ad.proto.
+package adv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative ad/v1/ad.proto
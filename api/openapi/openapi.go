@@ -0,0 +1,67 @@
+// Package openapi holds the OpenAPI 3 description of the REST API.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+// Load parses and validates the embedded document.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return doc, nil
+}
+
+// CheckRoutes reports routes that are missing from the document and
+// operations in the document that no route serves.
+func CheckRoutes(doc *openapi3.T, routes chi.Routes) error {
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	var undocumented []string
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		key := method + " " + route
+		if documented[key] {
+			delete(documented, key)
+		} else {
+			undocumented = append(undocumented, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, key := range undocumented {
+		problems = append(problems, "undocumented route "+key)
+	}
+	for key := range documented {
+		problems = append(problems, "documented operation without route "+key)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("OpenAPI document and routes diverge: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
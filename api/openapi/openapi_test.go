@@ -0,0 +1,64 @@
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"ad-service/api/openapi"
+	"ad-service/internal/delivery/handler"
+	"ad-service/internal/delivery/router"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/pkg/logger"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCheckRoutes(t *testing.T) {
+	loggers, err := logger.SetupLogger("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := chi.NewRouter()
+	router.SetupAdRoutes(routes, nil, nil, loggers, metrics.NewHandlerMetrics(), handler.VersionOptions{})
+
+	tests := []struct {
+		name    string
+		modify  func(doc *openapi3.T)
+		problem string
+	}{
+		{name: "in sync", modify: func(doc *openapi3.T) {}},
+		{
+			name:    "route missing from the document",
+			modify:  func(doc *openapi3.T) { doc.Paths.Delete("/v2/ads/{id}") },
+			problem: "undocumented route GET /v2/ads/{id}",
+		},
+		{
+			name: "operation without a route",
+			modify: func(doc *openapi3.T) {
+				doc.Paths.Set("/v2/ads/{id}/archive", &openapi3.PathItem{Post: &openapi3.Operation{}})
+			},
+			problem: "documented operation without route POST /v2/ads/{id}/archive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := openapi.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.modify(doc)
+
+			err = openapi.CheckRoutes(doc, routes)
+			if tt.problem == "" {
+				if err != nil {
+					t.Fatalf("CheckRoutes() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Fatalf("CheckRoutes() error = %v, want it to report %q", err, tt.problem)
+			}
+		})
+	}
+}
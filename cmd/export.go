@@ -0,0 +1,136 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"ad-service/internal/config"
+	"ad-service/internal/delivery/handler"
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/cache"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+)
+
+// runExport implements the export command, which writes the same export as
+// GET /ads/export to a file. It returns the exit code.
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "-", "output file, - for standard output")
+	format := flags.String("format", "", "ndjson or csv (default from the extension of -o, else ndjson)")
+	compress := flags.Bool("gzip", false, "gzip the output, implied by a .gz output file")
+	version := flags.Int("version", int(handler.APIv2), "API version whose representation is exported")
+	filterQuery := flags.String("filter", "", "listing filters as a query string, such as category=cars&tags=new")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	apiVersion := handler.APIVersion(*version)
+	if !apiVersion.IsValid() {
+		fmt.Fprintf(os.Stderr, "export: unsupported API version %d\n", *version)
+		return 2
+	}
+	filter, err := handler.ParseExportFilter(*filterQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: invalid filter: %v\n", err)
+		return 2
+	}
+	if strings.HasSuffix(*output, ".gz") {
+		*compress = true
+	}
+	if *format == "" {
+		*format = "ndjson"
+		if filepath.Ext(strings.TrimSuffix(*output, ".gz")) == ".csv" {
+			*format = "csv"
+		}
+	}
+
+	cfg := config.MustLoadConfig()
+
+	loggers, err := logger.SetupLogger(cfg.Logger.Level)
+	if err != nil {
+		log.Fatalf("Failed to set up logger: %v", err)
+	}
+
+	db, cleanupDB := setupDatabase(cfg, loggers)
+	defer cleanupDB()
+
+	rdb, cleanupRedis := setupRedis(cfg, loggers)
+	defer cleanupRedis()
+
+	repositoryMetrics := metrics.NewRepositoryMetrics()
+	adService := service.NewAdService(
+		repository.NewMysqlAdRepository(db, cache.NewRedisCache(rdb), repositoryMetrics),
+		repository.NewMysqlAttributeRepository(db, repositoryMetrics),
+		repository.NewMysqlCampaignRepository(db, repositoryMetrics),
+		metrics.NewServiceMetrics(),
+	)
+	exporter := handler.NewAdExporter(adService, apiVersion, versionOptions(cfg, loggers).Currency)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	count, err := exportToFile(ctx, exporter, *output, *format, *compress, filter)
+	if err != nil {
+		loggers.ErrorLogger.Error("Failed to export ads", "output", *output, utils.Err(err))
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	loggers.InfoLogger.Info("Exported ads", "output", *output, "format", *format, "count", count)
+	fmt.Fprintf(os.Stderr, "exported %d ads\n", count)
+	return 0
+}
+
+// exportToFile writes the export next to path and renames it into place
+// once it is complete, so that a failed export leaves no partial file.
+func exportToFile(ctx context.Context, exporter *handler.AdExporter, path, format string, compress bool, filter domain.AdFilter) (int64, error) {
+	if path == "-" {
+		return exportTo(ctx, exporter, os.Stdout, format, compress, filter)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	if err := file.Chmod(0o644); err != nil {
+		file.Close()
+		return 0, err
+	}
+
+	count, err := exportTo(ctx, exporter, file, format, compress, filter)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return count, err
+	}
+	return count, os.Rename(file.Name(), path)
+}
+
+func exportTo(ctx context.Context, exporter *handler.AdExporter, w io.Writer, format string, compress bool, filter domain.AdFilter) (int64, error) {
+	if !compress {
+		return exporter.Export(ctx, w, format, filter)
+	}
+
+	gz := gzip.NewWriter(w)
+	// Hide the Flush method of the gzip writer: nobody reads a file while
+	// it is written, and flushing would cost compression.
+	count, err := exporter.Export(ctx, struct{ io.Writer }{gz}, format, filter)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	return count, err
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"ad-service/internal/config"
+	"ad-service/internal/delivery/handler"
+	"ad-service/internal/infrastructure/cache"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+)
+
+// runImport implements the import command, which runs the same import as
+// POST /ads/import on a file and waits for it. It returns the exit code.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "ndjson or csv (default from the extension of the file, else ndjson)")
+	dryRun := flags.Bool("dry-run", false, "validate and count the rows without saving them")
+	version := flags.Int("version", int(handler.APIv2), "API version whose representation is imported")
+	errorsPath := flags.String("errors", "", "write the rows that were skipped to this CSV file")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: import [flags] <file>, - for standard input")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	apiVersion := handler.APIVersion(*version)
+	if !apiVersion.IsValid() {
+		fmt.Fprintf(os.Stderr, "import: unsupported API version %d\n", *version)
+		return 2
+	}
+	if *format == "" {
+		*format = "ndjson"
+		if filepath.Ext(strings.TrimSuffix(path, ".gz")) == ".csv" {
+			*format = "csv"
+		}
+	}
+
+	cfg := config.MustLoadConfig()
+
+	loggers, err := logger.SetupLogger(cfg.Logger.Level)
+	if err != nil {
+		log.Fatalf("Failed to set up logger: %v", err)
+	}
+
+	importer := handler.NewAdImporter(apiVersion, versionOptions(cfg, loggers).Currency)
+	newReader, err := importer.Reader(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 2
+	}
+
+	input, err := openImport(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 1
+	}
+	defer input.Close()
+
+	db, cleanupDB := setupDatabase(cfg, loggers)
+	defer cleanupDB()
+
+	rdb, cleanupRedis := setupRedis(cfg, loggers)
+	defer cleanupRedis()
+
+	repositoryMetrics := metrics.NewRepositoryMetrics()
+	importService := service.NewImportService(
+		repository.NewMysqlAdRepository(db, cache.NewRedisCache(rdb), repositoryMetrics),
+		repository.NewMysqlAttributeRepository(db, repositoryMetrics),
+		repository.NewMysqlCampaignRepository(db, repositoryMetrics),
+		repository.NewMysqlImportRepository(db, repositoryMetrics),
+		nil,
+		nil,
+		metrics.NewServiceMetrics(),
+		loggers,
+		importOptions(cfg),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	job, err := importService.RunImport(ctx, *format, *dryRun, newReader(input))
+	if err != nil {
+		loggers.ErrorLogger.Error("Failed to import ads", "input", path, utils.Err(err))
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		if job == nil {
+			return 1
+		}
+	}
+
+	summary := fmt.Sprintf("%d rows: %d created, %d updated, %d skipped", job.Total, job.Created, job.Updated, job.Failed)
+	if job.DryRun {
+		summary += " (dry run, nothing was saved)"
+	}
+	loggers.InfoLogger.Info("Imported ads", "input", path, "import_id", job.ID, "total", job.Total, "created", job.Created, "updated", job.Updated, "failed", job.Failed)
+	fmt.Fprintf(os.Stderr, "import %s: %s\n", job.ID, summary)
+
+	if *errorsPath != "" && job.Failed > 0 {
+		if err := writeImportErrors(importService, job.ID, *errorsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "import: failed to write the error report: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "skipped rows written to %s\n", *errorsPath)
+	}
+
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// openImport opens the import file, or standard input for -, and
+// decompresses .gz files.
+func openImport(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, file}, nil
+}
+
+func writeImportErrors(importService service.ImportService, id, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// The job is finished, so the report no longer depends on the
+	// interrupted context of the import.
+	err = handler.WriteImportErrors(context.Background(), file, importService, id)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -5,16 +5,24 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"ad-service/api/openapi"
 	"ad-service/internal/config"
+	"ad-service/internal/delivery/gql"
+	"ad-service/internal/delivery/handler"
 	"ad-service/internal/delivery/router"
+	"ad-service/internal/delivery/rpc"
 	"ad-service/internal/infrastructure/cache"
 	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/infrastructure/notifier"
+	"ad-service/internal/infrastructure/pubsub"
+	"ad-service/internal/infrastructure/webhook"
 	"ad-service/internal/repository"
 	"ad-service/internal/service"
 	"ad-service/pkg/database"
@@ -24,9 +32,20 @@ import (
 	"github.com/go-chi/chi/v5"
 	redisClient "github.com/go-redis/redis/v8"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		}
+	}
+
 	cfg := config.MustLoadConfig()
 
 	loggers, err := logger.SetupLogger(cfg.Logger.Level)
@@ -38,8 +57,9 @@ func main() {
 	db, cleanupDB := setupDatabase(cfg, loggers)
 	defer cleanupDB()
 
-	redisCache, cleanupRedis := setupRedis(cfg, loggers)
+	rdb, cleanupRedis := setupRedis(cfg, loggers)
 	defer cleanupRedis()
+	redisCache := cache.NewRedisCache(rdb)
 
 	tracerProvider := setupTracer(cfg, loggers)
 	defer shutdownTracer(tracerProvider, loggers)
@@ -50,16 +70,100 @@ func main() {
 	loggers.InfoLogger.Info("Prometheus metrics initialized")
 
 	adRepo := repository.NewMysqlAdRepository(db, redisCache, repositoryMetrics)
-	adService := service.NewAdService(adRepo, serviceMetrics)
+	attributeRepo := repository.NewMysqlAttributeRepository(db, repositoryMetrics)
+	campaignRepo := repository.NewMysqlCampaignRepository(db, repositoryMetrics)
+	savedSearchService := setupSavedSearches(cfg, db, serviceMetrics, repositoryMetrics, loggers)
+	defer stopSavedSearches(savedSearchService, loggers)
+	webhookService := setupWebhooks(cfg, db, serviceMetrics, repositoryMetrics, loggers)
+	defer stopWebhooks(webhookService, loggers)
+	streamService := setupStream(cfg, rdb, serviceMetrics, loggers)
+	publishers := service.NewPublishers(serviceMetrics,
+		service.NamedPublisher{Name: "saved_searches", Publisher: savedSearchService},
+		service.NamedPublisher{Name: "webhooks", Publisher: webhookService},
+		service.NamedPublisher{Name: "stream", Publisher: streamService},
+	)
+	outboxRelay := setupOutboxRelay(cfg, db, publishers, serviceMetrics, repositoryMetrics, loggers)
+	defer stopOutboxRelay(outboxRelay, loggers)
+	adService := service.NewAdService(adRepo, attributeRepo, campaignRepo, serviceMetrics)
+	attributeService := service.NewAttributeService(attributeRepo, serviceMetrics)
+	tagService := service.NewTagService(repository.NewMysqlTagRepository(db, repositoryMetrics), serviceMetrics)
+	jobService := setupJobs(cfg, db, serviceMetrics, repositoryMetrics, loggers)
+	campaignService := service.NewCampaignService(campaignRepo, adService, jobService, service.BillingOptions{
+		CPC: cfg.Billing.CPC,
+		CPM: cfg.Billing.CPM,
+	}, serviceMetrics)
+	servingService := service.NewServingService(adRepo, campaignRepo, redisCache, serviceMetrics, service.ServingOptions{
+		FrequencyCap:    cfg.Serving.FrequencyCap,
+		FrequencyWindow: cfg.Serving.FrequencyWindow,
+		CandidateTTL:    cfg.Serving.CandidateTTL,
+		MaxCandidates:   cfg.Serving.MaxCandidates,
+	})
+	variantService := service.NewVariantService(repository.NewMysqlVariantRepository(db, repositoryMetrics), adRepo, serviceMetrics)
+	favoriteService := service.NewFavoriteService(repository.NewMysqlFavoriteRepository(db, redisCache, repositoryMetrics), adRepo, serviceMetrics)
+	trackingService := setupTracking(cfg, db, adRepo, campaignService, serviceMetrics, repositoryMetrics, loggers)
+	defer stopTracking(trackingService, loggers)
+	importService := setupImports(cfg, db, adRepo, attributeRepo, campaignRepo, jobService, serviceMetrics, repositoryMetrics, loggers)
+	// The services above register their job types before the workers start.
+	jobService.Start()
+	loggers.InfoLogger.Info("Job workers started")
+	defer stopJobs(jobService, loggers)
 	loggers.InfoLogger.Info("Service and repository layers initialized")
 
+	apiDoc, err := openapi.Load()
+	if err != nil {
+		loggers.ErrorLogger.Error("Failed to load OpenAPI document", utils.Err(err))
+		os.Exit(1)
+	}
+
 	r := chi.NewRouter()
-	router.SetupAdRoutes(r, adService, loggers, handlerMetrics)
+	r.Use(handler.RequestID)
+	router.SetupCompression(r, handler.CompressOptions{
+		MinSize:      cfg.Compression.MinSize,
+		ContentTypes: cfg.Compression.ContentTypes,
+		GzipLevel:    cfg.Compression.GzipLevel,
+		BrotliLevel:  cfg.Compression.BrotliLevel,
+	})
+	router.SetupCaching(r, cachePolicies(cfg, loggers))
+	router.SetupVersioning(r)
+	if err := router.SetupValidation(r, apiDoc, loggers, cfg.OpenAPI.ValidateResponses); err != nil {
+		loggers.ErrorLogger.Error("Failed to set up request validation", utils.Err(err))
+		os.Exit(1)
+	}
+	router.SetupAdRoutes(r, adService, importService, loggers, handlerMetrics, versionOptions(cfg, loggers))
+	router.SetupImportRoutes(r, importService, loggers, handlerMetrics)
+	router.SetupJobRoutes(r, jobService, loggers, handlerMetrics)
+	router.SetupAttributeRoutes(r, attributeService, loggers, handlerMetrics)
+	router.SetupTagRoutes(r, tagService, loggers, handlerMetrics)
+	router.SetupTrackingRoutes(r, trackingService, adService, loggers, handlerMetrics)
+	router.SetupCampaignRoutes(r, campaignService, loggers, handlerMetrics)
+	router.SetupServingRoutes(r, servingService, loggers, handlerMetrics)
+	router.SetupVariantRoutes(r, variantService, loggers, handlerMetrics)
+	router.SetupFavoriteRoutes(r, favoriteService, loggers, handlerMetrics)
+	router.SetupSavedSearchRoutes(r, savedSearchService, loggers, handlerMetrics)
+	router.SetupWebhookRoutes(r, webhookService, loggers, handlerMetrics)
+	router.SetupStreamRoutes(r, streamService, loggers, handlerMetrics)
+	if err := router.SetupGraphQLRoutes(r, adService, loggers, handlerMetrics, gql.Options{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	}); err != nil {
+		loggers.ErrorLogger.Error("Failed to build GraphQL schema", utils.Err(err))
+		os.Exit(1)
+	}
+	if err := router.SetupDocsRoutes(r, apiDoc, loggers, handlerMetrics); err != nil {
+		loggers.ErrorLogger.Error("Failed to set up API docs", utils.Err(err))
+		os.Exit(1)
+	}
 	loggers.InfoLogger.Info("Router and routes initialized")
 
 	r.Handle("/metrics", handlerMetrics.HTTPHandler())
 
+	grpcServer := startGRPCServer(cfg, rpc.NewAdServer(adService, streamService, loggers), loggers)
+	defer stopGRPCServer(grpcServer, loggers)
+
 	server := startServer(cfg, r, loggers)
+	// Open event streams never finish on their own; closing them lets the
+	// graceful shutdown complete.
+	server.RegisterOnShutdown(func() { stopStream(streamService, loggers) })
 
 	waitForShutdown(server, loggers)
 }
@@ -88,7 +192,7 @@ func setupDatabase(cfg *config.Config, loggers *logger.Loggers) (*sql.DB, func()
 	return db, cleanup
 }
 
-func setupRedis(cfg *config.Config, loggers *logger.Loggers) (cache.Cache, func()) {
+func setupRedis(cfg *config.Config, loggers *logger.Loggers) (*redisClient.Client, func()) {
 	rdb := redisClient.NewClient(&redisClient.Options{
 		Addr:     cfg.Redis.Addr,
 		Password: cfg.Redis.Password,
@@ -107,7 +211,7 @@ func setupRedis(cfg *config.Config, loggers *logger.Loggers) (cache.Cache, func(
 		}
 	}
 
-	return cache.NewRedisCache(rdb), cleanup
+	return rdb, cleanup
 }
 
 func setupTracer(cfg *config.Config, loggers *logger.Loggers) *sdktrace.TracerProvider {
@@ -127,6 +231,246 @@ func shutdownTracer(tp *sdktrace.TracerProvider, loggers *logger.Loggers) {
 	}
 }
 
+func setupTracking(cfg *config.Config, db *sql.DB, adRepo repository.AdRepository, spend service.SpendRecorder, serviceMetrics *metrics.ServiceMetrics, repositoryMetrics *metrics.RepositoryMetrics, loggers *logger.Loggers) service.TrackingService {
+	trackingService := service.NewTrackingService(
+		repository.NewMysqlTrackingRepository(db, repositoryMetrics),
+		adRepo,
+		spend,
+		serviceMetrics,
+		loggers,
+		service.TrackingOptions{
+			FlushInterval:    cfg.Tracking.FlushInterval,
+			BatchSize:        cfg.Tracking.BatchSize,
+			BufferSize:       cfg.Tracking.BufferSize,
+			DedupWindow:      cfg.Tracking.DedupWindow,
+			MaxFlushAttempts: cfg.Tracking.MaxFlushAttempts,
+		},
+	)
+	trackingService.Start()
+	loggers.InfoLogger.Info("Tracking worker started")
+	return trackingService
+}
+
+func stopTracking(trackingService service.TrackingService, loggers *logger.Loggers) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := trackingService.Stop(ctx); err != nil {
+		loggers.ErrorLogger.Error("Failed to flush tracking events on shutdown", utils.Err(err))
+	}
+}
+
+func setupSavedSearches(cfg *config.Config, db *sql.DB, serviceMetrics *metrics.ServiceMetrics, repositoryMetrics *metrics.RepositoryMetrics, loggers *logger.Loggers) service.SavedSearchService {
+	var searchNotifier notifier.Notifier
+	switch cfg.SavedSearches.Notifier {
+	case "webhook":
+		if cfg.SavedSearches.WebhookURL == "" {
+			loggers.ErrorLogger.Error("Saved search webhook notifier requires saved_searches.webhook_url")
+			os.Exit(1)
+		}
+		searchNotifier = notifier.NewWebhookNotifier(cfg.SavedSearches.WebhookURL, cfg.SavedSearches.WebhookTimeout)
+	default:
+		searchNotifier = notifier.NewLogNotifier(loggers)
+	}
+
+	savedSearchService := service.NewSavedSearchService(
+		repository.NewMysqlSavedSearchRepository(db, repositoryMetrics),
+		searchNotifier,
+		serviceMetrics,
+		loggers,
+		service.SavedSearchOptions{
+			QueueSize:     cfg.SavedSearches.QueueSize,
+			RetryInterval: cfg.SavedSearches.RetryInterval,
+			MaxAttempts:   cfg.SavedSearches.MaxAttempts,
+		},
+	)
+	savedSearchService.Start()
+	loggers.InfoLogger.Info("Saved search matcher started")
+	return savedSearchService
+}
+
+func stopSavedSearches(savedSearchService service.SavedSearchService, loggers *logger.Loggers) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := savedSearchService.Stop(ctx); err != nil {
+		loggers.ErrorLogger.Error("Failed to drain saved search matcher on shutdown", utils.Err(err))
+	}
+}
+
+func setupWebhooks(cfg *config.Config, db *sql.DB, serviceMetrics *metrics.ServiceMetrics, repositoryMetrics *metrics.RepositoryMetrics, loggers *logger.Loggers) service.WebhookService {
+	timeout := cfg.Webhooks.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	webhookService := service.NewWebhookService(
+		repository.NewMysqlWebhookRepository(db, repositoryMetrics),
+		webhook.NewHTTPSender(&http.Client{Timeout: timeout}),
+		serviceMetrics,
+		loggers,
+		service.WebhookOptions{
+			PollInterval:   cfg.Webhooks.PollInterval,
+			BatchSize:      cfg.Webhooks.BatchSize,
+			MaxAttempts:    cfg.Webhooks.MaxAttempts,
+			InitialBackoff: cfg.Webhooks.InitialBackoff,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff,
+			Lease:          2 * timeout,
+		},
+	)
+	webhookService.Start()
+	loggers.InfoLogger.Info("Webhook delivery worker started")
+	return webhookService
+}
+
+func stopWebhooks(webhookService service.WebhookService, loggers *logger.Loggers) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := webhookService.Stop(ctx); err != nil {
+		loggers.ErrorLogger.Error("Failed to stop webhook worker on shutdown", utils.Err(err))
+	}
+}
+
+func setupJobs(cfg *config.Config, db *sql.DB, serviceMetrics *metrics.ServiceMetrics, repositoryMetrics *metrics.RepositoryMetrics, loggers *logger.Loggers) service.JobService {
+	return service.NewJobService(
+		repository.NewMysqlJobRepository(db, repositoryMetrics),
+		serviceMetrics,
+		loggers,
+		service.JobOptions{
+			Workers:        cfg.Jobs.Workers,
+			PollInterval:   cfg.Jobs.PollInterval,
+			Lease:          cfg.Jobs.Lease,
+			InitialBackoff: cfg.Jobs.InitialBackoff,
+			MaxBackoff:     cfg.Jobs.MaxBackoff,
+			MaxAttempts:    cfg.Jobs.MaxAttempts,
+			Retention:      cfg.Jobs.Retention,
+		},
+	)
+}
+
+func stopJobs(jobService service.JobService, loggers *logger.Loggers) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := jobService.Stop(ctx); err != nil {
+		loggers.ErrorLogger.Error("Failed to stop job workers on shutdown", utils.Err(err))
+	}
+}
+
+func setupImports(cfg *config.Config, db *sql.DB, adRepo repository.AdRepository, attributeRepo repository.AttributeRepository, campaignRepo repository.CampaignRepository, jobService service.JobService, serviceMetrics *metrics.ServiceMetrics, repositoryMetrics *metrics.RepositoryMetrics, loggers *logger.Loggers) service.ImportService {
+	return service.NewImportService(
+		adRepo,
+		attributeRepo,
+		campaignRepo,
+		repository.NewMysqlImportRepository(db, repositoryMetrics),
+		jobService,
+		handler.ImportReaders(versionOptions(cfg, loggers).Currency),
+		serviceMetrics,
+		loggers,
+		importOptions(cfg),
+	)
+}
+
+func importOptions(cfg *config.Config) service.ImportOptions {
+	return service.ImportOptions{
+		SpoolDir:          cfg.Imports.SpoolDir,
+		BatchSize:         cfg.Imports.BatchSize,
+		MaxAttempts:       cfg.Imports.MaxAttempts,
+		MaxReportedErrors: cfg.Imports.MaxReportedErrors,
+	}
+}
+
+func setupOutboxRelay(cfg *config.Config, db *sql.DB, publisher service.Publisher, serviceMetrics *metrics.ServiceMetrics, repositoryMetrics *metrics.RepositoryMetrics, loggers *logger.Loggers) service.OutboxRelay {
+	outboxRelay := service.NewOutboxRelay(
+		repository.NewMysqlOutboxRepository(db, repositoryMetrics),
+		publisher,
+		serviceMetrics,
+		loggers,
+		service.OutboxOptions{
+			PollInterval:   cfg.Outbox.PollInterval,
+			BatchSize:      cfg.Outbox.BatchSize,
+			RetryBackoff:   cfg.Outbox.RetryBackoff,
+			PublishTimeout: cfg.Outbox.PublishTimeout,
+			Retention:      cfg.Outbox.Retention,
+		},
+	)
+	outboxRelay.Start()
+	loggers.InfoLogger.Info("Outbox relay started")
+	return outboxRelay
+}
+
+func stopOutboxRelay(outboxRelay service.OutboxRelay, loggers *logger.Loggers) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := outboxRelay.Stop(ctx); err != nil {
+		loggers.ErrorLogger.Error("Failed to stop outbox relay on shutdown", utils.Err(err))
+	}
+}
+
+func setupStream(cfg *config.Config, rdb *redisClient.Client, serviceMetrics *metrics.ServiceMetrics, loggers *logger.Loggers) service.StreamService {
+	streamService := service.NewStreamService(pubsub.NewRedisPubSub(rdb), serviceMetrics, loggers, service.StreamOptions{
+		Channel:      cfg.Stream.Channel,
+		BufferSize:   cfg.Stream.BufferSize,
+		ClientBuffer: cfg.Stream.ClientBuffer,
+	})
+	streamService.Start()
+	loggers.InfoLogger.Info("Event stream started")
+	return streamService
+}
+
+func stopStream(streamService service.StreamService, loggers *logger.Loggers) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := streamService.Stop(ctx); err != nil {
+		loggers.ErrorLogger.Error("Failed to stop event stream on shutdown", utils.Err(err))
+	}
+}
+
+func versionOptions(cfg *config.Config, loggers *logger.Loggers) handler.VersionOptions {
+	parseDate := func(key, value string) time.Time {
+		if value == "" {
+			return time.Time{}
+		}
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			loggers.ErrorLogger.Error("Invalid date in versioning."+key, utils.Err(err))
+			os.Exit(1)
+		}
+		return date
+	}
+
+	currency := cfg.Versioning.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	return handler.VersionOptions{
+		Currency: currency,
+		Deprecations: map[handler.APIVersion]handler.Deprecation{
+			handler.APIv1: {
+				Since:     parseDate("v1_deprecation", cfg.Versioning.V1Deprecation),
+				Sunset:    parseDate("v1_sunset", cfg.Versioning.V1Sunset),
+				Successor: handler.APIv2,
+			},
+		},
+	}
+}
+
+func cachePolicies(cfg *config.Config, loggers *logger.Loggers) map[string]string {
+	policies := make(map[string]string, len(cfg.Caching.Routes))
+	for i, route := range cfg.Caching.Routes {
+		if route.Route == "" || route.CacheControl == "" {
+			loggers.ErrorLogger.Error("Invalid caching route: route and cache_control are required", "index", i)
+			os.Exit(1)
+		}
+		policies[route.Route] = route.CacheControl
+	}
+	return policies
+}
+
 func startServer(cfg *config.Config, handler http.Handler, loggers *logger.Loggers) *http.Server {
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.HTTP.Port),
@@ -146,6 +490,48 @@ func startServer(cfg *config.Config, handler http.Handler, loggers *logger.Logge
 	return server
 }
 
+func startGRPCServer(cfg *config.Config, adServer *rpc.AdServer, loggers *logger.Loggers) *grpc.Server {
+	grpcMetrics := metrics.NewGRPCMetrics()
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(rpc.UnaryInterceptor(grpcMetrics)),
+		grpc.ChainStreamInterceptor(rpc.StreamInterceptor(grpcMetrics)),
+	)
+	adServer.Register(server)
+	reflection.Register(server)
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+	if err != nil {
+		loggers.ErrorLogger.Error("Failed to listen for gRPC", utils.Err(err))
+		os.Exit(1)
+	}
+
+	go func() {
+		loggers.InfoLogger.Info("Starting gRPC server", "port", cfg.GRPC.Port)
+		if err := server.Serve(listener); err != nil {
+			loggers.ErrorLogger.Error("Failed to start gRPC server", utils.Err(err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
+
+func stopGRPCServer(server *grpc.Server, loggers *logger.Loggers) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		loggers.InfoLogger.Info("gRPC server shutdown gracefully")
+	case <-time.After(10 * time.Second):
+		server.Stop()
+		loggers.ErrorLogger.Error("gRPC server forced to shutdown")
+	}
+}
+
 func waitForShutdown(server *http.Server, loggers *logger.Loggers) {
 	shutdownCh := make(chan os.Signal, 1)
 	signal.Notify(shutdownCh, os.Interrupt, syscall.SIGTERM)
@@ -8,11 +8,26 @@ import (
 )
 
 type Config struct {
-	HTTP     HTTPConfig     `yaml:"http"`
-	Database DatabaseConfig `yaml:"database"`
-	Redis    RedisConfig    `yaml:"redis"`
-	Tracing  TracingConfig  `yaml:"tracing"`
-	Logger   LoggerConfig   `yaml:"logger"`
+	HTTP          HTTPConfig        `yaml:"http"`
+	GRPC          GRPCConfig        `yaml:"grpc"`
+	Database      DatabaseConfig    `yaml:"database"`
+	Redis         RedisConfig       `yaml:"redis"`
+	Tracing       TracingConfig     `yaml:"tracing"`
+	Logger        LoggerConfig      `yaml:"logger"`
+	Tracking      TrackingConfig    `yaml:"tracking"`
+	Billing       BillingConfig     `yaml:"billing"`
+	Serving       ServingConfig     `yaml:"serving"`
+	SavedSearches SavedSearchConfig `yaml:"saved_searches" mapstructure:"saved_searches"`
+	Webhooks      WebhookConfig     `yaml:"webhooks"`
+	Outbox        OutboxConfig      `yaml:"outbox"`
+	Stream        StreamConfig      `yaml:"stream"`
+	GraphQL       GraphQLConfig     `yaml:"graphql"`
+	OpenAPI       OpenAPIConfig     `yaml:"openapi"`
+	Versioning    VersioningConfig  `yaml:"versioning"`
+	Imports       ImportConfig      `yaml:"imports"`
+	Jobs          JobConfig         `yaml:"jobs"`
+	Compression   CompressionConfig `yaml:"compression"`
+	Caching       CachingConfig     `yaml:"caching"`
 }
 
 type HTTPConfig struct {
@@ -20,6 +35,25 @@ type HTTPConfig struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+type GRPCConfig struct {
+	Port int `yaml:"port"`
+}
+
+type GraphQLConfig struct {
+	MaxDepth      int `yaml:"max_depth" mapstructure:"max_depth"`
+	MaxComplexity int `yaml:"max_complexity" mapstructure:"max_complexity"`
+}
+
+type OpenAPIConfig struct {
+	ValidateResponses bool `yaml:"validate_responses" mapstructure:"validate_responses"` // development only
+}
+
+type VersioningConfig struct {
+	Currency      string `yaml:"currency"`
+	V1Deprecation string `yaml:"v1_deprecation" mapstructure:"v1_deprecation"` // YYYY-MM-DD
+	V1Sunset      string `yaml:"v1_sunset" mapstructure:"v1_sunset"`           // YYYY-MM-DD
+}
+
 type DatabaseConfig struct {
 	Host     string `yaml:"host"`
 	Port     string `yaml:"port"`
@@ -45,6 +79,93 @@ type LoggerConfig struct {
 	Level string `yaml:"level"`
 }
 
+type TrackingConfig struct {
+	FlushInterval    time.Duration `yaml:"flush_interval" mapstructure:"flush_interval"`
+	BatchSize        int           `yaml:"batch_size" mapstructure:"batch_size"`
+	BufferSize       int           `yaml:"buffer_size" mapstructure:"buffer_size"`
+	DedupWindow      time.Duration `yaml:"dedup_window" mapstructure:"dedup_window"`
+	MaxFlushAttempts int           `yaml:"max_flush_attempts" mapstructure:"max_flush_attempts"`
+}
+
+type ServingConfig struct {
+	FrequencyCap    int           `yaml:"frequency_cap" mapstructure:"frequency_cap"`
+	FrequencyWindow time.Duration `yaml:"frequency_window" mapstructure:"frequency_window"`
+	CandidateTTL    time.Duration `yaml:"candidate_ttl" mapstructure:"candidate_ttl"`
+	MaxCandidates   int           `yaml:"max_candidates" mapstructure:"max_candidates"`
+}
+
+type SavedSearchConfig struct {
+	Notifier       string        `yaml:"notifier"` // log or webhook
+	WebhookURL     string        `yaml:"webhook_url" mapstructure:"webhook_url"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" mapstructure:"webhook_timeout"`
+	QueueSize      int           `yaml:"queue_size" mapstructure:"queue_size"`
+	RetryInterval  time.Duration `yaml:"retry_interval" mapstructure:"retry_interval"`
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts"`
+}
+
+type WebhookConfig struct {
+	Timeout        time.Duration `yaml:"timeout"`
+	PollInterval   time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+	BatchSize      int           `yaml:"batch_size" mapstructure:"batch_size"`
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+}
+
+type OutboxConfig struct {
+	PollInterval   time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+	BatchSize      int           `yaml:"batch_size" mapstructure:"batch_size"`
+	RetryBackoff   time.Duration `yaml:"retry_backoff" mapstructure:"retry_backoff"`
+	PublishTimeout time.Duration `yaml:"publish_timeout" mapstructure:"publish_timeout"`
+	Retention      time.Duration `yaml:"retention"`
+}
+
+type StreamConfig struct {
+	Channel      string `yaml:"channel"`
+	BufferSize   int    `yaml:"buffer_size" mapstructure:"buffer_size"`
+	ClientBuffer int    `yaml:"client_buffer" mapstructure:"client_buffer"`
+}
+
+type ImportConfig struct {
+	SpoolDir          string `yaml:"spool_dir" mapstructure:"spool_dir"`
+	BatchSize         int    `yaml:"batch_size" mapstructure:"batch_size"`
+	MaxAttempts       int    `yaml:"max_attempts" mapstructure:"max_attempts"`
+	MaxReportedErrors int    `yaml:"max_reported_errors" mapstructure:"max_reported_errors"`
+}
+
+type CompressionConfig struct {
+	MinSize      int      `yaml:"min_size" mapstructure:"min_size"`
+	ContentTypes []string `yaml:"content_types" mapstructure:"content_types"`
+	GzipLevel    int      `yaml:"gzip_level" mapstructure:"gzip_level"`
+	BrotliLevel  int      `yaml:"brotli_level" mapstructure:"brotli_level"`
+}
+
+type CachingConfig struct {
+	Routes []CacheRouteConfig `yaml:"routes"`
+}
+
+// CacheRouteConfig sets the Cache-Control of a route pattern, e.g.
+// /v2/ads/{id}.
+type CacheRouteConfig struct {
+	Route        string `yaml:"route"`
+	CacheControl string `yaml:"cache_control" mapstructure:"cache_control"`
+}
+
+type JobConfig struct {
+	Workers        int           `yaml:"workers"`
+	PollInterval   time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+	Lease          time.Duration `yaml:"lease"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts"`
+	Retention      time.Duration `yaml:"retention"`
+}
+
+type BillingConfig struct {
+	CPC float64 `yaml:"cpc"`
+	CPM float64 `yaml:"cpm"`
+}
+
 func LoadConfig() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
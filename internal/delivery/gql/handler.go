@@ -0,0 +1,190 @@
+package gql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxRequestSize = 1 << 20
+
+type Options struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Handler struct {
+	schema  graphql.Schema
+	service service.AdService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+	options Options
+}
+
+func NewHandler(adService service.AdService, logger *logger.Loggers, metrics *metrics.HandlerMetrics, options Options) (*Handler, error) {
+	schema, err := NewSchema(adService, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := otel.Tracer("ad-service/handler")
+	return &Handler{
+		schema:  schema,
+		service: adService,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+		options: options,
+	}, nil
+}
+
+// ServeHTTP executes GraphQL requests sent as a JSON body with POST or as
+// query parameters with GET. Mutations are only accepted over POST.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GraphQL")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues(r.Method, "/graphql", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues(r.Method, "/graphql", status).Observe(duration)
+	}()
+
+	req, err := parseRequest(w, r)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, http.StatusBadRequest, "invalid_payload", err.Error())
+		return
+	}
+
+	span.SetAttributes(attribute.String("graphql.operation.name", req.OperationName))
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		status = "error"
+		utils.RespondWithJSON(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		status = "error"
+		utils.RespondWithJSON(w, http.StatusBadRequest, &graphql.Result{Errors: validation.Errors})
+		return
+	}
+
+	if err := checkLimits(doc, req.OperationName, req.Variables, h.options.MaxDepth, h.options.MaxComplexity); err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		utils.RespondWithJSON(w, http.StatusBadRequest, &graphql.Result{Errors: []gqlerrors.FormattedError{{
+			Message:    err.Error(),
+			Extensions: map[string]interface{}{"code": "QUERY_TOO_COMPLEX"},
+		}}})
+		return
+	}
+
+	if r.Method == http.MethodGet && hasMutation(doc, req.OperationName) {
+		status = "error"
+		w.Header().Set("Allow", http.MethodPost)
+		respondProblem(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "mutations require POST")
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withAdLoader(ctx, newAdLoader(h.service)),
+	})
+	if result.HasErrors() {
+		status = "error"
+		span.SetAttributes(attribute.Int("graphql.errors", len(result.Errors)))
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// respondProblem answers requests rejected before execution with a problem
+// carrying the same code the REST handlers use for the failure.
+func respondProblem(w http.ResponseWriter, r *http.Request, status int, code string, detail string) {
+	utils.RespondWithProblem(w, &utils.Problem{
+		Type:      utils.ProblemType(code),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: r.Header.Get(utils.RequestIDHeader),
+	})
+}
+
+func parseRequest(w http.ResponseWriter, r *http.Request) (*request, error) {
+	var req request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, errInvalidVariables
+			}
+		}
+	} else {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			return nil, errInvalidPayload
+		}
+	}
+
+	if req.Query == "" {
+		return nil, errMissingQuery
+	}
+	return &req, nil
+}
+
+var (
+	errInvalidPayload   = errors.New("Invalid request payload")
+	errInvalidVariables = errors.New("variables must be a JSON object")
+	errMissingQuery     = errors.New("query is required")
+)
+
+func hasMutation(doc *ast.Document, operationName string) bool {
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || op.Operation != ast.OperationTypeMutation {
+			continue
+		}
+		if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxIntrospectionDepth bounds the nesting of introspection queries. It
+// leaves room for the type references of the usual introspection query,
+// which are nested deeper than the data queries are allowed to be.
+const maxIntrospectionDepth = 15
+
+// queryCost measures an operation before it is executed. Every field costs
+// one and paginated fields multiply the cost of their selection by the
+// requested page size. Introspection fields cost nothing so that tools can
+// always load the schema; their depth is measured on its own.
+type queryCost struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits rejects operations nested deeper than maxDepth or more complex
+// than maxComplexity. Zero disables a limit. While the depth is limited,
+// introspection is held to maxIntrospectionDepth instead.
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}, maxDepth, maxComplexity int) error {
+	qc := &queryCost{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			qc.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		// Execution reports the missing operation.
+		return nil
+	}
+
+	m := qc.selectionSet(operation.SelectionSet, map[string]bool{}, false)
+	if maxDepth > 0 && m.depth > maxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", m.depth, maxDepth)
+	}
+	if maxDepth > 0 && m.introspectionDepth > maxIntrospectionDepth {
+		return fmt.Errorf("introspection depth %d exceeds the limit of %d", m.introspectionDepth, maxIntrospectionDepth)
+	}
+	if maxComplexity > 0 && m.complexity > maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", m.complexity, maxComplexity)
+	}
+	return nil
+}
+
+// measure is the depth and complexity of a selection. Paths through
+// introspection fields count towards introspectionDepth only.
+type measure struct {
+	depth              int
+	introspectionDepth int
+	complexity         int
+}
+
+func (qc *queryCost) selectionSet(set *ast.SelectionSet, visiting map[string]bool, introspection bool) measure {
+	var total measure
+	if set == nil {
+		return total
+	}
+
+	for _, selection := range set.Selections {
+		var m measure
+		switch selection := selection.(type) {
+		case *ast.Field:
+			inIntrospection := introspection || strings.HasPrefix(selection.Name.Value, "__")
+			child := qc.selectionSet(selection.SelectionSet, visiting, inIntrospection)
+			if inIntrospection {
+				m.introspectionDepth = child.introspectionDepth + 1
+				break
+			}
+			m.depth = child.depth + 1
+			if child.introspectionDepth > 0 {
+				m.introspectionDepth = child.introspectionDepth + 1
+			}
+			m.complexity = 1 + qc.multiplier(selection)*child.complexity
+		case *ast.InlineFragment:
+			m = qc.selectionSet(selection.SelectionSet, visiting, introspection)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := qc.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			m = qc.selectionSet(fragment.SelectionSet, visiting, introspection)
+			delete(visiting, name)
+		}
+		total.depth = max(total.depth, m.depth)
+		total.introspectionDepth = max(total.introspectionDepth, m.introspectionDepth)
+		total.complexity += m.complexity
+	}
+	return total
+}
+
+// multiplier is the page size requested by a field's first argument, or
+// one for fields that are not paginated. Sizes the resolver would reject
+// count as the largest page.
+func (qc *queryCost) multiplier(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		n := maxPageSize
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if v, err := strconv.Atoi(value.Value); err == nil {
+				n = v
+			}
+		case *ast.Variable:
+			switch v := qc.variables[value.Name.Value].(type) {
+			case float64:
+				if v <= maxPageSize {
+					n = int(v)
+				}
+			case int:
+				n = v
+			case nil:
+				n = defaultPageSize
+			}
+		}
+		if n <= 0 || n > maxPageSize {
+			return maxPageSize
+		}
+		return n
+	}
+
+	if field.Name.Value == "ads" {
+		return defaultPageSize
+	}
+	return 1
+}
@@ -0,0 +1,90 @@
+package gql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func TestCheckLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		operation  string
+		variables  map[string]interface{}
+		depth      int
+		complexity int
+	}{
+		{name: "single ad", query: `{ ad(id: 1) { id title } }`, depth: 2, complexity: 3},
+		{name: "page size", query: `{ ads(first: 5) { id title } }`, depth: 2, complexity: 11},
+		{name: "default page", query: `{ ads { id title } }`, depth: 2, complexity: 21},
+		{name: "oversized page", query: `{ ads(first: 500) { id } }`, depth: 2, complexity: 101},
+		{name: "page from variable", query: `query($n: Int) { ads(first: $n) { id title } }`, variables: map[string]interface{}{"n": float64(3)}, depth: 2, complexity: 7},
+		{name: "unset variable", query: `query($n: Int) { ads(first: $n) { id } }`, depth: 2, complexity: 11},
+		{name: "nested pages", query: `{ ads(first: 10) { campaign { ads(first: 10) { id } } } }`, depth: 4, complexity: 1 + 10*(1+1*(1+10))},
+		{name: "fragments", query: `{ ...Top } fragment Top on Query { ad(id: 1) { ...Fields } } fragment Fields on Ad { id title }`, depth: 2, complexity: 3},
+		{name: "fragment cycle", query: `{ ad(id: 1) { ...A } } fragment A on Ad { id ...A }`, depth: 2, complexity: 2},
+		{name: "introspection", query: `{ __schema { types { name } } }`, depth: 0, complexity: 0},
+		{name: "named operation", query: `query Small { ad(id: 1) { id } } query Big { ads(first: 100) { id } }`, operation: "Small", depth: 2, complexity: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			operation := tt.operation
+
+			if err := checkLimits(doc, operation, tt.variables, tt.depth, tt.complexity); err != nil {
+				t.Errorf("checkLimits() at the limits: %v", err)
+			}
+			if err := checkLimits(doc, operation, tt.variables, 0, 0); err != nil {
+				t.Errorf("checkLimits() without limits: %v", err)
+			}
+			if tt.depth > 0 {
+				want := fmt.Sprintf("query depth %d exceeds the limit of %d", tt.depth, tt.depth-1)
+				if err := checkLimits(doc, operation, tt.variables, tt.depth-1, 0); err == nil || err.Error() != want {
+					t.Errorf("checkLimits() below the depth = %v, want %q", err, want)
+				}
+			}
+			if tt.complexity > 1 {
+				want := fmt.Sprintf("query complexity %d exceeds the limit of %d", tt.complexity, tt.complexity-1)
+				if err := checkLimits(doc, operation, tt.variables, 0, tt.complexity-1); err == nil || err.Error() != want {
+					t.Errorf("checkLimits() below the complexity = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckLimitsIntrospection(t *testing.T) {
+	deep := "{ __schema { types { fields { type { " + strings.Repeat("ofType { ", 12) + "name" + strings.Repeat(" }", 16) + " }"
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "full introspection query", query: testutil.IntrospectionQuery},
+		{name: "typename", query: `{ ad(id: 1) { id __typename } }`},
+		{name: "deep introspection", query: deep, want: "introspection depth 17 exceeds the limit of 15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			err = checkLimits(doc, "", nil, 2, 3)
+			if tt.want == "" && err != nil {
+				t.Errorf("checkLimits() error = %v", err)
+			}
+			if tt.want != "" && (err == nil || err.Error() != tt.want) {
+				t.Errorf("checkLimits() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package gql
+
+import (
+	"context"
+	"strconv"
+
+	"ad-service/internal/service"
+
+	"github.com/graph-gophers/dataloader"
+)
+
+const maxAdBatch = 100
+
+type adLoaderKey struct{}
+
+// newAdLoader batches the ad lookups of a single request into GetAdsByIDs
+// calls. Loaders cache what they load and must not outlive the request.
+func newAdLoader(adService service.AdService) *dataloader.Loader {
+	batch := func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+		results := make([]*dataloader.Result, len(keys))
+
+		ids := make([]int64, len(keys))
+		for i, key := range keys {
+			ids[i], _ = strconv.ParseInt(key.String(), 10, 64)
+		}
+
+		ads, err := adService.GetAdsByIDs(ctx, ids)
+		for i, id := range ids {
+			if err != nil {
+				results[i] = &dataloader.Result{Error: err}
+				continue
+			}
+			if ad, ok := ads[id]; ok {
+				results[i] = &dataloader.Result{Data: ad}
+			} else {
+				results[i] = &dataloader.Result{}
+			}
+		}
+		return results
+	}
+
+	return dataloader.NewBatchedLoader(batch, dataloader.WithBatchCapacity(maxAdBatch))
+}
+
+func adKey(id int64) dataloader.Key {
+	return dataloader.StringKey(strconv.FormatInt(id, 10))
+}
+
+func withAdLoader(ctx context.Context, loader *dataloader.Loader) context.Context {
+	return context.WithValue(ctx, adLoaderKey{}, loader)
+}
+
+func adLoaderFromContext(ctx context.Context) *dataloader.Loader {
+	return ctx.Value(adLoaderKey{}).(*dataloader.Loader)
+}
@@ -0,0 +1,573 @@
+package gql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+	cursorPrefix    = "offset:"
+)
+
+// jsonScalar carries free-form objects such as ad attributes and targeting.
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "An arbitrary JSON value.",
+	Serialize:   func(value interface{}) interface{} { return value },
+	ParseValue:  func(value interface{}) interface{} { return value },
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return literalValue(valueAST)
+	},
+})
+
+func literalValue(valueAST ast.Value) interface{} {
+	switch v := valueAST.(type) {
+	case *ast.ObjectValue:
+		obj := make(map[string]interface{}, len(v.Fields))
+		for _, field := range v.Fields {
+			obj[field.Name.Value] = literalValue(field.Value)
+		}
+		return obj
+	case *ast.ListValue:
+		list := make([]interface{}, 0, len(v.Values))
+		for _, item := range v.Values {
+			list = append(list, literalValue(item))
+		}
+		return list
+	case *ast.IntValue:
+		n, _ := strconv.ParseFloat(v.Value, 64)
+		return n
+	case *ast.FloatValue:
+		n, _ := strconv.ParseFloat(v.Value, 64)
+		return n
+	default:
+		return valueAST.GetValue()
+	}
+}
+
+// resolver holds the services used by the field resolvers.
+type resolver struct {
+	service service.AdService
+	logger  *logger.Loggers
+}
+
+// NewSchema builds the GraphQL schema of the ad API.
+func NewSchema(adService service.AdService, loggers *logger.Loggers) (graphql.Schema, error) {
+	r := &resolver{service: adService, logger: loggers}
+
+	adType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Ad",
+		Fields: graphql.Fields{
+			"id":            {Type: graphql.NewNonNull(graphql.ID), Resolve: adField(func(ad *domain.Ad) interface{} { return strconv.FormatInt(ad.ID, 10) })},
+			"title":         {Type: graphql.NewNonNull(graphql.String), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.Title })},
+			"description":   {Type: graphql.NewNonNull(graphql.String), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.Description })},
+			"price":         {Type: graphql.NewNonNull(graphql.Float), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.Price })},
+			"category":      {Type: graphql.String, Resolve: adField(func(ad *domain.Ad) interface{} { return nullableString(ad.Category) })},
+			"attributes":    {Type: jsonScalar, Resolve: adField(func(ad *domain.Ad) interface{} { return ad.Attributes })},
+			"tags":          {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), Resolve: adField(func(ad *domain.Ad) interface{} { return nonNilTags(ad.Tags) })},
+			"targetUrl":     {Type: graphql.String, Resolve: adField(func(ad *domain.Ad) interface{} { return nullableString(ad.TargetURL) })},
+			"campaignId":    {Type: graphql.ID, Resolve: adField(func(ad *domain.Ad) interface{} { return nullableID(ad.CampaignID) })},
+			"weight":        {Type: graphql.NewNonNull(graphql.Int), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.Weight })},
+			"targeting":     {Type: jsonScalar, Resolve: adField(func(ad *domain.Ad) interface{} { return targetingValue(ad.Targeting) })},
+			"favoriteCount": {Type: graphql.NewNonNull(graphql.Int), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.FavoriteCount })},
+			"createdAt":     {Type: graphql.NewNonNull(graphql.DateTime), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.CreatedAt })},
+			"updatedAt":     {Type: graphql.NewNonNull(graphql.DateTime), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.UpdatedAt })},
+			"active":        {Type: graphql.NewNonNull(graphql.Boolean), Resolve: adField(func(ad *domain.Ad) interface{} { return ad.Active })},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     {Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": {Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     {Type: graphql.String},
+			"endCursor":       {Type: graphql.String},
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AdEdge",
+		Fields: graphql.Fields{
+			"cursor": {Type: graphql.NewNonNull(graphql.String)},
+			"node":   {Type: graphql.NewNonNull(adType)},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AdConnection",
+		Fields: graphql.Fields{
+			"edges":    {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"pageInfo": {Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+
+	tagModeEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "TagMode",
+		Values: graphql.EnumValueConfigMap{
+			"ANY": {Value: string(domain.TagMatchAny)},
+			"ALL": {Value: string(domain.TagMatchAll)},
+		},
+	})
+
+	sortFieldEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "AdSortField",
+		Values: graphql.EnumValueConfigMap{
+			"ID":             {Value: "id"},
+			"TITLE":          {Value: "title"},
+			"PRICE":          {Value: "price"},
+			"WEIGHT":         {Value: "weight"},
+			"FAVORITE_COUNT": {Value: "favorite_count"},
+			"CREATED_AT":     {Value: "created_at"},
+			"UPDATED_AT":     {Value: "updated_at"},
+		},
+	})
+
+	sortOrderEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "SortOrder",
+		Values: graphql.EnumValueConfigMap{
+			"ASC":  {Value: "ASC"},
+			"DESC": {Value: "DESC"},
+		},
+	})
+
+	attributeFilterInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AttributeFilterInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":  {Type: graphql.NewNonNull(graphql.String)},
+			"value": {Type: graphql.String},
+			"min":   {Type: graphql.Float},
+			"max":   {Type: graphql.Float},
+		},
+	})
+
+	filterInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AdFilterInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"category":   {Type: graphql.String},
+			"tags":       {Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"tagMode":    {Type: tagModeEnum},
+			"campaignId": {Type: graphql.ID},
+			"attributes": {Type: graphql.NewList(graphql.NewNonNull(attributeFilterInput))},
+		},
+	})
+
+	sortInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AdSortInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"field": {Type: sortFieldEnum, DefaultValue: "created_at"},
+			"order": {Type: sortOrderEnum, DefaultValue: "ASC"},
+		},
+	})
+
+	adInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AdInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       {Type: graphql.NewNonNull(graphql.String)},
+			"description": {Type: graphql.String},
+			"price":       {Type: graphql.NewNonNull(graphql.Float)},
+			"category":    {Type: graphql.String},
+			"attributes":  {Type: jsonScalar},
+			"tags":        {Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"targetUrl":   {Type: graphql.String},
+			"campaignId":  {Type: graphql.ID},
+			"weight":      {Type: graphql.Int},
+			"targeting":   {Type: jsonScalar},
+			"active":      {Type: graphql.Boolean},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"ad": {
+				Type: adType,
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.resolveAd,
+			},
+			"ads": {
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": {Type: filterInput},
+					"sort":   {Type: sortInput},
+					"first":  {Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":  {Type: graphql.String},
+				},
+				Resolve: r.resolveAds,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createAd": {
+				Type: graphql.NewNonNull(adType),
+				Args: graphql.FieldConfigArgument{
+					"input": {Type: graphql.NewNonNull(adInput)},
+				},
+				Resolve: r.resolveCreateAd,
+			},
+			"updateAd": {
+				Type: graphql.NewNonNull(adType),
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: graphql.NewNonNull(graphql.ID)},
+					"input": {Type: graphql.NewNonNull(adInput)},
+				},
+				Resolve: r.resolveUpdateAd,
+			},
+			"deleteAd": {
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.resolveDeleteAd,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func (r *resolver) resolveAd(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := adLoaderFromContext(p.Context).Load(p.Context, adKey(id))
+	return func() (interface{}, error) {
+		value, err := thunk()
+		if err != nil {
+			return nil, r.toError(err, "failed to retrieve ad")
+		}
+		ad, _ := value.(*domain.Ad)
+		if ad == nil {
+			return nil, nil
+		}
+		return ad, nil
+	}, nil
+}
+
+type connection struct {
+	Edges    []edge                 `json:"edges"`
+	PageInfo map[string]interface{} `json:"pageInfo"`
+}
+
+type edge struct {
+	Cursor string     `json:"cursor"`
+	Node   *domain.Ad `json:"node"`
+}
+
+// resolveAds pages through the ad listing with opaque cursors over the
+// listing offset. One extra ad is fetched to tell whether a next page exists.
+func (r *resolver) resolveAds(p graphql.ResolveParams) (interface{}, error) {
+	first, _ := p.Args["first"].(int)
+	if first <= 0 || first > maxPageSize {
+		return nil, inputError(fmt.Sprintf("first must be between 1 and %d", maxPageSize))
+	}
+
+	offset := 0
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		position, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		offset = position + 1
+	}
+
+	sortBy, order := "created_at", "ASC"
+	if sort, ok := p.Args["sort"].(map[string]interface{}); ok {
+		if field, ok := sort["field"].(string); ok {
+			sortBy = field
+		}
+		if o, ok := sort["order"].(string); ok {
+			order = o
+		}
+	}
+
+	filter, err := parseFilter(p.Args["filter"])
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.service.GetAllAds(p.Context, first+1, offset, sortBy, order, filter)
+	if err != nil {
+		return nil, r.toError(err, "failed to retrieve ads")
+	}
+
+	ads := result.Ads
+	hasNext := len(ads) > first
+	if hasNext {
+		ads = ads[:first]
+	}
+
+	conn := connection{
+		Edges: make([]edge, 0, len(ads)),
+		PageInfo: map[string]interface{}{
+			"hasNextPage":     hasNext,
+			"hasPreviousPage": offset > 0,
+		},
+	}
+	loader := adLoaderFromContext(p.Context)
+	for i, ad := range ads {
+		cursor := encodeCursor(offset + i)
+		conn.Edges = append(conn.Edges, edge{Cursor: cursor, Node: ad})
+		loader.Prime(p.Context, adKey(ad.ID), ad)
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo["startCursor"] = conn.Edges[0].Cursor
+		conn.PageInfo["endCursor"] = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+func (r *resolver) resolveCreateAd(p graphql.ResolveParams) (interface{}, error) {
+	ad, err := parseAdInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := r.service.CreateAd(p.Context, ad)
+	if err != nil {
+		return nil, r.toError(err, "failed to create ad")
+	}
+	return created, nil
+}
+
+func (r *resolver) resolveUpdateAd(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	ad, err := parseAdInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	ad.ID = id
+
+	updated, err := r.service.UpdateAd(p.Context, ad)
+	if err != nil {
+		return nil, r.toError(err, "failed to update ad")
+	}
+	adLoaderFromContext(p.Context).Clear(p.Context, adKey(id))
+	return updated, nil
+}
+
+func (r *resolver) resolveDeleteAd(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.service.DeleteAd(p.Context, id); err != nil {
+		return nil, r.toError(err, "failed to delete ad")
+	}
+	adLoaderFromContext(p.Context).Clear(p.Context, adKey(id))
+	return true, nil
+}
+
+// Error is a GraphQL error with a machine readable code in its extensions.
+type Error struct {
+	Message string
+	Code    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+func inputError(message string) error {
+	return &Error{Message: message, Code: "BAD_USER_INPUT"}
+}
+
+func (r *resolver) toError(err error, logMessage string) error {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.Is(err, service.ErrAdNotFound):
+		return &Error{Message: err.Error(), Code: "NOT_FOUND"}
+	case errors.Is(err, service.ErrInvalidID):
+		return inputError(err.Error())
+	case errors.As(err, &validationErr):
+		return inputError(validationErr.Error())
+	default:
+		r.logger.ErrorLogger.Error(logMessage, utils.Err(err))
+		return &Error{Message: "internal server error", Code: "INTERNAL_SERVER_ERROR"}
+	}
+}
+
+func adField(get func(ad *domain.Ad) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		ad, ok := p.Source.(*domain.Ad)
+		if !ok || ad == nil {
+			return nil, nil
+		}
+		return get(ad), nil
+	}
+}
+
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullableID(id *int64) interface{} {
+	if id == nil {
+		return nil
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func targetingValue(targeting *domain.Targeting) interface{} {
+	if targeting.IsEmpty() {
+		return nil
+	}
+	return targeting
+}
+
+func parseID(value interface{}) (int64, error) {
+	raw, _ := value.(string)
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, inputError("id must be a positive integer")
+	}
+	return id, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, inputError("invalid cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, inputError("invalid cursor")
+	}
+	return offset, nil
+}
+
+func parseFilter(value interface{}) (domain.AdFilter, error) {
+	var filter domain.AdFilter
+	args, ok := value.(map[string]interface{})
+	if !ok {
+		return filter, nil
+	}
+
+	filter.Category, _ = args["category"].(string)
+	if mode, ok := args["tagMode"].(string); ok {
+		filter.TagMode = domain.TagMatchMode(mode)
+	}
+	if tags, ok := args["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				filter.Tags = append(filter.Tags, s)
+			}
+		}
+	}
+	if campaignID, ok := args["campaignId"]; ok && campaignID != nil {
+		id, err := parseID(campaignID)
+		if err != nil {
+			return filter, inputError("campaignId must be a positive integer")
+		}
+		filter.CampaignID = id
+	}
+	if attributes, ok := args["attributes"].([]interface{}); ok {
+		for _, raw := range attributes {
+			attr, _ := raw.(map[string]interface{})
+			f := domain.AttributeFilter{}
+			f.Name, _ = attr["name"].(string)
+			f.Value, _ = attr["value"].(string)
+			if min, ok := attr["min"].(float64); ok {
+				f.Min = &min
+			}
+			if max, ok := attr["max"].(float64); ok {
+				f.Max = &max
+			}
+			filter.Attributes = append(filter.Attributes, f)
+		}
+	}
+	return filter, nil
+}
+
+func parseAdInput(value interface{}) (*domain.Ad, error) {
+	args, _ := value.(map[string]interface{})
+	ad := &domain.Ad{}
+
+	ad.Title, _ = args["title"].(string)
+	ad.Description, _ = args["description"].(string)
+	ad.Price, _ = args["price"].(float64)
+	ad.Category, _ = args["category"].(string)
+	ad.TargetURL, _ = args["targetUrl"].(string)
+	ad.Weight, _ = args["weight"].(int)
+	ad.Active, _ = args["active"].(bool)
+
+	if tags, ok := args["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				ad.Tags = append(ad.Tags, s)
+			}
+		}
+	}
+	if campaignID, ok := args["campaignId"]; ok && campaignID != nil {
+		id, err := parseID(campaignID)
+		if err != nil {
+			return nil, inputError("campaignId must be a positive integer")
+		}
+		ad.CampaignID = &id
+	}
+	if attributes, ok := args["attributes"]; ok && attributes != nil {
+		m, ok := attributes.(map[string]interface{})
+		if !ok {
+			return nil, inputError("attributes must be an object")
+		}
+		ad.Attributes = m
+	}
+	if targeting, ok := args["targeting"]; ok && targeting != nil {
+		// Targeting arrives as a generic JSON value; decode it the same way
+		// as a REST request body.
+		data, err := json.Marshal(targeting)
+		if err != nil {
+			return nil, inputError("targeting is invalid")
+		}
+		if err := json.Unmarshal(data, &ad.Targeting); err != nil {
+			return nil, inputError("targeting is invalid")
+		}
+	}
+	return ad, nil
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+)
+
+var errInvalidPayload = errors.New("invalid request payload")
+
+// adCodec maps ads between the domain and the payloads of one API version.
+type adCodec interface {
+	decodeAd(body io.Reader) (*domain.Ad, error)
+	encodeAd(ad *domain.Ad) interface{}
+	csvHeader() []string
+	csvRecord(ad *domain.Ad) []string
+	decodeCSV(row csvRow) (*domain.Ad, error)
+}
+
+func newAdCodec(version APIVersion, currency string) adCodec {
+	if version == APIv2 {
+		return adCodecV2{currency: strings.ToUpper(currency)}
+	}
+	return adCodecV1{}
+}
+
+type AdV1 struct {
+	ID            int64                  `json:"id"`
+	ExternalRef   string                 `json:"external_ref,omitempty"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	Price         float64                `json:"price"`
+	Category      string                 `json:"category,omitempty"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	TargetURL     string                 `json:"target_url,omitempty"`
+	CampaignID    *int64                 `json:"campaign_id,omitempty"`
+	Weight        int                    `json:"weight,omitempty"`
+	Targeting     *domain.Targeting      `json:"targeting,omitempty"`
+	FavoriteCount int64                  `json:"favorite_count"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	Active        bool                   `json:"active"`
+}
+
+type adCodecV1 struct{}
+
+func (adCodecV1) decodeAd(body io.Reader) (*domain.Ad, error) {
+	var dto AdV1
+	if err := json.NewDecoder(body).Decode(&dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+	return &domain.Ad{
+		ExternalRef: dto.ExternalRef,
+		Title:       dto.Title,
+		Description: dto.Description,
+		Price:       dto.Price,
+		Category:    dto.Category,
+		Attributes:  dto.Attributes,
+		Tags:        dto.Tags,
+		TargetURL:   dto.TargetURL,
+		CampaignID:  dto.CampaignID,
+		Weight:      dto.Weight,
+		Targeting:   dto.Targeting,
+		Active:      dto.Active,
+	}, nil
+}
+
+func (adCodecV1) encodeAd(ad *domain.Ad) interface{} {
+	return &AdV1{
+		ID:            ad.ID,
+		ExternalRef:   ad.ExternalRef,
+		Title:         ad.Title,
+		Description:   ad.Description,
+		Price:         ad.Price,
+		Category:      ad.Category,
+		Attributes:    ad.Attributes,
+		Tags:          ad.Tags,
+		TargetURL:     ad.TargetURL,
+		CampaignID:    ad.CampaignID,
+		Weight:        ad.Weight,
+		Targeting:     ad.Targeting,
+		FavoriteCount: ad.FavoriteCount,
+		CreatedAt:     ad.CreatedAt,
+		UpdatedAt:     ad.UpdatedAt,
+		Active:        ad.Active,
+	}
+}
+
+func (adCodecV1) csvHeader() []string {
+	return adCSVHeader("price")
+}
+
+func (adCodecV1) csvRecord(ad *domain.Ad) []string {
+	return adCSVRecord(ad, strconv.FormatFloat(ad.Price, 'f', -1, 64))
+}
+
+func (adCodecV1) decodeCSV(row csvRow) (*domain.Ad, error) {
+	ad, err := adFromCSV(row)
+	if err != nil {
+		return nil, err
+	}
+	if price := row.get("price"); price != "" {
+		if ad.Price, err = strconv.ParseFloat(price, 64); err != nil {
+			return nil, &service.ValidationError{Field: "price", Message: "must be a number"}
+		}
+	}
+	return ad, nil
+}
+
+// Money is a decimal amount in a currency. The amount is a string so that
+// clients do not lose precision to floating point.
+type Money struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// AdV2 replaces the bare price of v1 with Money.
+type AdV2 struct {
+	ID            int64                  `json:"id"`
+	ExternalRef   string                 `json:"external_ref,omitempty"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	Price         Money                  `json:"price"`
+	Category      string                 `json:"category,omitempty"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	TargetURL     string                 `json:"target_url,omitempty"`
+	CampaignID    *int64                 `json:"campaign_id,omitempty"`
+	Weight        int                    `json:"weight,omitempty"`
+	Targeting     *domain.Targeting      `json:"targeting,omitempty"`
+	FavoriteCount int64                  `json:"favorite_count"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	Active        bool                   `json:"active"`
+}
+
+// adCodecV2 prices every ad in the currency of the service; ads do not
+// carry a currency of their own.
+type adCodecV2 struct {
+	currency string
+}
+
+func (c adCodecV2) decodeAd(body io.Reader) (*domain.Ad, error) {
+	var dto AdV2
+	if err := json.NewDecoder(body).Decode(&dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+
+	var price float64
+	if dto.Price.Amount != "" {
+		amount, err := strconv.ParseFloat(dto.Price.Amount, 64)
+		if err != nil {
+			return nil, &service.ValidationError{Field: "price.amount", Message: "must be a decimal number"}
+		}
+		price = amount
+	}
+	if dto.Price.Currency != "" && !strings.EqualFold(dto.Price.Currency, c.currency) {
+		return nil, &service.ValidationError{Field: "price.currency", Message: "must be " + c.currency}
+	}
+
+	return &domain.Ad{
+		ExternalRef: dto.ExternalRef,
+		Title:       dto.Title,
+		Description: dto.Description,
+		Price:       price,
+		Category:    dto.Category,
+		Attributes:  dto.Attributes,
+		Tags:        dto.Tags,
+		TargetURL:   dto.TargetURL,
+		CampaignID:  dto.CampaignID,
+		Weight:      dto.Weight,
+		Targeting:   dto.Targeting,
+		Active:      dto.Active,
+	}, nil
+}
+
+func (c adCodecV2) encodeAd(ad *domain.Ad) interface{} {
+	return &AdV2{
+		ID:          ad.ID,
+		ExternalRef: ad.ExternalRef,
+		Title:       ad.Title,
+		Description: ad.Description,
+		Price: Money{
+			Amount:   strconv.FormatFloat(ad.Price, 'f', 2, 64),
+			Currency: c.currency,
+		},
+		Category:      ad.Category,
+		Attributes:    ad.Attributes,
+		Tags:          ad.Tags,
+		TargetURL:     ad.TargetURL,
+		CampaignID:    ad.CampaignID,
+		Weight:        ad.Weight,
+		Targeting:     ad.Targeting,
+		FavoriteCount: ad.FavoriteCount,
+		CreatedAt:     ad.CreatedAt,
+		UpdatedAt:     ad.UpdatedAt,
+		Active:        ad.Active,
+	}
+}
+
+func (adCodecV2) csvHeader() []string {
+	return adCSVHeader("price_amount", "price_currency")
+}
+
+func (c adCodecV2) csvRecord(ad *domain.Ad) []string {
+	return adCSVRecord(ad, strconv.FormatFloat(ad.Price, 'f', 2, 64), c.currency)
+}
+
+func (c adCodecV2) decodeCSV(row csvRow) (*domain.Ad, error) {
+	ad, err := adFromCSV(row)
+	if err != nil {
+		return nil, err
+	}
+	if amount := row.get("price_amount"); amount != "" {
+		if ad.Price, err = strconv.ParseFloat(amount, 64); err != nil {
+			return nil, &service.ValidationError{Field: "price_amount", Message: "must be a decimal number"}
+		}
+	}
+	if currency := row.get("price_currency"); currency != "" && !strings.EqualFold(currency, c.currency) {
+		return nil, &service.ValidationError{Field: "price_currency", Message: "must be " + c.currency}
+	}
+	return ad, nil
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AttributeHandler struct {
+	service service.AttributeService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewAttributeHandler(service service.AttributeService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *AttributeHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &AttributeHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *AttributeHandler) ListDefinitions(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListDefinitions")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/categories/{category}/attributes", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/categories/{category}/attributes", status).Observe(duration)
+	}()
+
+	category := chi.URLParam(r, "category")
+	span.SetAttributes(attribute.String("category", category))
+
+	defs, err := h.service.ListDefinitions(ctx, category)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to list attribute definitions")
+		return
+	}
+
+	if defs == nil {
+		defs = []*domain.AttributeDefinition{}
+	}
+	utils.RespondWithJSON(w, http.StatusOK, defs)
+}
+
+func (h *AttributeHandler) CreateDefinition(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler CreateDefinition")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/categories/{category}/attributes", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/categories/{category}/attributes", status).Observe(duration)
+	}()
+
+	var defReq domain.AttributeDefinition
+	if err := json.NewDecoder(r.Body).Decode(&defReq); err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid request payload"))
+		span.RecordError(err)
+		respondProblem(w, r, CodeInvalidPayload, "invalid request payload")
+		return
+	}
+	defReq.Category = chi.URLParam(r, "category")
+
+	span.SetAttributes(
+		attribute.String("category", defReq.Category),
+		attribute.String("attribute.name", defReq.Name),
+	)
+
+	created, err := h.service.CreateDefinition(ctx, &defReq)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to create attribute definition")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *AttributeHandler) DeleteDefinition(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler DeleteDefinition")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("DELETE", "/categories/{category}/attributes/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("DELETE", "/categories/{category}/attributes/{id}", status).Observe(duration)
+	}()
+
+	category := chi.URLParam(r, "category")
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	err = h.service.DeleteDefinition(ctx, category, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to delete attribute definition")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "attribute definition deleted successfully"})
+}
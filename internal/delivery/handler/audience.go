@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+)
+
+const segmentParamPrefix = "seg."
+
+// audienceFromRequest builds the targeting context of the viewer. Query
+// parameters (locale, country, device, seg.<key>) override what is derived
+// from the Accept-Language, X-Country/CF-IPCountry and User-Agent headers.
+func audienceFromRequest(r *http.Request) *domain.AudienceContext {
+	query := r.URL.Query()
+
+	audience := &domain.AudienceContext{
+		Locale:   strings.ToLower(query.Get("locale")),
+		Country:  strings.ToUpper(query.Get("country")),
+		Device:   domain.DeviceType(strings.ToLower(query.Get("device"))),
+		Time:     time.Now(),
+		Segments: make(map[string]string),
+	}
+
+	if audience.Locale == "" {
+		audience.Locale = primaryLanguage(r.Header.Get("Accept-Language"))
+	}
+	if audience.Country == "" {
+		audience.Country = strings.ToUpper(r.Header.Get("X-Country"))
+	}
+	if audience.Country == "" {
+		audience.Country = strings.ToUpper(r.Header.Get("CF-IPCountry"))
+	}
+	if audience.Device == "" {
+		audience.Device = deviceFromUserAgent(r.UserAgent())
+	}
+
+	for key := range query {
+		if strings.HasPrefix(key, segmentParamPrefix) {
+			audience.Segments[strings.TrimPrefix(key, segmentParamPrefix)] = query.Get(key)
+		}
+	}
+
+	return audience
+}
+
+// requestedAudience returns the targeting context of the viewer when the
+// request asks for it with a locale, country, device or seg.<key> parameter.
+// Listings are not narrowed to the viewer otherwise.
+func requestedAudience(r *http.Request) *domain.AudienceContext {
+	query := r.URL.Query()
+	for key := range query {
+		switch {
+		case key == "locale", key == "country", key == "device", strings.HasPrefix(key, segmentParamPrefix):
+			return audienceFromRequest(r)
+		}
+	}
+	return nil
+}
+
+func primaryLanguage(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return ""
+	}
+	first := strings.Split(acceptLanguage, ",")[0]
+	first = strings.TrimSpace(strings.Split(first, ";")[0])
+	if first == "*" {
+		return ""
+	}
+	return strings.ToLower(first)
+}
+
+func deviceFromUserAgent(userAgent string) domain.DeviceType {
+	switch {
+	case userAgent == "":
+		return ""
+	case strings.Contains(userAgent, "iPad") || strings.Contains(userAgent, "Tablet"):
+		return domain.DeviceTablet
+	case strings.Contains(userAgent, "Mobi") || strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "Android"):
+		return domain.DeviceMobile
+	default:
+		return domain.DeviceDesktop
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"ad-service/internal/domain"
+)
+
+func TestRequestedAudience(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		headers  map[string]string
+		audience *domain.AudienceContext
+	}{
+		{name: "not requested", target: "/ads?category=cars", headers: map[string]string{"Accept-Language": "en"}},
+		{name: "locale", target: "/ads?locale=TK", audience: &domain.AudienceContext{Locale: "tk"}},
+		{name: "segment", target: "/ads?seg.plan=pro", audience: &domain.AudienceContext{Segments: map[string]string{"plan": "pro"}}},
+		{
+			name:     "headers fill in",
+			target:   "/ads?device=tablet",
+			headers:  map[string]string{"Accept-Language": "ru-RU,ru;q=0.9", "X-Country": "tm"},
+			audience: &domain.AudienceContext{Locale: "ru-ru", Country: "TM", Device: domain.DeviceTablet},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.target, nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			got := requestedAudience(r)
+			if tt.audience == nil {
+				if got != nil {
+					t.Fatalf("requestedAudience() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("requestedAudience() = nil")
+			}
+			if got.Locale != tt.audience.Locale || got.Country != tt.audience.Country || got.Device != tt.audience.Device {
+				t.Errorf("requestedAudience() = %+v, want %+v", got, tt.audience)
+			}
+			for key, value := range tt.audience.Segments {
+				if got.Segments[key] != value {
+					t.Errorf("segment %s = %q, want %q", key, got.Segments[key], value)
+				}
+			}
+		})
+	}
+}
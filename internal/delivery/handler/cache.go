@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CacheControl sets the Cache-Control header of successful GET and HEAD
+// responses from the policy of their route, keyed by the route pattern such
+// as /v2/ads/{id}. Requests without a version prefix match the versioned
+// route that serves them. A Cache-Control set by the handler wins.
+func CacheControl(policies map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Upgraded connections such as /ws need the hijackable writer.
+			if len(policies) == 0 || (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, r: r, policies: policies}, r)
+		})
+	}
+}
+
+// cacheControlWriter adds the policy when the status is known, by which
+// time the router has matched the route.
+type cacheControlWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	policies    map[string]string
+	wroteHeader bool
+}
+
+func (c *cacheControlWriter) WriteHeader(status int) {
+	if !c.wroteHeader && status >= http.StatusOK {
+		c.wroteHeader = true
+		if status < http.StatusMultipleChoices || status == http.StatusNotModified {
+			c.setPolicy()
+		}
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *cacheControlWriter) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+func (c *cacheControlWriter) Flush() {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (c *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func (c *cacheControlWriter) setPolicy() {
+	header := c.Header()
+	if header.Get("Cache-Control") != "" {
+		return
+	}
+	routeContext := chi.RouteContext(c.r.Context())
+	if routeContext == nil {
+		return
+	}
+	if policy, ok := c.policies[routeContext.RoutePattern()]; ok {
+		header.Set("Cache-Control", policy)
+	}
+}
+
+// adETag is a weak validator for the representation of ad in the given
+// version with the selected fields. Favorites do not update the ad, so the
+// favorite count is part of the tag.
+func adETag(ad *domain.Ad, version APIVersion, selection adSelection) string {
+	fields := make([]string, 0, len(selection.fields))
+	for _, field := range selection.fields {
+		if field != "id" {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fmt.Sprintf(`W/"v%d-%d-%d-%d-%s"`, version, ad.ID, ad.UpdatedAt.UnixNano(), ad.FavoriteCount, strings.Join(fields, "."))
+}
+
+// checkNotModified sets ETag and, unless it is zero, Last-Modified, and
+// reports whether the client's copy is still current. If-None-Match takes
+// precedence over If-Modified-Since when present (RFC 9110, 13.1.3). HTTP
+// dates have whole seconds, so lastModified is compared at that precision.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		lastModified = lastModified.UTC().Truncate(time.Second)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		return etagMatches(match, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type fakeAdService struct {
+	service.AdService
+	ad *domain.Ad
+}
+
+func (s *fakeAdService) GetAdByID(ctx context.Context, id int64) (*domain.Ad, error) {
+	return s.ad, nil
+}
+
+func (s *fakeAdService) ExpandAds(ctx context.Context, ads []*domain.Ad, expand []string) (*domain.AdRelations, error) {
+	return &domain.AdRelations{}, nil
+}
+
+func TestGetAdByIDNotModified(t *testing.T) {
+	updated := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	ad := &domain.Ad{ID: 1, Title: "Bike", FavoriteCount: 2, UpdatedAt: updated}
+	favorited := &domain.Ad{ID: 1, Title: "Bike", FavoriteCount: 3, UpdatedAt: updated}
+	tests := []struct {
+		name         string
+		target       string
+		since        time.Time
+		match        string
+		status       int
+		lastModified bool
+	}{
+		{name: "no fields", target: "/ads/1", since: updated, status: http.StatusOK},
+		{name: "favorite count selected", target: "/ads/1?fields=title,favorite_count", since: updated, status: http.StatusOK},
+		{name: "etag matches", target: "/ads/1", match: adETag(ad, APIv2, adSelection{}), status: http.StatusNotModified},
+		{name: "etag of other favorite count", target: "/ads/1", match: adETag(favorited, APIv2, adSelection{}), status: http.StatusOK},
+		{name: "etag wins over date", target: "/ads/1?fields=title,price", since: updated, match: adETag(favorited, APIv2, adSelection{}), status: http.StatusOK, lastModified: true},
+		{name: "etag of other fields", target: "/ads/1?fields=title", match: adETag(ad, APIv2, adSelection{}), status: http.StatusOK, lastModified: true},
+		{name: "etag of same fields", target: "/ads/1?fields=price,id,title", match: adETag(ad, APIv2, adSelection{fields: []string{"title", "price"}}), status: http.StatusNotModified, lastModified: true},
+		{name: "expanded", target: "/ads/1?expand=campaign", since: updated, match: adETag(ad, APIv2, adSelection{}), status: http.StatusOK},
+		{name: "not modified", target: "/ads/1?fields=title,price", since: updated, status: http.StatusNotModified, lastModified: true},
+		{name: "modified", target: "/ads/1?fields=title,price", since: updated.Add(-time.Minute), status: http.StatusOK, lastModified: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ads := &fakeAdService{ad: ad}
+			h := NewAdHandler(ads, nil, APIv2, "USD", testLoggers(), testMetrics)
+			r := chi.NewRouter()
+			r.Get("/ads/{id}", h.GetAdByID)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if !tt.since.IsZero() {
+				req.Header.Set("If-Modified-Since", tt.since.Format(http.TimeFormat))
+			}
+			if tt.match != "" {
+				req.Header.Set("If-None-Match", tt.match)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Last-Modified") != ""; got != tt.lastModified {
+				t.Errorf("Last-Modified sent = %v, want %v", got, tt.lastModified)
+			}
+		})
+	}
+}
+
+func TestGetAdByIDETagVersion(t *testing.T) {
+	ads := &fakeAdService{ad: &domain.Ad{ID: 1, Title: "Bike", UpdatedAt: time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)}}
+	r := chi.NewRouter()
+	r.Use(NegotiateVersion(r))
+	for _, version := range []APIVersion{APIv1, APIv2} {
+		h := NewAdHandler(ads, nil, version, "USD", testLoggers(), testMetrics)
+		r.Get(version.Prefix()+"/ads/{id}", h.GetAdByID)
+	}
+
+	get := func(accept, match string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ads/1", nil)
+		req.Header.Set("Accept", accept)
+		if match != "" {
+			req.Header.Set("If-None-Match", match)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	v1 := get("application/json; version=1", "")
+	etag := v1.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("v1 response has no ETag")
+	}
+	if rec := get("application/json; version=1", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("v1 revalidation status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	rec := get("application/json; version=2", etag)
+	if rec.Code != http.StatusOK {
+		t.Errorf("v2 request with a v1 ETag status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !slices.Contains(rec.Header().Values("Vary"), "Accept") {
+		t.Errorf("Vary = %q, want Accept", rec.Header().Values("Vary"))
+	}
+}
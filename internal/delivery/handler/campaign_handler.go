@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type CampaignHandler struct {
+	service service.CampaignService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewCampaignHandler(service service.CampaignService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *CampaignHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &CampaignHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *CampaignHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListCampaigns")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/campaigns", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/campaigns", status).Observe(duration)
+	}()
+
+	query := r.URL.Query()
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10 // Default limit
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1 // Default page number
+	}
+
+	campaigns, err := h.service.ListCampaigns(ctx, limit, (page-1)*limit)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retrieve campaigns")
+		return
+	}
+
+	if campaigns == nil {
+		campaigns = []*domain.Campaign{}
+	}
+	utils.RespondWithJSON(w, http.StatusOK, campaigns)
+}
+
+func (h *CampaignHandler) GetCampaignByID(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetCampaignByID")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/campaigns/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/campaigns/{id}", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("campaign.id", id))
+
+	campaign, err := h.service.GetCampaignByID(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to get campaign")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, campaign)
+}
+
+func (h *CampaignHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler CreateCampaign")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/campaigns", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/campaigns", status).Observe(duration)
+	}()
+
+	var campaignReq domain.Campaign
+	if err := json.NewDecoder(r.Body).Decode(&campaignReq); err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid request payload"))
+		span.RecordError(err)
+		respondProblem(w, r, CodeInvalidPayload, "invalid request payload")
+		return
+	}
+
+	span.SetAttributes(attribute.String("campaign.name", campaignReq.Name))
+
+	created, err := h.service.CreateCampaign(ctx, &campaignReq)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to create campaign")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *CampaignHandler) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler UpdateCampaign")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("PUT", "/campaigns/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("PUT", "/campaigns/{id}", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	var campaignReq domain.Campaign
+	if err := json.NewDecoder(r.Body).Decode(&campaignReq); err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid request payload"))
+		span.RecordError(err)
+		respondProblem(w, r, CodeInvalidPayload, "invalid request payload")
+		return
+	}
+	campaignReq.ID = id
+
+	span.SetAttributes(attribute.Int64("campaign.id", id))
+
+	updated, err := h.service.UpdateCampaign(ctx, &campaignReq)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to update campaign")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, updated)
+}
+
+func (h *CampaignHandler) DeleteCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler DeleteCampaign")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("DELETE", "/campaigns/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("DELETE", "/campaigns/{id}", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("campaign.id", id))
+
+	if err := h.service.DeleteCampaign(ctx, id); err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to delete campaign")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "campaign deleted successfully"})
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ad-service/pkg/utils"
+
+	"github.com/andybalholm/brotli"
+)
+
+// CompressOptions configures Compress. Zero values select the defaults.
+type CompressOptions struct {
+	// MinSize is the smallest body worth compressing, in bytes.
+	MinSize int
+	// ContentTypes lists the compressible media types. Types with a +json
+	// or +xml suffix follow application/json and application/xml.
+	ContentTypes []string
+	GzipLevel    int
+	BrotliLevel  int
+}
+
+var defaultCompressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/x-ndjson",
+	"application/xml",
+	"text/csv",
+	"text/html",
+	"text/plain",
+}
+
+// compressEncodings lists the supported encodings, preferred first.
+var compressEncodings = []string{"br", "gzip"}
+
+// Compress compresses responses with brotli or gzip, whichever the client
+// prefers, once they reach MinSize bytes of a compressible type. Responses
+// that set Content-Encoding themselves, such as exports, are passed through
+// untouched, and so are protocol upgrades.
+func Compress(options CompressOptions) func(http.Handler) http.Handler {
+	if options.MinSize <= 0 {
+		options.MinSize = 1024
+	}
+	if len(options.ContentTypes) == 0 {
+		options.ContentTypes = defaultCompressibleTypes
+	}
+	if options.GzipLevel == 0 {
+		options.GzipLevel = gzip.DefaultCompression
+	}
+	if options.BrotliLevel <= 0 {
+		options.BrotliLevel = 4
+	}
+
+	// Encoders are pooled: a brotli writer allocates its window up front.
+	pools := map[string]*sync.Pool{
+		"gzip": {New: func() interface{} {
+			gz, err := gzip.NewWriterLevel(io.Discard, options.GzipLevel)
+			if err != nil {
+				gz = gzip.NewWriter(io.Discard)
+			}
+			return gz
+		}},
+		"br": {New: func() interface{} {
+			return brotli.NewWriterLevel(io.Discard, options.BrotliLevel)
+		}},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), compressEncodings...)
+			if encoding == "" || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, options: &options, encoding: encoding, pool: pools[encoding], head: r.Method == http.MethodHead}
+			next.ServeHTTP(cw, r)
+			cw.close()
+		})
+	}
+}
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressWriter holds the start of a response back until it knows whether
+// the response is worth compressing.
+type compressWriter struct {
+	http.ResponseWriter
+	options  *CompressOptions
+	encoding string
+	pool     *sync.Pool
+	head     bool
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder encoder
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	if c.decided || status < http.StatusOK {
+		c.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if c.status != 0 {
+		return
+	}
+	c.status = status
+	if c.head || status == http.StatusNoContent || status == http.StatusNotModified {
+		c.decide(false)
+	}
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if !c.decided {
+		c.buf = append(c.buf, p...)
+		compressible := c.compressible()
+		if compressible && len(c.buf) < c.options.MinSize {
+			return len(p), nil
+		}
+		if err := c.decide(compressible); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if c.encoder != nil {
+		return c.encoder.Write(p)
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+// Flush sends what was written so far. A streamed response is compressed
+// even when the part written before the flush is small.
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		c.decide(len(c.buf) > 0 && c.compressible())
+	}
+	if c.encoder != nil {
+		c.encoder.Flush()
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// compressible reports whether the response may be compressed, judging by
+// the headers the handler set.
+func (c *compressWriter) compressible() bool {
+	header := c.Header()
+	if header.Get("Content-Encoding") != "" || c.status == http.StatusPartialContent {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		// Left to net/http, which sniffs the type from the first bytes.
+		contentType = http.DetectContentType(c.buf)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return slices.Contains(c.options.ContentTypes, mediaType) || slices.Contains(c.options.ContentTypes, "application/json")
+	case strings.HasSuffix(mediaType, "+xml"):
+		return slices.Contains(c.options.ContentTypes, mediaType) || slices.Contains(c.options.ContentTypes, "application/xml")
+	}
+	return slices.Contains(c.options.ContentTypes, mediaType)
+}
+
+// decide sends the headers, compressed or not, followed by the buffered
+// start of the body.
+func (c *compressWriter) decide(compress bool) error {
+	c.decided = true
+	header := c.Header()
+	if compress {
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", http.DetectContentType(c.buf))
+		}
+		header.Set("Content-Encoding", c.encoding)
+		header.Del("Content-Length")
+		utils.AddVary(header, "Accept-Encoding")
+		c.encoder = c.pool.Get().(encoder)
+		c.encoder.Reset(c.ResponseWriter)
+	} else if header.Get("Content-Encoding") == "" && c.compressible() {
+		// The same response may be compressed for a larger body.
+		utils.AddVary(header, "Accept-Encoding")
+	}
+
+	status := c.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	c.ResponseWriter.WriteHeader(status)
+
+	if len(c.buf) == 0 {
+		return nil
+	}
+	buf := c.buf
+	c.buf = nil
+	var err error
+	if c.encoder != nil {
+		_, err = c.encoder.Write(buf)
+	} else {
+		_, err = c.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close sends a response that stayed below MinSize and ends the compressed
+// stream.
+func (c *compressWriter) close() {
+	if !c.decided {
+		if c.status == 0 && len(c.buf) == 0 {
+			// Nothing was written; net/http sends its default response.
+			return
+		}
+		c.decide(false)
+	}
+	if c.encoder != nil {
+		c.encoder.Close()
+		c.encoder.Reset(io.Discard)
+		c.pool.Put(c.encoder)
+		c.encoder = nil
+	}
+}
+
+// negotiateEncoding returns the supported content coding the
+// Accept-Encoding header gives the highest weight, or "" when it accepts
+// none of them. Ties go to the earlier entry of supported.
+func negotiateEncoding(header string, supported ...string) string {
+	weights := make(map[string]float64)
+	for _, coding := range strings.Split(header, ",") {
+		name, params, err := mime.ParseMediaType(strings.TrimSpace(coding))
+		if err != nil {
+			continue
+		}
+		weight := 1.0
+		if q, ok := params["q"]; ok {
+			if weight, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		weights[name] = weight
+	}
+
+	best, bestWeight := "", 0.0
+	for _, encoding := range supported {
+		weight, ok := weights[encoding]
+		if !ok {
+			weight = weights["*"]
+		}
+		if weight > bestWeight {
+			best, bestWeight = encoding, weight
+		}
+	}
+	return best
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"title":"bike"}`, 100)
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		encoding       string
+		status         int
+		body           string
+		flush          bool
+		want           string
+	}{
+		{name: "large json", acceptEncoding: "gzip", contentType: "application/json", body: large, want: "gzip"},
+		{name: "brotli preferred", acceptEncoding: "gzip, br", contentType: "application/json", body: large, want: "br"},
+		{name: "gzip weighted higher", acceptEncoding: "gzip;q=1, br;q=0.5", contentType: "application/json", body: large, want: "gzip"},
+		{name: "below threshold", acceptEncoding: "gzip", contentType: "application/json", body: `{"id":1}`},
+		{name: "at threshold", acceptEncoding: "gzip", contentType: "application/json", body: strings.Repeat("a", 1024), want: "gzip"},
+		{name: "suffix type", acceptEncoding: "gzip", contentType: "application/problem+json", body: large, want: "gzip"},
+		{name: "image", acceptEncoding: "gzip", contentType: "image/png", body: large},
+		{name: "sniffed text", acceptEncoding: "gzip", body: strings.Repeat("plain text ", 200), want: "gzip"},
+		{name: "encoded already", acceptEncoding: "gzip", contentType: "text/csv", encoding: "gzip", body: large},
+		{name: "not accepted", contentType: "application/json", body: large},
+		{name: "refused", acceptEncoding: "gzip;q=0", contentType: "application/json", body: large},
+		{name: "small flushed stream", acceptEncoding: "gzip", contentType: "application/x-ndjson", body: `{"id":1}`, flush: true, want: "gzip"},
+		{name: "not modified", acceptEncoding: "gzip", contentType: "application/json", status: http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Compress(CompressOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				io.WriteString(w, tt.body)
+				if tt.flush {
+					http.NewResponseController(w).Flush()
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/ads", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			encoding := rec.Header().Get("Content-Encoding")
+			if tt.encoding == "" && encoding != tt.want {
+				t.Fatalf("Content-Encoding = %q, want %q", encoding, tt.want)
+			}
+			if tt.status != 0 && rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			var body io.Reader = rec.Body
+			switch tt.want {
+			case "gzip":
+				gz, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				body = gz
+			case "br":
+				body = brotli.NewReader(rec.Body)
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("reading the body: %v", err)
+			}
+			if string(got) != tt.body {
+				t.Errorf("body = %.40q..., want %.40q...", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "gzip", want: "gzip"},
+		{header: "gzip, deflate, br", want: "br"},
+		{header: "br;q=0.1, gzip;q=0.9", want: "gzip"},
+		{header: "*", want: "br"},
+		{header: "*;q=0.5, br;q=0", want: "gzip"},
+		{header: "identity", want: ""},
+		{header: "gzip;q=bogus", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := negotiateEncoding(tt.header, compressEncodings...); got != tt.want {
+				t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
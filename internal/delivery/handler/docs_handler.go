@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>Ad Service API</title>
+  <link rel="stylesheet" href="swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="swagger-ui-bundle.js"></script>
+  <script src="swagger-ui-standalone-preset.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "/openapi.json",
+      dom_id: "#swagger-ui",
+      presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+      layout: "StandaloneLayout"
+    });
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI document and a Swagger UI bundled into the
+// binary, so the docs work without access to a CDN.
+type DocsHandler struct {
+	spec   []byte
+	assets http.Handler
+}
+
+func NewDocsHandler(doc *openapi3.T) (*DocsHandler, error) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{
+		spec:   spec,
+		assets: http.StripPrefix("/docs", http.FileServer(swaggerFiles.HTTP)),
+	}, nil
+}
+
+func (h *DocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.spec)
+}
+
+func (h *DocsHandler) Page(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
+
+func (h *DocsHandler) Assets(w http.ResponseWriter, r *http.Request) {
+	h.assets.ServeHTTP(w, r)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorCode identifies a kind of problem. Codes are part of the API: clients
+// branch on them, so existing codes must not change meaning.
+type ErrorCode string
+
+const (
+	CodeAdNotFound          ErrorCode = "ad_not_found"
+	CodeTargetURLNotFound   ErrorCode = "target_url_not_found"
+	CodeCampaignNotFound    ErrorCode = "campaign_not_found"
+	CodeVariantNotFound     ErrorCode = "variant_not_found"
+	CodeAttributeNotFound   ErrorCode = "attribute_not_found"
+	CodeFavoriteNotFound    ErrorCode = "favorite_not_found"
+	CodeSavedSearchNotFound ErrorCode = "saved_search_not_found"
+	CodeWebhookNotFound     ErrorCode = "webhook_not_found"
+	CodeDeliveryNotFound    ErrorCode = "delivery_not_found"
+	CodeInvalidID           ErrorCode = "invalid_id"
+	CodeInvalidPayload      ErrorCode = "invalid_payload"
+	CodeValidationFailed    ErrorCode = "validation_failed"
+	CodeUnauthenticated     ErrorCode = "unauthenticated"
+	CodeMethodNotAllowed    ErrorCode = "method_not_allowed"
+	CodeConflict            ErrorCode = "conflict"
+	CodeUnsupportedVersion  ErrorCode = "unsupported_version"
+	CodeUnsupportedFormat   ErrorCode = "unsupported_format"
+	CodeUnsupportedMedia    ErrorCode = "unsupported_media_type"
+	CodeImportNotFound      ErrorCode = "import_not_found"
+	CodeJobNotFound         ErrorCode = "job_not_found"
+	CodeUnavailable         ErrorCode = "unavailable"
+	CodeInternal            ErrorCode = "internal_error"
+)
+
+var errorCatalogue = map[ErrorCode]struct {
+	status int
+	title  string
+}{
+	CodeAdNotFound:          {http.StatusNotFound, "Ad not found"},
+	CodeTargetURLNotFound:   {http.StatusNotFound, "Ad has no target URL"},
+	CodeCampaignNotFound:    {http.StatusNotFound, "Campaign not found"},
+	CodeVariantNotFound:     {http.StatusNotFound, "Variant not found"},
+	CodeAttributeNotFound:   {http.StatusNotFound, "Attribute definition not found"},
+	CodeFavoriteNotFound:    {http.StatusNotFound, "Favorite not found"},
+	CodeSavedSearchNotFound: {http.StatusNotFound, "Saved search not found"},
+	CodeWebhookNotFound:     {http.StatusNotFound, "Webhook subscription not found"},
+	CodeDeliveryNotFound:    {http.StatusNotFound, "Webhook delivery not found"},
+	CodeInvalidID:           {http.StatusBadRequest, "Invalid id"},
+	CodeInvalidPayload:      {http.StatusBadRequest, "Invalid request payload"},
+	CodeValidationFailed:    {http.StatusBadRequest, "Validation failed"},
+	CodeUnauthenticated:     {http.StatusUnauthorized, "Authentication required"},
+	CodeMethodNotAllowed:    {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeConflict:            {http.StatusConflict, "Conflict"},
+	CodeUnsupportedVersion:  {http.StatusNotAcceptable, "Unsupported API version"},
+	CodeUnsupportedFormat:   {http.StatusNotAcceptable, "Unsupported format"},
+	CodeUnsupportedMedia:    {http.StatusUnsupportedMediaType, "Unsupported media type"},
+	CodeImportNotFound:      {http.StatusNotFound, "Import not found"},
+	CodeJobNotFound:         {http.StatusNotFound, "Job not found"},
+	CodeUnavailable:         {http.StatusServiceUnavailable, "Service unavailable"},
+	CodeInternal:            {http.StatusInternalServerError, "Internal server error"},
+}
+
+func newProblem(r *http.Request, code ErrorCode, detail string) *utils.Problem {
+	entry := errorCatalogue[code]
+	return &utils.Problem{
+		Type:      utils.ProblemType(string(code)),
+		Title:     entry.title,
+		Status:    entry.status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      string(code),
+		RequestID: r.Header.Get(utils.RequestIDHeader),
+	}
+}
+
+func respondProblem(w http.ResponseWriter, r *http.Request, code ErrorCode, detail string) {
+	utils.RespondWithProblem(w, newProblem(r, code, detail))
+}
+
+// respondError maps an error from the services to a problem and returns
+// the status label for the request metrics. Errors outside the catalogue
+// are logged with logMessage and answered without details.
+func respondError(w http.ResponseWriter, r *http.Request, logger *logger.Loggers, span trace.Span, err error, logMessage string) string {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.Is(err, service.ErrAdNotFound):
+		respondProblem(w, r, CodeAdNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrCampaignNotFound):
+		respondProblem(w, r, CodeCampaignNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrVariantNotFound), errors.Is(err, service.ErrNoVariants):
+		respondProblem(w, r, CodeVariantNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrAttributeNotFound):
+		respondProblem(w, r, CodeAttributeNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrFavoriteNotFound):
+		respondProblem(w, r, CodeFavoriteNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrSavedSearchNotFound):
+		respondProblem(w, r, CodeSavedSearchNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrWebhookNotFound):
+		respondProblem(w, r, CodeWebhookNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrDeliveryNotFound):
+		respondProblem(w, r, CodeDeliveryNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrImportNotFound):
+		respondProblem(w, r, CodeImportNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, service.ErrJobNotFound):
+		respondProblem(w, r, CodeJobNotFound, err.Error())
+		return "not_found"
+	case errors.Is(err, errInvalidPayload):
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeInvalidPayload, err.Error())
+		return "error"
+	case errors.Is(err, service.ErrInvalidID):
+		span.SetAttributes(attribute.String("error", "invalid id parameter"))
+		respondProblem(w, r, CodeInvalidID, "invalid id parameter")
+		return "error"
+	case errors.Is(err, service.ErrInvalidUserID):
+		respondProblem(w, r, CodeUnauthenticated, "")
+		return "error"
+	case errors.Is(err, service.ErrInvalidEventType):
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return "error"
+	case errors.As(err, &validationErr):
+		span.SetAttributes(attribute.String("error", validationErr.Error()))
+		problem := newProblem(r, CodeValidationFailed, validationErr.Error())
+		problem.Errors = []utils.FieldError{{In: "request", Name: validationErr.Field, Message: validationErr.Message}}
+		utils.RespondWithProblem(w, problem)
+		return "error"
+	case errors.Is(err, service.ErrAttributeExists), errors.Is(err, service.ErrVariantExists), errors.Is(err, service.ErrExternalRefExists),
+		errors.Is(err, service.ErrJobFinished), errors.Is(err, service.ErrJobNotRetryable):
+		respondProblem(w, r, CodeConflict, err.Error())
+		return "conflict"
+	case errors.Is(err, service.ErrTrackingBufferFull), errors.Is(err, service.ErrTrackingStopped), errors.Is(err, service.ErrStreamClosed):
+		respondProblem(w, r, CodeUnavailable, err.Error())
+		return "error"
+	default:
+		logger.ErrorLogger.Error(logMessage, utils.Err(err))
+		span.SetAttributes(attribute.String("error", logMessage))
+		span.RecordError(err)
+		respondProblem(w, r, CodeInternal, "")
+		return "error"
+	}
+}
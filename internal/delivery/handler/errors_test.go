@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ad-service/internal/service"
+	"ad-service/pkg/utils"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRespondError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   ErrorCode
+		label  string
+	}{
+		{name: "ad", err: service.ErrAdNotFound, status: http.StatusNotFound, code: CodeAdNotFound, label: "not_found"},
+		{name: "campaign", err: fmt.Errorf("load: %w", service.ErrCampaignNotFound), status: http.StatusNotFound, code: CodeCampaignNotFound, label: "not_found"},
+		{name: "no variants", err: service.ErrNoVariants, status: http.StatusNotFound, code: CodeVariantNotFound, label: "not_found"},
+		{name: "webhook", err: service.ErrWebhookNotFound, status: http.StatusNotFound, code: CodeWebhookNotFound, label: "not_found"},
+		{name: "delivery", err: service.ErrDeliveryNotFound, status: http.StatusNotFound, code: CodeDeliveryNotFound, label: "not_found"},
+		{name: "saved search", err: service.ErrSavedSearchNotFound, status: http.StatusNotFound, code: CodeSavedSearchNotFound, label: "not_found"},
+		{name: "user", err: service.ErrInvalidUserID, status: http.StatusUnauthorized, code: CodeUnauthenticated, label: "error"},
+		{name: "event type", err: service.ErrInvalidEventType, status: http.StatusBadRequest, code: CodeValidationFailed, label: "error"},
+		{name: "validation", err: &service.ValidationError{Field: "url", Message: "must be https"}, status: http.StatusBadRequest, code: CodeValidationFailed, label: "error"},
+		{name: "variant exists", err: service.ErrVariantExists, status: http.StatusConflict, code: CodeConflict, label: "conflict"},
+		{name: "buffer full", err: service.ErrTrackingBufferFull, status: http.StatusServiceUnavailable, code: CodeUnavailable, label: "error"},
+		{name: "unknown", err: errors.New("boom"), status: http.StatusInternalServerError, code: CodeInternal, label: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/ads/1", nil)
+			span := trace.SpanFromContext(context.Background())
+
+			label := respondError(rec, req, testLoggers(), span, tt.err, "failed")
+
+			if label != tt.label {
+				t.Errorf("label = %q, want %q", label, tt.label)
+			}
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var problem utils.Problem
+			if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem.Code != string(tt.code) {
+				t.Errorf("code = %q, want %q", problem.Code, tt.code)
+			}
+		})
+	}
+}
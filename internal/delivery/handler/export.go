@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AdExporter writes the full ad catalogue in the representation of one API
+// version. It backs GET /ads/export and the export command.
+type AdExporter struct {
+	service service.AdService
+	codec   adCodec
+}
+
+func NewAdExporter(service service.AdService, version APIVersion, currency string) *AdExporter {
+	return &AdExporter{
+		service: service,
+		codec:   newAdCodec(version, currency),
+	}
+}
+
+// Export writes every ad matching filter to w as ndjson or csv and returns
+// the number of ads written. w is flushed every few ads; when it has a
+// Flush method, that is called as well.
+func (e *AdExporter) Export(ctx context.Context, w io.Writer, format string, filter domain.AdFilter) (int64, error) {
+	exportFormat := listFormat(strings.ToLower(format))
+	if !slices.Contains(exportFormats, exportFormat) {
+		return 0, fmt.Errorf("unsupported export format %q, use %s", format, formatNames(exportFormats))
+	}
+
+	enc := newAdEncoder(newFlushWriter(w), exportFormat, e.codec)
+	var count int64
+	err := e.service.ExportAds(ctx, filter, func(ad *domain.Ad) error {
+		if err := enc.encode(ad); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, enc.close()
+}
+
+// ParseExportFilter reads export filters from a query string in the syntax
+// of the GET /ads filters, such as category=cars&attr.doors.min=4.
+func ParseExportFilter(query string) (domain.AdFilter, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return domain.AdFilter{}, err
+	}
+	return parseAdFilter(values)
+}
+
+// ExportAds streams every ad matching the listing filters as NDJSON or CSV.
+// The export is read from a database cursor and written as it is read, so
+// neither side holds the catalogue in memory.
+func (h *AdHandler) ExportAds(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ExportAds")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", h.version.Prefix()+"/ads/export", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", h.version.Prefix()+"/ads/export", status).Observe(duration)
+	}()
+
+	format, err := negotiateFormat(r, exportFormats)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeUnsupportedFormat, err.Error())
+		return
+	}
+
+	filter, err := parseAdFilter(r.URL.Query())
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return
+	}
+
+	// Exports outlive the server write timeout.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	out := &exportResponse{w: w, format: format, filename: "ads." + string(format), gzip: acceptsGzip(r)}
+	span.SetAttributes(
+		attribute.String("ads.format", string(format)),
+		attribute.Bool("export.gzip", out.gzip),
+	)
+
+	count, err := h.exporter.Export(ctx, out, string(format), filter)
+	if err == nil {
+		err = out.Close()
+	}
+	span.SetAttributes(attribute.Int64("ads.count", count))
+	if err == nil {
+		return
+	}
+
+	if !out.started {
+		status = h.respondError(w, r, span, err, "failed to export ads")
+		return
+	}
+
+	// The status is out already. Aborting the connection keeps clients from
+	// mistaking a truncated export for a complete one.
+	status = "error"
+	span.RecordError(err)
+	h.logger.ErrorLogger.Error("failed to export ads", "exported", count, utils.Err(err))
+	panic(http.ErrAbortHandler)
+}
+
+// exportResponse holds the response headers back until the export writes
+// its first byte, so that an export failing up front still gets a problem
+// response.
+type exportResponse struct {
+	w        http.ResponseWriter
+	format   listFormat
+	filename string
+	gzip     bool
+	gz       *gzip.Writer
+	started  bool
+}
+
+func (e *exportResponse) start() {
+	e.started = true
+
+	header := e.w.Header()
+	header.Set("Content-Type", formatContentTypes[e.format])
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", e.filename))
+	utils.AddVary(header, "Accept", "Accept-Encoding")
+	if e.gzip {
+		header.Set("Content-Encoding", "gzip")
+		e.gz = gzip.NewWriter(e.w)
+	}
+	e.w.WriteHeader(http.StatusOK)
+}
+
+func (e *exportResponse) Write(p []byte) (int, error) {
+	if !e.started {
+		e.start()
+	}
+	if e.gz != nil {
+		return e.gz.Write(p)
+	}
+	return e.w.Write(p)
+}
+
+func (e *exportResponse) Flush() error {
+	if e.gz != nil {
+		if err := e.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// Close sends the headers of an empty export and ends the gzip stream.
+func (e *exportResponse) Close() error {
+	if !e.started {
+		e.start()
+	}
+	if e.gz != nil {
+		return e.gz.Close()
+	}
+	return nil
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	return negotiateEncoding(r.Header.Get("Accept-Encoding"), "gzip") == "gzip"
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type FavoriteHandler struct {
+	service service.FavoriteService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewFavoriteHandler(service service.FavoriteService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *FavoriteHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &FavoriteHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *FavoriteHandler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListFavorites")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/me/favorites", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/me/favorites", status).Observe(duration)
+	}()
+
+	query := r.URL.Query()
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10 // Default limit
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1 // Default page number
+	}
+
+	favorites, err := h.service.ListFavorites(ctx, userFromContext(ctx), limit, (page-1)*limit)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retrieve favorites")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, favorites)
+}
+
+func (h *FavoriteHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler AddFavorite")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("PUT", "/me/favorites/{adId}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("PUT", "/me/favorites/{adId}", status).Observe(duration)
+	}()
+
+	adID, err := parseIDParam(r, "adId")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	created, err := h.service.AddFavorite(ctx, userFromContext(ctx), adID)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to add favorite")
+		return
+	}
+
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+	}
+	utils.RespondWithJSON(w, code, map[string]interface{}{"ad_id": adID, "favorited": true})
+}
+
+func (h *FavoriteHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler RemoveFavorite")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("DELETE", "/me/favorites/{adId}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("DELETE", "/me/favorites/{adId}", status).Observe(duration)
+	}()
+
+	adID, err := parseIDParam(r, "adId")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	if err := h.service.RemoveFavorite(ctx, userFromContext(ctx), adID); err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to remove favorite")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "favorite removed successfully"})
+}
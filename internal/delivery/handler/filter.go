@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"ad-service/internal/domain"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const attributeParamPrefix = "attr."
+
+// parseAdFilter reads listing filters from the query string:
+// category=<slug>, attr.<name>=<value>, attr.<name>.min=<n>, attr.<name>.max=<n>,
+// tags=<a,b>, tags_mode=<any|all> and campaign_id=<id>.
+func parseAdFilter(query url.Values) (domain.AdFilter, error) {
+	filter := domain.AdFilter{
+		Category: query.Get("category"),
+		TagMode:  domain.TagMatchMode(query.Get("tags_mode")),
+	}
+
+	if tags := query.Get("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+
+	if campaignID := query.Get("campaign_id"); campaignID != "" {
+		id, err := strconv.ParseInt(campaignID, 10, 64)
+		if err != nil || id <= 0 {
+			return domain.AdFilter{}, fmt.Errorf("campaign_id must be a positive integer")
+		}
+		filter.CampaignID = id
+	}
+
+	byName := make(map[string]*domain.AttributeFilter)
+	for key := range query {
+		if !strings.HasPrefix(key, attributeParamPrefix) {
+			continue
+		}
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, attributeParamPrefix)
+		bound := ""
+		if strings.HasSuffix(name, ".min") || strings.HasSuffix(name, ".max") {
+			bound = name[len(name)-3:]
+			name = name[:len(name)-4]
+		}
+
+		attr, ok := byName[name]
+		if !ok {
+			attr = &domain.AttributeFilter{Name: name}
+			byName[name] = attr
+		}
+
+		switch bound {
+		case "":
+			attr.Value = value
+		default:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return domain.AdFilter{}, fmt.Errorf("%s must be a number", key)
+			}
+			if bound == "min" {
+				attr.Min = &n
+			} else {
+				attr.Max = &n
+			}
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		filter.Attributes = append(filter.Attributes, *byName[name])
+	}
+
+	return filter, nil
+}
+
+// parseStreamFilter reads the listing filters plus events=<type,...> and
+// ad_id=<id,...> for live event subscriptions.
+func parseStreamFilter(query url.Values) (domain.StreamFilter, error) {
+	adFilter, err := parseAdFilter(query)
+	if err != nil {
+		return domain.StreamFilter{}, err
+	}
+	filter := domain.StreamFilter{Filter: adFilter}
+
+	if events := query.Get("events"); events != "" {
+		for _, eventType := range strings.Split(events, ",") {
+			filter.Events = append(filter.Events, domain.AdEventType(strings.TrimSpace(eventType)))
+		}
+	}
+
+	if ids := query.Get("ad_id"); ids != "" {
+		for _, raw := range strings.Split(ids, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+			if err != nil || id <= 0 {
+				return domain.StreamFilter{}, fmt.Errorf("ad_id must be a list of positive integers")
+			}
+			filter.AdIDs = append(filter.AdIDs, id)
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,485 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/utils"
+)
+
+type listFormat string
+
+const (
+	formatJSON   listFormat = "json"
+	formatCSV    listFormat = "csv"
+	formatNDJSON listFormat = "ndjson"
+	formatXML    listFormat = "xml"
+)
+
+// flushEvery is the number of ads written between flushes, so that large
+// listings reach the client while they are being encoded.
+const flushEvery = 100
+
+var formatContentTypes = map[listFormat]string{
+	formatJSON:   "application/json",
+	formatCSV:    "text/csv; charset=utf-8",
+	formatNDJSON: "application/x-ndjson",
+	formatXML:    "application/xml; charset=utf-8",
+}
+
+var mediaTypeFormats = map[string]listFormat{
+	"application/json":     formatJSON,
+	"text/csv":             formatCSV,
+	"application/x-ndjson": formatNDJSON,
+	"application/ndjson":   formatNDJSON,
+	"application/xml":      formatXML,
+	"text/xml":             formatXML,
+	"text/*":               formatCSV,
+}
+
+var (
+	listFormats   = []listFormat{formatJSON, formatCSV, formatNDJSON, formatXML}
+	exportFormats = []listFormat{formatNDJSON, formatCSV}
+)
+
+// negotiateFormat picks one of the offered formats from the format query
+// parameter or else the Accept header, honouring q-values. Versioned JSON
+// media types count as JSON; wildcards and a missing header select the
+// first offered format.
+func negotiateFormat(r *http.Request, offered []listFormat) (listFormat, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format := listFormat(strings.ToLower(name))
+		if !slices.Contains(offered, format) {
+			return "", fmt.Errorf("unsupported format %q, use %s", name, formatNames(offered))
+		}
+		return format, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return offered[0], nil
+	}
+
+	type candidate struct {
+		format listFormat
+		q      float64
+	}
+	var candidates []candidate
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		format, ok := mediaTypeFormats[mediaType]
+		switch {
+		case mediaType == "*/*" || mediaType == "application/*":
+			format, ok = offered[0], true
+		case !ok && strings.HasPrefix(mediaType, vendorMediaTypePrefix) && strings.HasSuffix(mediaType, "+json"):
+			format, ok = formatJSON, true
+		}
+		if ok && slices.Contains(offered, format) {
+			candidates = append(candidates, candidate{format: format, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("none of the accepted media types is supported, use %s", formatNames(offered))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].format, nil
+}
+
+func formatNames(formats []listFormat) string {
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// writeAdList streams a page of ads in format. Pagination is part of the
+// JSON and XML documents and is sent in headers for CSV and NDJSON. Once
+// the first byte is out the status cannot change, so encoding errors are
+// returned for logging only.
+func writeAdList(w http.ResponseWriter, r *http.Request, format listFormat, codec adCodec, result *service.PaginationResult) error {
+	header := w.Header()
+	header.Set("Content-Type", formatContentTypes[format])
+	utils.AddVary(header, "Accept")
+	if format == formatCSV || format == formatNDJSON {
+		setPaginationHeaders(header, r, result)
+	}
+	w.WriteHeader(http.StatusOK)
+
+	out := newFlushWriter(w)
+	var err error
+	switch format {
+	case formatCSV, formatNDJSON:
+		err = writeAdRows(newAdEncoder(out, format, codec), result.Ads)
+	case formatXML:
+		err = writeAdsXML(out, codec, result)
+	default:
+		err = writeAdsJSON(out, codec, result)
+	}
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func setPaginationHeaders(header http.Header, r *http.Request, result *service.PaginationResult) {
+	header.Set("X-Current-Page", strconv.Itoa(result.CurrentPage))
+	header.Set("X-Total-Pages", strconv.Itoa(result.TotalPages))
+
+	link := func(page int, rel string) {
+		u := *r.URL
+		query := u.Query()
+		query.Set("page", strconv.Itoa(page))
+		u.RawQuery = query.Encode()
+		header.Add("Link", fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel))
+	}
+	if result.NextPage > 0 {
+		link(result.NextPage, "next")
+	}
+	if result.PrevPage > 0 {
+		link(result.PrevPage, "prev")
+	}
+}
+
+// flushWriter buffers output and pushes it to the client every flushEvery
+// ads, flushing any compression in between on the way.
+type flushWriter struct {
+	*bufio.Writer
+	w     io.Writer
+	count int
+}
+
+func newFlushWriter(w io.Writer) *flushWriter {
+	return &flushWriter{Writer: bufio.NewWriter(w), w: w}
+}
+
+func (f *flushWriter) adWritten() error {
+	f.count++
+	if f.count%flushEvery != 0 {
+		return nil
+	}
+	return f.Flush()
+}
+
+func (f *flushWriter) Flush() error {
+	if err := f.Writer.Flush(); err != nil {
+		return err
+	}
+	switch flusher := f.w.(type) {
+	case interface{ Flush() error }:
+		return flusher.Flush()
+	case http.Flusher:
+		flusher.Flush()
+	}
+	return nil
+}
+
+// writeAdsJSON writes the same document as encoding the codec's page, one
+// ad at a time.
+func writeAdsJSON(out *flushWriter, codec adCodec, result *service.PaginationResult) error {
+	pagination, err := json.Marshal(paginationOf(result))
+	if err != nil {
+		return err
+	}
+
+	if result.Ads == nil {
+		out.WriteString(`{"ads":null,`)
+	} else {
+		out.WriteString(`{"ads":[`)
+		for i, ad := range result.Ads {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			b, err := json.Marshal(codec.encodeAd(ad))
+			if err != nil {
+				return err
+			}
+			out.Write(b)
+			if err := out.adWritten(); err != nil {
+				return err
+			}
+		}
+		out.WriteString(`],`)
+	}
+	out.Write(pagination[1:])
+	_, err = out.WriteString("\n")
+	return err
+}
+
+// adEncoder writes ads one row at a time in a row-oriented format.
+type adEncoder interface {
+	encode(ad *domain.Ad) error
+	// close writes what is still buffered, including the CSV header of an
+	// empty listing.
+	close() error
+}
+
+func newAdEncoder(out *flushWriter, format listFormat, codec adCodec) adEncoder {
+	if format == formatCSV {
+		return &csvAdEncoder{out: out, csv: csv.NewWriter(out), codec: codec}
+	}
+	return &ndjsonAdEncoder{out: out, json: json.NewEncoder(out), codec: codec}
+}
+
+func writeAdRows(enc adEncoder, ads []*domain.Ad) error {
+	for _, ad := range ads {
+		if err := enc.encode(ad); err != nil {
+			return err
+		}
+	}
+	return enc.close()
+}
+
+type ndjsonAdEncoder struct {
+	out   *flushWriter
+	json  *json.Encoder
+	codec adCodec
+}
+
+func (e *ndjsonAdEncoder) encode(ad *domain.Ad) error {
+	if err := e.json.Encode(e.codec.encodeAd(ad)); err != nil {
+		return err
+	}
+	return e.out.adWritten()
+}
+
+func (e *ndjsonAdEncoder) close() error {
+	return e.out.Flush()
+}
+
+type csvAdEncoder struct {
+	out           *flushWriter
+	csv           *csv.Writer
+	codec         adCodec
+	headerWritten bool
+}
+
+func (e *csvAdEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.csv.Write(e.codec.csvHeader())
+}
+
+func (e *csvAdEncoder) encode(ad *domain.Ad) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	if err := e.csv.Write(e.codec.csvRecord(ad)); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.out.adWritten()
+}
+
+func (e *csvAdEncoder) close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.out.Flush()
+}
+
+// adCSVHeader names the columns of adCSVRecord around the price columns
+// of the API version.
+func adCSVHeader(price ...string) []string {
+	header := []string{"id", "title", "description"}
+	header = append(header, price...)
+	return append(header,
+		"category", "tags", "target_url", "campaign_id", "weight",
+		"favorite_count", "active", "created_at", "updated_at",
+		"attributes", "targeting", "external_ref",
+	)
+}
+
+// adCSVRecord flattens an ad into one row. Tags are joined with commas and
+// attributes and targeting are written as JSON.
+func adCSVRecord(ad *domain.Ad, price ...string) []string {
+	var campaignID string
+	if ad.CampaignID != nil {
+		campaignID = strconv.FormatInt(*ad.CampaignID, 10)
+	}
+
+	record := []string{strconv.FormatInt(ad.ID, 10), csvText(ad.Title), csvText(ad.Description)}
+	record = append(record, price...)
+	return append(record,
+		csvText(ad.Category),
+		csvText(strings.Join(ad.Tags, ",")),
+		csvText(ad.TargetURL),
+		campaignID,
+		strconv.Itoa(ad.Weight),
+		strconv.FormatInt(ad.FavoriteCount, 10),
+		strconv.FormatBool(ad.Active),
+		ad.CreatedAt.Format(time.RFC3339),
+		ad.UpdatedAt.Format(time.RFC3339),
+		csvJSON(ad.Attributes),
+		csvJSON(ad.Targeting),
+		csvText(ad.ExternalRef),
+	)
+}
+
+// csvText keeps spreadsheets from evaluating user text as a formula by
+// prefixing cells that start like one with a quote.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func csvJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return ""
+	}
+	return csvText(string(b))
+}
+
+// writeAdsXML mirrors the JSON of every ad as elements: objects become
+// nested elements and arrays repeat their element.
+func writeAdsXML(out *flushWriter, codec adCodec, result *service.PaginationResult) error {
+	out.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(out)
+	root := xml.StartElement{Name: xml.Name{Local: "ads"}, Attr: []xml.Attr{
+		{Name: xml.Name{Local: "current_page"}, Value: strconv.Itoa(result.CurrentPage)},
+		{Name: xml.Name{Local: "total_pages"}, Value: strconv.Itoa(result.TotalPages)},
+	}}
+	if result.NextPage > 0 {
+		root.Attr = append(root.Attr, xml.Attr{Name: xml.Name{Local: "next_page"}, Value: strconv.Itoa(result.NextPage)})
+	}
+	if result.PrevPage > 0 {
+		root.Attr = append(root.Attr, xml.Attr{Name: xml.Name{Local: "prev_page"}, Value: strconv.Itoa(result.PrevPage)})
+	}
+	if err := enc.EncodeToken(root); err != nil {
+		return err
+	}
+
+	for _, ad := range result.Ads {
+		b, err := json.Marshal(codec.encodeAd(ad))
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(strings.NewReader(string(b)))
+		dec.UseNumber()
+		if err := jsonToXML(enc, "ad", dec); err != nil {
+			return err
+		}
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		if err := out.adWritten(); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// jsonToXML transcodes the next JSON value of dec into an element called
+// name, keeping the order of object members. Keys that are not XML names,
+// such as attribute names with spaces, become an entry element with a key
+// attribute.
+func jsonToXML(enc *xml.Encoder, name string, dec *json.Decoder) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !xmlNamePattern.MatchString(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '[' {
+			for dec.More() {
+				if err := jsonToXML(enc, name, dec); err != nil {
+					return err
+				}
+			}
+			_, err := dec.Token()
+			return err
+		}
+
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := jsonToXML(enc, key.(string), dec); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	case nil:
+		return nil
+	default:
+		return enc.EncodeElement(fmt.Sprint(token), start)
+	}
+}
+
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+type pagination struct {
+	CurrentPage int `json:"current_page"`
+	NextPage    int `json:"next_page,omitempty"`
+	PrevPage    int `json:"prev_page,omitempty"`
+	TotalPages  int `json:"total_pages"`
+}
+
+func paginationOf(result *service.PaginationResult) pagination {
+	return pagination{
+		CurrentPage: result.CurrentPage,
+		NextPage:    result.NextPage,
+		PrevPage:    result.PrevPage,
+		TotalPages:  result.TotalPages,
+	}
+}
@@ -1,8 +1,7 @@
 package handler
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,26 +13,37 @@ import (
 
 	"ad-service/internal/infrastructure/metrics"
 
-	"github.com/go-chi/chi/v5"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type AdHandler struct {
-	service service.AdService
-	logger  *logger.Loggers
-	metrics *metrics.HandlerMetrics
-	tracer  trace.Tracer
+	service  service.AdService
+	version  APIVersion
+	codec    adCodec
+	exporter *AdExporter
+	importer *AdImporter
+	imports  service.ImportService
+	logger   *logger.Loggers
+	metrics  *metrics.HandlerMetrics
+	tracer   trace.Tracer
 }
 
-func NewAdHandler(service service.AdService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *AdHandler {
+// NewAdHandler serves the ad routes of one API version. Prices in v2 are
+// reported in currency.
+func NewAdHandler(service service.AdService, imports service.ImportService, version APIVersion, currency string, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *AdHandler {
 	tracer := otel.Tracer("ad-service/handler")
 	return &AdHandler{
-		service: service,
-		logger:  logger,
-		metrics: metrics,
-		tracer:  tracer,
+		service:  service,
+		version:  version,
+		codec:    newAdCodec(version, currency),
+		exporter: NewAdExporter(service, version, currency),
+		importer: NewAdImporter(version, currency),
+		imports:  imports,
+		logger:   logger,
+		metrics:  metrics,
+		tracer:   tracer,
 	}
 }
 
@@ -46,23 +56,21 @@ func (h *AdHandler) GetAdByID(w http.ResponseWriter, r *http.Request) {
 
 	defer func() {
 		duration := time.Since(startTime).Seconds()
-		h.metrics.RequestCount.WithLabelValues("GET", "/ads/{id}", status).Inc()
-		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/{id}", status).Observe(duration)
+		h.metrics.RequestCount.WithLabelValues("GET", h.version.Prefix()+"/ads/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", h.version.Prefix()+"/ads/{id}", status).Observe(duration)
 	}()
 
-	idParam := chi.URLParam(r, "id")
-	if idParam == "" {
-		status = "error"
-		span.SetAttributes(attribute.String("error", "missing id parameter"))
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "missing id parameter")
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = h.respondError(w, r, span, service.ErrInvalidID, "")
 		return
 	}
 
-	id, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil || id <= 0 {
+	selection, err := parseAdSelection(r.URL.Query())
+	if err != nil {
 		status = "error"
-		span.SetAttributes(attribute.String("error", "invalid id parameter"))
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "invalid id parameter")
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
 		return
 	}
 
@@ -70,26 +78,38 @@ func (h *AdHandler) GetAdByID(w http.ResponseWriter, r *http.Request) {
 
 	ad, err := h.service.GetAdByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidID) {
-			status = "error"
-			utils.RespondWithErrorJSON(w, http.StatusBadRequest, "invalid id parameter")
-		} else if errors.Is(err, service.ErrAdNotFound) {
-			status = "not_found"
-			utils.RespondWithErrorJSON(w, http.StatusNotFound, "ad not found")
-		} else {
-			status = "error"
-			h.logger.ErrorLogger.Error("failed to get ad by ID", utils.Err(err))
-			span.SetAttributes(attribute.String("error", "failed to get ad by ID"))
-			span.RecordError(err)
-			utils.RespondWithErrorJSON(w, http.StatusInternalServerError, "internal server error")
-		}
-		span.RecordError(err)
+		status = h.respondError(w, r, span, err, "failed to get ad by ID")
+		return
+	}
+
+	// Expanded resources change without the ad being updated, so responses
+	// with them carry no validators. Favorites do not update the ad either,
+	// so responses with the favorite count are only validated by the ETag.
+	lastModified := ad.UpdatedAt
+	if selection.selects("favorite_count") {
+		lastModified = time.Time{}
+	}
+	// The version may be negotiated through Accept on the same URL.
+	utils.AddVary(w.Header(), "Accept")
+	if len(selection.expand) == 0 && checkNotModified(w, r, adETag(ad, h.version, selection), lastModified) {
+		status = "not_modified"
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, ad)
+	relations, err := h.expandAds(ctx, selection, []*domain.Ad{ad})
+	if err != nil {
+		status = h.respondError(w, r, span, err, "failed to expand ad")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, selection.codec(h.codec, relations).encodeAd(ad))
 }
 
+// maxAdPageSize caps the limit of ad listings, which are buffered whole
+// before they are encoded. Full listings are streamed by the export.
+const maxAdPageSize = 100
+
 func (h *AdHandler) GetAllAds(w http.ResponseWriter, r *http.Request) {
 	ctx, span := h.tracer.Start(r.Context(), "Handler GetAllAds")
 	defer span.End()
@@ -99,16 +119,27 @@ func (h *AdHandler) GetAllAds(w http.ResponseWriter, r *http.Request) {
 
 	defer func() {
 		duration := time.Since(startTime).Seconds()
-		h.metrics.RequestCount.WithLabelValues("GET", "/ads", status).Inc()
-		h.metrics.RequestDuration.WithLabelValues("GET", "/ads", status).Observe(duration)
+		h.metrics.RequestCount.WithLabelValues("GET", h.version.Prefix()+"/ads", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", h.version.Prefix()+"/ads", status).Observe(duration)
 	}()
 
+	format, err := negotiateFormat(r, listFormats)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeUnsupportedFormat, err.Error())
+		return
+	}
+
 	query := r.URL.Query()
 
 	limit, err := strconv.Atoi(query.Get("limit"))
 	if err != nil || limit <= 0 {
 		limit = 10 // Default limit
 	}
+	if limit > maxAdPageSize {
+		limit = maxAdPageSize
+	}
 
 	page, err := strconv.Atoi(query.Get("page"))
 	if err != nil || page <= 0 {
@@ -127,24 +158,55 @@ func (h *AdHandler) GetAllAds(w http.ResponseWriter, r *http.Request) {
 		order = "ASC" // Default sort order
 	}
 
+	filter, err := parseAdFilter(query)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return
+	}
+	filter.Audience = requestedAudience(r)
+	if filter.Audience != nil {
+		utils.AddVary(w.Header(), "Accept-Language", "User-Agent", "X-Country", "CF-IPCountry")
+	}
+
+	selection, err := parseAdSelection(query)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return
+	}
+	filter.Fields = selection.load()
+
 	span.SetAttributes(
 		attribute.Int("ads.limit", limit),
 		attribute.Int("ads.offset", offset),
 		attribute.String("ads.sort_by", sortBy),
 		attribute.String("ads.order", order),
+		attribute.String("ads.category", filter.Category),
+		attribute.String("ads.format", string(format)),
+		attribute.StringSlice("ads.fields", selection.fields),
+		attribute.StringSlice("ads.expand", selection.expand),
 	)
 
-	result, err := h.service.GetAllAds(ctx, limit, offset, sortBy, order)
+	result, err := h.service.GetAllAds(ctx, limit, offset, sortBy, order, filter)
 	if err != nil {
-		status = "error"
-		h.logger.ErrorLogger.Error("failed to retrieve ads", utils.Err(err))
-		span.SetAttributes(attribute.String("error", "failed to retrieve ads"))
-		span.RecordError(err)
-		utils.RespondWithErrorJSON(w, http.StatusInternalServerError, "could not retrieve ads")
+		status = h.respondError(w, r, span, err, "failed to retrieve ads")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, result)
+	relations, err := h.expandAds(ctx, selection, result.Ads)
+	if err != nil {
+		status = h.respondError(w, r, span, err, "failed to expand ads")
+		return
+	}
+
+	if err := writeAdList(w, r, format, selection.codec(h.codec, relations), result); err != nil {
+		status = "error"
+		span.RecordError(err)
+		h.logger.ErrorLogger.Error("failed to write ads", "format", format, utils.Err(err))
+	}
 }
 
 func (h *AdHandler) CreateAd(w http.ResponseWriter, r *http.Request) {
@@ -156,17 +218,13 @@ func (h *AdHandler) CreateAd(w http.ResponseWriter, r *http.Request) {
 
 	defer func() {
 		duration := time.Since(startTime).Seconds()
-		h.metrics.RequestCount.WithLabelValues("POST", "/ads", status).Inc()
-		h.metrics.RequestDuration.WithLabelValues("POST", "/ads", status).Observe(duration)
+		h.metrics.RequestCount.WithLabelValues("POST", h.version.Prefix()+"/ads", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", h.version.Prefix()+"/ads", status).Observe(duration)
 	}()
 
-	var adReq domain.Ad
-	if err := json.NewDecoder(r.Body).Decode(&adReq); err != nil {
-		status = "error"
-		h.logger.ErrorLogger.Error("Invalid request payload", utils.Err(err))
-		span.SetAttributes(attribute.String("error", "Invalid request payload"))
-		span.RecordError(err)
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "Invalid request payload")
+	adReq, err := h.codec.decodeAd(r.Body)
+	if err != nil {
+		status = h.respondError(w, r, span, err, "")
 		return
 	}
 
@@ -175,17 +233,13 @@ func (h *AdHandler) CreateAd(w http.ResponseWriter, r *http.Request) {
 		attribute.Float64("ad.price", adReq.Price),
 	)
 
-	createdAd, err := h.service.CreateAd(ctx, &adReq)
+	createdAd, err := h.service.CreateAd(ctx, adReq)
 	if err != nil {
-		status = "error"
-		h.logger.ErrorLogger.Error("Could not create ad", utils.Err(err))
-		span.SetAttributes(attribute.String("error", "Could not create ad"))
-		span.RecordError(err)
-		utils.RespondWithErrorJSON(w, http.StatusInternalServerError, "Could not create ad")
+		status = h.respondError(w, r, span, err, "Could not create ad")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusCreated, createdAd)
+	utils.RespondWithJSON(w, http.StatusCreated, h.codec.encodeAd(createdAd))
 }
 
 func (h *AdHandler) UpdateAd(w http.ResponseWriter, r *http.Request) {
@@ -197,33 +251,19 @@ func (h *AdHandler) UpdateAd(w http.ResponseWriter, r *http.Request) {
 
 	defer func() {
 		duration := time.Since(startTime).Seconds()
-		h.metrics.RequestCount.WithLabelValues("PUT", "/ads/{id}", status).Inc()
-		h.metrics.RequestDuration.WithLabelValues("PUT", "/ads/{id}", status).Observe(duration)
+		h.metrics.RequestCount.WithLabelValues("PUT", h.version.Prefix()+"/ads/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("PUT", h.version.Prefix()+"/ads/{id}", status).Observe(duration)
 	}()
 
-	idParam := chi.URLParam(r, "id")
-	if idParam == "" {
-		status = "error"
-		span.SetAttributes(attribute.String("error", "missing id parameter"))
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "missing id parameter")
-		return
-	}
-
-	id, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil || id <= 0 {
-		status = "error"
-		span.SetAttributes(attribute.String("error", "invalid id parameter"))
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "invalid id parameter")
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = h.respondError(w, r, span, service.ErrInvalidID, "")
 		return
 	}
 
-	var adRequest domain.Ad
-	if err := json.NewDecoder(r.Body).Decode(&adRequest); err != nil {
-		status = "error"
-		h.logger.ErrorLogger.Error("failed to decode request body", utils.Err(err))
-		span.SetAttributes(attribute.String("error", "failed to decode request body"))
-		span.RecordError(err)
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "invalid request payload")
+	adRequest, err := h.codec.decodeAd(r.Body)
+	if err != nil {
+		status = h.respondError(w, r, span, err, "")
 		return
 	}
 
@@ -235,25 +275,13 @@ func (h *AdHandler) UpdateAd(w http.ResponseWriter, r *http.Request) {
 		attribute.Float64("ad.price", adRequest.Price),
 	)
 
-	updatedAd, err := h.service.UpdateAd(ctx, &adRequest)
+	updatedAd, err := h.service.UpdateAd(ctx, adRequest)
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidID) {
-			status = "error"
-			utils.RespondWithErrorJSON(w, http.StatusBadRequest, "invalid id parameter")
-		} else if errors.Is(err, service.ErrAdNotFound) {
-			status = "not_found"
-			utils.RespondWithErrorJSON(w, http.StatusNotFound, "ad not found")
-		} else {
-			status = "error"
-			h.logger.ErrorLogger.Error("failed to update ad", utils.Err(err))
-			span.SetAttributes(attribute.String("error", "failed to update ad"))
-			span.RecordError(err)
-			utils.RespondWithErrorJSON(w, http.StatusInternalServerError, "internal server error")
-		}
+		status = h.respondError(w, r, span, err, "failed to update ad")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, updatedAd)
+	utils.RespondWithJSON(w, http.StatusOK, h.codec.encodeAd(updatedAd))
 }
 
 func (h *AdHandler) DeleteAd(w http.ResponseWriter, r *http.Request) {
@@ -265,43 +293,32 @@ func (h *AdHandler) DeleteAd(w http.ResponseWriter, r *http.Request) {
 
 	defer func() {
 		duration := time.Since(startTime).Seconds()
-		h.metrics.RequestCount.WithLabelValues("DELETE", "/ads/{id}", status).Inc()
-		h.metrics.RequestDuration.WithLabelValues("DELETE", "/ads/{id}", status).Observe(duration)
+		h.metrics.RequestCount.WithLabelValues("DELETE", h.version.Prefix()+"/ads/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("DELETE", h.version.Prefix()+"/ads/{id}", status).Observe(duration)
 	}()
 
-	idParam := chi.URLParam(r, "id")
-	if idParam == "" {
-		status = "error"
-		span.SetAttributes(attribute.String("error", "missing id parameter"))
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "missing id parameter")
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = h.respondError(w, r, span, service.ErrInvalidID, "")
 		return
 	}
 
-	id, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil || id <= 0 {
-		status = "error"
-		span.SetAttributes(attribute.String("error", "invalid id parameter"))
-		utils.RespondWithErrorJSON(w, http.StatusBadRequest, "invalid id parameter")
+	if err := h.service.DeleteAd(ctx, id); err != nil {
+		status = h.respondError(w, r, span, err, "failed to delete ad")
 		return
 	}
 
-	err = h.service.DeleteAd(ctx, id)
-	if err != nil {
-		if errors.Is(err, service.ErrInvalidID) {
-			status = "error"
-			utils.RespondWithErrorJSON(w, http.StatusBadRequest, "invalid id parameter")
-		} else if errors.Is(err, service.ErrAdNotFound) {
-			status = "not_found"
-			utils.RespondWithErrorJSON(w, http.StatusNotFound, "ad not found")
-		} else {
-			status = "error"
-			h.logger.ErrorLogger.Error("failed to delete ad", utils.Err(err))
-			span.SetAttributes(attribute.String("error", "failed to delete ad"))
-			span.RecordError(err)
-			utils.RespondWithErrorJSON(w, http.StatusInternalServerError, "internal server error")
-		}
-		return
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "ad deleted successfully"})
+}
+
+// expandAds loads the related resources the selection embeds, if any.
+func (h *AdHandler) expandAds(ctx context.Context, selection adSelection, ads []*domain.Ad) (*domain.AdRelations, error) {
+	if len(selection.expand) == 0 {
+		return nil, nil
 	}
+	return h.service.ExpandAds(ctx, ads, selection.expand)
+}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "ad deleted successfully"})
+func (h *AdHandler) respondError(w http.ResponseWriter, r *http.Request, span trace.Span, err error, logMessage string) string {
+	return respondError(w, r, h.logger, span, err, logMessage)
 }
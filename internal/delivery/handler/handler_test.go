@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+)
+
+type fakeListAdService struct {
+	service.AdService
+	limit int
+}
+
+func (s *fakeListAdService) GetAllAds(ctx context.Context, limit int, offset int, sortBy string, order string, filter domain.AdFilter) (*service.PaginationResult, error) {
+	s.limit = limit
+	return &service.PaginationResult{Ads: []*domain.Ad{}, CurrentPage: 1, TotalPages: 1}, nil
+}
+
+func TestGetAllAdsLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		limit  int
+	}{
+		{name: "default", target: "/ads", limit: 10},
+		{name: "requested", target: "/ads?limit=50", limit: 50},
+		{name: "capped", target: "/ads?limit=10000000", limit: maxAdPageSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ads := &fakeListAdService{}
+			h := NewAdHandler(ads, nil, APIv2, "USD", testLoggers(), testMetrics)
+
+			rec := httptest.NewRecorder()
+			h.GetAllAds(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+			}
+			if ads.limit != tt.limit {
+				t.Errorf("limit = %d, want %d", ads.limit, tt.limit)
+			}
+		})
+	}
+}
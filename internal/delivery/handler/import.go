@@ -0,0 +1,435 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Imports read the formats that exports write, so that an export can be
+// imported again.
+var importFormats = exportFormats
+
+// maxImportLine bounds one NDJSON row.
+const maxImportLine = 1 << 20
+
+// AdImporter reads import files in the representation of one API version.
+// It backs POST /ads/import and the import command.
+type AdImporter struct {
+	codec adCodec
+}
+
+func NewAdImporter(version APIVersion, currency string) *AdImporter {
+	return &AdImporter{codec: newAdCodec(version, currency)}
+}
+
+// Reader returns the reader of the ndjson or csv format. NDJSON rows are ads
+// as the API accepts them; CSV files have the columns of the CSV export, of
+// which id, favorite_count, created_at and updated_at are ignored.
+func (i *AdImporter) Reader(format string) (service.NewAdReader, error) {
+	switch listFormat(strings.ToLower(format)) {
+	case formatNDJSON:
+		return func(body io.Reader) service.AdReader { return newNDJSONAdReader(body, i.codec) }, nil
+	case formatCSV:
+		return func(body io.Reader) service.AdReader { return newCSVAdReader(body, i.codec) }, nil
+	}
+	return nil, fmt.Errorf("unsupported import format %q, use %s", format, formatNames(importFormats))
+}
+
+// ImportReaders returns the readers of background imports, which record the
+// API version of their upload.
+func ImportReaders(currency string) service.ImportReaders {
+	return func(format string, version int) (service.NewAdReader, error) {
+		apiVersion := APIVersion(version)
+		if !apiVersion.IsValid() {
+			return nil, fmt.Errorf("unsupported API version %d", version)
+		}
+		return NewAdImporter(apiVersion, currency).Reader(format)
+	}
+}
+
+// importFormat returns the import format named by the Content-Type header.
+func importFormat(r *http.Request) (listFormat, bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", false
+	}
+	format, ok := mediaTypeFormats[mediaType]
+	return format, ok && !strings.Contains(mediaType, "*") && slices.Contains(importFormats, format)
+}
+
+// ImportAds accepts a CSV or NDJSON upload and enqueues its import. The
+// response points at the job, which reports the progress and the rows that
+// were skipped.
+func (h *AdHandler) ImportAds(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ImportAds")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", h.version.Prefix()+"/ads/import", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", h.version.Prefix()+"/ads/import", status).Observe(duration)
+	}()
+
+	format, ok := importFormat(r)
+	if !ok {
+		status = "error"
+		respondProblem(w, r, CodeUnsupportedMedia, "send text/csv or application/x-ndjson")
+		return
+	}
+
+	var dryRun bool
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			status = h.respondError(w, r, span, &service.ValidationError{Field: "dry_run", Message: "must be a boolean"}, "")
+			return
+		}
+	}
+
+	if _, err := h.importer.Reader(string(format)); err != nil {
+		status = h.respondError(w, r, span, err, "failed to read import")
+		return
+	}
+
+	// Uploads outlive the server read timeout.
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+	job, err := h.imports.SubmitImport(ctx, string(format), int(h.version), dryRun, r.Body)
+	if err != nil {
+		status = h.respondError(w, r, span, err, "failed to submit import")
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("import.id", job.ID),
+		attribute.String("import.format", job.Format),
+		attribute.Bool("import.dry_run", job.DryRun),
+	)
+
+	w.Header().Set("Location", "/imports/"+job.ID)
+	utils.RespondWithJSON(w, http.StatusAccepted, job)
+}
+
+// ImportHandler reports on imports, which are the same for every API
+// version.
+type ImportHandler struct {
+	service service.ImportService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewImportHandler(service service.ImportService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *ImportHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &ImportHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *ImportHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetImport")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/imports/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/imports/{id}", status).Observe(duration)
+	}()
+
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String("import.id", id))
+
+	job, err := h.service.GetImportJob(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to get import")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, job)
+}
+
+// GetImportErrors downloads the rows an import skipped as CSV.
+func (h *ImportHandler) GetImportErrors(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetImportErrors")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/imports/{id}/errors", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/imports/{id}/errors", status).Observe(duration)
+	}()
+
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String("import.id", id))
+
+	out := &exportResponse{w: w, format: formatCSV, filename: "import-" + id + "-errors.csv", gzip: acceptsGzip(r)}
+	err := WriteImportErrors(ctx, out, h.service, id)
+	if err == nil {
+		err = out.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !out.started {
+		status = respondError(w, r, h.logger, span, err, "failed to get import errors")
+		return
+	}
+
+	status = "error"
+	span.RecordError(err)
+	h.logger.ErrorLogger.Error("failed to write import errors", "import_id", id, utils.Err(err))
+	panic(http.ErrAbortHandler)
+}
+
+// WriteImportErrors writes the error report of an import as CSV, one row
+// per skipped row of the import.
+func WriteImportErrors(ctx context.Context, w io.Writer, imports service.ImportService, id string) error {
+	out := newFlushWriter(w)
+	report := csv.NewWriter(out)
+	if err := report.Write([]string{"row", "external_ref", "field", "message"}); err != nil {
+		return err
+	}
+
+	err := imports.StreamImportErrors(ctx, id, func(rowErr domain.ImportRowError) error {
+		if err := report.Write([]string{
+			strconv.Itoa(rowErr.Row),
+			csvText(rowErr.ExternalRef),
+			csvText(rowErr.Field),
+			csvText(rowErr.Message),
+		}); err != nil {
+			return err
+		}
+		report.Flush()
+		if err := report.Error(); err != nil {
+			return err
+		}
+		return out.adWritten()
+	})
+	if err != nil {
+		return err
+	}
+
+	report.Flush()
+	if err := report.Error(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// ndjsonAdReader reads one ad per line and skips blank lines.
+type ndjsonAdReader struct {
+	scanner *bufio.Scanner
+	codec   adCodec
+	row     int
+}
+
+func newNDJSONAdReader(body io.Reader, codec adCodec) *ndjsonAdReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+	return &ndjsonAdReader{scanner: scanner, codec: codec}
+}
+
+func (r *ndjsonAdReader) Next() (service.ImportRow, error) {
+	for r.scanner.Scan() {
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		r.row++
+
+		ad, err := r.codec.decodeAd(bytes.NewReader(line))
+		if err != nil {
+			return service.ImportRow{}, rowError(r.row, err)
+		}
+		return service.ImportRow{Number: r.row, Ad: ad}, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return service.ImportRow{}, fmt.Errorf("row %d is longer than %d bytes", r.row+1, maxImportLine)
+		}
+		return service.ImportRow{}, err
+	}
+	return service.ImportRow{}, io.EOF
+}
+
+// csvAdReader reads a CSV file with a header row naming its columns.
+type csvAdReader struct {
+	csv     *csv.Reader
+	codec   adCodec
+	columns map[string]int
+	row     int
+}
+
+func newCSVAdReader(body io.Reader, codec adCodec) *csvAdReader {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	return &csvAdReader{csv: reader, codec: codec}
+}
+
+func (r *csvAdReader) readHeader() error {
+	header, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the CSV header: %w", err)
+	}
+
+	r.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		r.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := r.columns["title"]; !ok {
+		return errors.New("the CSV header has no title column")
+	}
+	return nil
+}
+
+func (r *csvAdReader) Next() (service.ImportRow, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return service.ImportRow{}, err
+		}
+	}
+
+	record, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return service.ImportRow{}, io.EOF
+	}
+	r.row++
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return service.ImportRow{}, &service.RowError{Row: r.row, Message: parseErr.Err.Error()}
+	}
+	if err != nil {
+		return service.ImportRow{}, err
+	}
+
+	row := csvRow{columns: r.columns, record: record}
+	ad, err := r.codec.decodeCSV(row)
+	if err != nil {
+		rowErr := rowError(r.row, err)
+		rowErr.ExternalRef = row.get("external_ref")
+		return service.ImportRow{}, rowErr
+	}
+	return service.ImportRow{Number: r.row, Ad: ad}, nil
+}
+
+func rowError(row int, err error) *service.RowError {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return &service.RowError{Row: row, Field: validationErr.Field, Message: validationErr.Message}
+	}
+	return &service.RowError{Row: row, Message: err.Error()}
+}
+
+// csvRow is a CSV record whose cells are looked up by column name.
+type csvRow struct {
+	columns map[string]int
+	record  []string
+}
+
+// get returns the cell of a column, or "" when the column is missing. The
+// quote csvText adds in front of formula-like text is removed.
+func (r csvRow) get(name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	value := r.record[i]
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// adFromCSV reads the columns shared by every API version, in the layout
+// of adCSVRecord.
+func adFromCSV(row csvRow) (*domain.Ad, error) {
+	ad := &domain.Ad{
+		ExternalRef: row.get("external_ref"),
+		Title:       row.get("title"),
+		Description: row.get("description"),
+		Category:    strings.TrimSpace(row.get("category")),
+		TargetURL:   strings.TrimSpace(row.get("target_url")),
+	}
+
+	for _, tag := range strings.Split(row.get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ad.Tags = append(ad.Tags, tag)
+		}
+	}
+
+	if value := strings.TrimSpace(row.get("campaign_id")); value != "" {
+		campaignID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, &service.ValidationError{Field: "campaign_id", Message: "must be an integer"}
+		}
+		ad.CampaignID = &campaignID
+	}
+	if value := strings.TrimSpace(row.get("weight")); value != "" {
+		weight, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, &service.ValidationError{Field: "weight", Message: "must be an integer"}
+		}
+		ad.Weight = weight
+	}
+	if value := strings.TrimSpace(row.get("active")); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, &service.ValidationError{Field: "active", Message: "must be true or false"}
+		}
+		ad.Active = active
+	}
+	if value := row.get("attributes"); value != "" {
+		if err := json.Unmarshal([]byte(value), &ad.Attributes); err != nil {
+			return nil, &service.ValidationError{Field: "attributes", Message: "must be a JSON object"}
+		}
+	}
+	if value := row.get("targeting"); value != "" {
+		if err := json.Unmarshal([]byte(value), &ad.Targeting); err != nil {
+			return nil, &service.ValidationError{Field: "targeting", Message: "must be a JSON object"}
+		}
+	}
+	return ad, nil
+}
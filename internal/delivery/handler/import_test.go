@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"ad-service/internal/service"
+)
+
+// readImport reads every row of an import and describes each one as
+// "<row> <title>" or "<row> error <field>".
+func readImport(t *testing.T, version APIVersion, format, body string) ([]string, error) {
+	t.Helper()
+	newReader, err := NewAdImporter(version, "USD").Reader(format)
+	if err != nil {
+		t.Fatalf("Reader(%q) error = %v", format, err)
+	}
+	reader := newReader(strings.NewReader(body))
+
+	var rows []string
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		var rowErr *service.RowError
+		if errors.As(err, &rowErr) {
+			rows = append(rows, fmt.Sprintf("%d error %s", rowErr.Row, rowErr.Field))
+			continue
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, fmt.Sprintf("%d %s", row.Number, row.Ad.Title))
+	}
+}
+
+func TestImportReaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		version APIVersion
+		format  string
+		body    string
+		rows    []string
+		wantErr bool
+	}{
+		{
+			name:   "ndjson",
+			format: "ndjson",
+			body:   "{\"title\":\"Bike\",\"price\":10}\n\n{\"title\":\"Car\"}\n",
+			rows:   []string{"1 Bike", "2 Car"},
+		},
+		{
+			name:   "ndjson bad row",
+			format: "ndjson",
+			body:   "{\"title\":\"Bike\"}\n{\"title\":\n{\"title\":\"Car\"}",
+			rows:   []string{"1 Bike", "2 error ", "3 Car"},
+		},
+		{
+			name:    "ndjson v2 currency",
+			version: APIv2,
+			format:  "ndjson",
+			body:    "{\"title\":\"Bike\",\"price\":{\"amount\":\"10.50\",\"currency\":\"usd\"}}\n{\"title\":\"Car\",\"price\":{\"amount\":\"1\",\"currency\":\"EUR\"}}\n",
+			rows:    []string{"1 Bike", "2 error price.currency"},
+		},
+		{
+			name:    "ndjson line too long",
+			format:  "ndjson",
+			body:    "{\"title\":\"" + strings.Repeat("a", maxImportLine) + "\"}\n",
+			wantErr: true,
+		},
+		{
+			name:   "csv",
+			format: "csv",
+			body:   "\ufeffTitle,price,tags,active\nBike,10,\"road, used\",true\nCar,,,\n",
+			rows:   []string{"1 Bike", "2 Car"},
+		},
+		{
+			name:   "csv bad cells",
+			format: "csv",
+			body:   "title,price,weight,active,attributes\nBike,ten,,,\nCar,,heavy,,\nBus,,,maybe,\nVan,,,,[1]\nTram,1,2,false,{}\n",
+			rows:   []string{"1 error price", "2 error weight", "3 error active", "4 error attributes", "5 Tram"},
+		},
+		{
+			name:   "csv formula quote",
+			format: "csv",
+			body:   "title\n'=SUM(A1)\n",
+			rows:   []string{"1 =SUM(A1)"},
+		},
+		{
+			name:   "csv ragged rows",
+			format: "csv",
+			body:   "title,description\nBike\n\"Car,x\n",
+			rows:   []string{"1 Bike", "2 error "},
+		},
+		{
+			name:    "csv without title",
+			format:  "csv",
+			body:    "name,price\nBike,10\n",
+			wantErr: true,
+		},
+		{
+			name:   "empty csv",
+			format: "csv",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := tt.version
+			if version == 0 {
+				version = APIv1
+			}
+			rows, err := readImport(t, version, tt.format, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reading the import: error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestAdImporterUnknownFormat(t *testing.T) {
+	if _, err := NewAdImporter(APIv1, "USD").Reader("xml"); err == nil {
+		t.Error("Reader(\"xml\") accepted a format that cannot be imported")
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type JobHandler struct {
+	service service.JobService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewJobHandler(service service.JobService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *JobHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &JobHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+// jobProgressResponse is the small view of a job that clients poll while it
+// runs. Percent is left out while the total is unknown.
+type jobProgressResponse struct {
+	ID        string           `json:"id"`
+	Status    domain.JobStatus `json:"status"`
+	Done      int64            `json:"done"`
+	Total     int64            `json:"total"`
+	Percent   *float64         `json:"percent,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListJobs")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/jobs", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/jobs", status).Observe(duration)
+	}()
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	filter := domain.JobFilter{Type: query.Get("type"), Status: domain.JobStatus(query.Get("status"))}
+
+	jobs, err := h.service.ListJobs(ctx, filter, limit)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to list jobs")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, jobs)
+}
+
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/jobs/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/jobs/{id}", status).Observe(duration)
+	}()
+
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String("job.id", id))
+
+	job, err := h.service.GetJob(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to get job")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, job)
+}
+
+func (h *JobHandler) GetJobProgress(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetJobProgress")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/jobs/{id}/progress", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/jobs/{id}/progress", status).Observe(duration)
+	}()
+
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String("job.id", id))
+
+	job, err := h.service.GetJob(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to get job")
+		return
+	}
+
+	response := jobProgressResponse{
+		ID:        job.ID,
+		Status:    job.Status,
+		Done:      job.Progress.Done,
+		Total:     job.Progress.Total,
+		Error:     job.Error,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if percent := job.Progress.Percent(); percent >= 0 {
+		response.Percent = &percent
+	}
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// CancelJob answers 202 because a running job stops with the next heartbeat
+// of its worker, which may run on another instance.
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler CancelJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/jobs/{id}/cancel", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/jobs/{id}/cancel", status).Observe(duration)
+	}()
+
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String("job.id", id))
+
+	job, err := h.service.CancelJob(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to cancel job")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, job)
+}
+
+func (h *JobHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler RetryJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/jobs/{id}/retry", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/jobs/{id}/retry", status).Observe(duration)
+	}()
+
+	id := chi.URLParam(r, "id")
+	span.SetAttributes(attribute.String("job.id", id))
+
+	job, err := h.service.RetryJob(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retry job")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, job)
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/pkg/logger"
+)
+
+// Metrics register with the default Prometheus registry, so the tests of
+// the package share one set.
+var testMetrics = metrics.NewHandlerMetrics()
+
+func testLoggers() *logger.Loggers {
+	loggers, err := logger.SetupLogger("test")
+	if err != nil {
+		panic(err)
+	}
+	return loggers
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// OpenAPIValidator checks requests to documented operations against the
+// OpenAPI document before they reach the handlers. Requests to routes the
+// document does not describe pass through unchecked.
+type OpenAPIValidator struct {
+	router            routers.Router
+	logger            *logger.Loggers
+	validateResponses bool
+}
+
+// NewOpenAPIValidator builds the validator. With validateResponses set,
+// responses are buffered and checked too, and mismatches are logged; this
+// is meant for development since it costs a copy of every response.
+// Streamed responses such as exports and event streams are not checked.
+func NewOpenAPIValidator(doc *openapi3.T, logger *logger.Loggers, validateResponses bool) (*OpenAPIValidator, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	for _, contentType := range []string{"application/x-ndjson", "application/xml"} {
+		if openapi3filter.RegisteredBodyDecoder(contentType) == nil {
+			openapi3filter.RegisterBodyDecoder(contentType, textBodyDecoder)
+		}
+	}
+	return &OpenAPIValidator{
+		router:            router,
+		logger:            logger,
+		validateResponses: validateResponses,
+	}, nil
+}
+
+func (v *OpenAPIValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:          true,
+				SkipSettingDefaults: true,
+				AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+				ExcludeRequestBody:  isUpload(r),
+			},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			if isUnsupportedMediaType(err) {
+				respondProblem(w, r, CodeUnsupportedMedia, "Content-Type "+r.Header.Get("Content-Type")+" is not accepted here")
+				return
+			}
+			problem := newProblem(r, CodeValidationFailed, "request does not match the API description")
+			problem.Errors = fieldErrors(err)
+			utils.RespondWithProblem(w, problem)
+			return
+		}
+
+		if !v.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if recorder.streamed {
+			return
+		}
+
+		err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 recorder.status,
+			Header:                 recorder.Header(),
+			Body:                   io.NopCloser(&recorder.body),
+			Options:                &openapi3filter.Options{MultiError: true, IncludeResponseStatus: true},
+		})
+		if err != nil {
+			v.logger.ErrorLogger.Error("response does not match the API description",
+				"method", r.Method,
+				"route", route.Path,
+				"status", recorder.status,
+				utils.Err(err),
+			)
+		}
+	})
+}
+
+// fieldErrors flattens the errors of a failed request validation.
+func fieldErrors(err error) []utils.FieldError {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var result []utils.FieldError
+		for _, e := range multi {
+			result = append(result, fieldErrors(e)...)
+		}
+		return result
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return []utils.FieldError{{In: "request", Message: err.Error()}}
+	}
+
+	if requestErr.Parameter != nil {
+		fieldErr := utils.FieldError{In: requestErr.Parameter.In, Name: requestErr.Parameter.Name, Message: requestErr.Reason}
+		var schemaErr *openapi3.SchemaError
+		if errors.As(requestErr.Err, &schemaErr) {
+			fieldErr.Message = schemaErr.Reason
+		} else if requestErr.Err != nil {
+			fieldErr.Message = requestErr.Err.Error()
+		}
+		return []utils.FieldError{fieldErr}
+	}
+
+	if requestErr.Err == nil {
+		return []utils.FieldError{{In: "body", Message: requestErr.Reason}}
+	}
+	var result []utils.FieldError
+	for _, schemaErr := range schemaErrors(requestErr.Err) {
+		result = append(result, utils.FieldError{
+			In:      "body",
+			Pointer: jsonPointer(schemaErr.JSONPointer()),
+			Message: schemaErr.Reason,
+		})
+	}
+	if len(result) == 0 {
+		result = append(result, utils.FieldError{In: "body", Message: requestErr.Err.Error()})
+	}
+	return result
+}
+
+// isUnsupportedMediaType reports whether the request body was rejected for
+// its Content-Type, which kin-openapi only tells apart by the reason.
+func isUnsupportedMediaType(err error) bool {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		for _, e := range multi {
+			if isUnsupportedMediaType(e) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var requestErr *openapi3filter.RequestError
+	return errors.As(err, &requestErr) && requestErr.RequestBody != nil &&
+		strings.HasPrefix(requestErr.Reason, "header Content-Type has unexpected value")
+}
+
+func schemaErrors(err error) []*openapi3.SchemaError {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var result []*openapi3.SchemaError
+		for _, e := range multi {
+			result = append(result, schemaErrors(e)...)
+		}
+		return result
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []*openapi3.SchemaError{schemaErr}
+	}
+	return nil
+}
+
+func jsonPointer(path []string) string {
+	var b strings.Builder
+	for _, segment := range path {
+		b.WriteByte('/')
+		segment = strings.ReplaceAll(segment, "~", "~0")
+		b.WriteString(strings.ReplaceAll(segment, "/", "~1"))
+	}
+	return b.String()
+}
+
+// responseRecorder passes the response through while keeping a copy of the
+// status and body for validation. Streamed responses are passed through
+// without a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+	streamed    bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+		r.streamed = isStreamed(r.Header())
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if !r.streamed {
+		r.body.Write(p)
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// isStreamed reports whether a response is streamed rather than sent whole:
+// event streams, export downloads and bodies that are already encoded. They
+// can grow without bound and cannot be decoded for validation.
+func isStreamed(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/event-stream" ||
+		header.Get("Content-Encoding") != "" ||
+		strings.HasPrefix(header.Get("Content-Disposition"), "attachment")
+}
+
+// isUpload reports whether the request body is an import upload. Uploads
+// are streamed to the handler rather than buffered for validation.
+func isUpload(r *http.Request) bool {
+	_, ok := importFormat(r)
+	return ok
+}
+
+// textBodyDecoder reads bodies the validator cannot parse, such as the
+// streamed listings, as plain strings.
+func textBodyDecoder(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
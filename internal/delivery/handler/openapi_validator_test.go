@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   map[string]string
+		streamed bool
+	}{
+		{name: "json", header: map[string]string{"Content-Type": "application/json"}},
+		{name: "paged csv", header: map[string]string{"Content-Type": "text/csv; charset=utf-8"}},
+		{name: "event stream", header: map[string]string{"Content-Type": "text/event-stream"}, streamed: true},
+		{name: "export", header: map[string]string{"Content-Type": "text/csv", "Content-Disposition": `attachment; filename="ads.csv"`}, streamed: true},
+		{name: "encoded", header: map[string]string{"Content-Type": "application/x-ndjson", "Content-Encoding": "gzip"}, streamed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			recorder := &responseRecorder{ResponseWriter: rec, status: http.StatusOK}
+			for name, value := range tt.header {
+				recorder.Header().Set(name, value)
+			}
+
+			controller := http.NewResponseController(recorder)
+			recorder.Write([]byte("first"))
+			if err := controller.Flush(); err != nil {
+				t.Fatalf("Flush() through the recorder: %v", err)
+			}
+			recorder.Write([]byte("second"))
+
+			if !rec.Flushed {
+				t.Error("flush did not reach the client")
+			}
+			if rec.Body.String() != "firstsecond" {
+				t.Errorf("client got %q", rec.Body.String())
+			}
+			if recorder.streamed != tt.streamed {
+				t.Errorf("streamed = %v, want %v", recorder.streamed, tt.streamed)
+			}
+			wantCopy := "firstsecond"
+			if tt.streamed {
+				wantCopy = ""
+			}
+			if recorder.body.String() != wantCopy {
+				t.Errorf("recorded copy %q, want %q", recorder.body.String(), wantCopy)
+			}
+		})
+	}
+}
+
+func TestResponseRecorderUnwrap(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var w http.ResponseWriter = &responseRecorder{ResponseWriter: &cacheControlWriter{ResponseWriter: rec}}
+	for {
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = unwrapper.Unwrap()
+	}
+	if w != rec {
+		t.Errorf("unwrapping ends at %T, want the client's writer", w)
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var errInvalidIDParam = errors.New("invalid id parameter")
+
+const maxViewerIDLength = 64
+
+func parseIDParam(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errInvalidIDParam
+	}
+	return id, nil
+}
+
+// explicitViewerID returns the viewer id the request carries, or "" for an
+// anonymous viewer.
+func explicitViewerID(r *http.Request) string {
+	id := r.URL.Query().Get("viewer")
+	if id == "" {
+		id = r.Header.Get("X-Viewer-ID")
+	}
+	if id == "" {
+		if cookie, err := r.Cookie("viewer_id"); err == nil {
+			id = cookie.Value
+		}
+	}
+	if len(id) > maxViewerIDLength {
+		id = id[:maxViewerIDLength]
+	}
+	return id
+}
+
+// viewerID identifies the viewer of an ad for deduplicating tracking events.
+// Explicit ids win; anonymous viewers are fingerprinted by address and user
+// agent.
+func viewerID(r *http.Request) string {
+	id := explicitViewerID(r)
+	if id == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		sum := sha256.Sum256([]byte(host + "|" + r.UserAgent()))
+		id = "anon-" + hex.EncodeToString(sum[:12])
+	}
+	if len(id) > maxViewerIDLength {
+		id = id[:maxViewerIDLength]
+	}
+	return id
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type SavedSearchHandler struct {
+	service service.SavedSearchService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewSavedSearchHandler(service service.SavedSearchService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *SavedSearchHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &SavedSearchHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+// savedSearchRequest carries the search as a GET /ads query string, e.g.
+// "category=cars&attr.price.max=5000&tags=diesel".
+type savedSearchRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+func (h *SavedSearchHandler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListSavedSearches")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/me/saved-searches", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/me/saved-searches", status).Observe(duration)
+	}()
+
+	searches, err := h.service.ListSavedSearches(ctx, userFromContext(ctx))
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retrieve saved searches")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, searches)
+}
+
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler CreateSavedSearch")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/me/saved-searches", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/me/saved-searches", status).Observe(duration)
+	}()
+
+	var searchReq savedSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&searchReq); err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid request payload"))
+		span.RecordError(err)
+		respondProblem(w, r, CodeInvalidPayload, "invalid request payload")
+		return
+	}
+
+	query, err := url.ParseQuery(strings.TrimPrefix(searchReq.Query, "?"))
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid search query"))
+		respondProblem(w, r, CodeValidationFailed, "invalid search query")
+		return
+	}
+
+	filter, err := parseAdFilter(query)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return
+	}
+
+	created, err := h.service.CreateSavedSearch(ctx, &domain.SavedSearch{
+		UserID: userFromContext(ctx),
+		Name:   searchReq.Name,
+		Query:  query.Encode(),
+		Filter: filter,
+	})
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to create saved search")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler DeleteSavedSearch")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("DELETE", "/me/saved-searches/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("DELETE", "/me/saved-searches/{id}", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("saved_search.id", id))
+
+	if err := h.service.DeleteSavedSearch(ctx, userFromContext(ctx), id); err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to delete saved search")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "saved search deleted successfully"})
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"ad-service/internal/domain"
+)
+
+// adSelection is the sparse fieldset and the related resources a client
+// asked for with fields=<field,...> and expand=<name,...>. The id is part
+// of every fieldset.
+type adSelection struct {
+	fields []string
+	expand []string
+}
+
+func parseAdSelection(query url.Values) (adSelection, error) {
+	var selection adSelection
+	if fields := query.Get("fields"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if !slices.Contains(domain.AdFieldNames, field) {
+				return adSelection{}, fmt.Errorf("unknown field %q, fields may name %s", field, strings.Join(domain.AdFieldNames, ", "))
+			}
+			if !slices.Contains(selection.fields, field) {
+				selection.fields = append(selection.fields, field)
+			}
+		}
+	}
+	if expand := query.Get("expand"); expand != "" {
+		for _, name := range strings.Split(expand, ",") {
+			name = strings.TrimSpace(name)
+			if !slices.Contains(domain.AdExpansions, name) {
+				return adSelection{}, fmt.Errorf("cannot expand %q, expand may name %s", name, strings.Join(domain.AdExpansions, ", "))
+			}
+			if !slices.Contains(selection.expand, name) {
+				selection.expand = append(selection.expand, name)
+			}
+		}
+	}
+	return selection, nil
+}
+
+func (s adSelection) isEmpty() bool {
+	return len(s.fields) == 0 && len(s.expand) == 0
+}
+
+// load returns the fields to load for the selection: the selected ones and
+// those the expansions are looked up by. Nil loads every field.
+func (s adSelection) load() []string {
+	if len(s.fields) == 0 {
+		return nil
+	}
+	fields := slices.Clone(s.fields)
+	for _, name := range s.expand {
+		field := name
+		if name == domain.AdExpandCampaign {
+			field = "campaign_id"
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (s adSelection) selects(field string) bool {
+	return len(s.fields) == 0 || field == "id" || slices.Contains(s.fields, field)
+}
+
+// codec wraps codec to encode the selected fields of ads with the related
+// resources of relations embedded. CSV rows keep the selected columns but
+// cannot embed anything.
+func (s adSelection) codec(codec adCodec, relations *domain.AdRelations) adCodec {
+	if s.isEmpty() {
+		return codec
+	}
+	selected := &selectedAdCodec{adCodec: codec, selection: s, relations: relations}
+	for i, column := range codec.csvHeader() {
+		field := column
+		if strings.HasPrefix(column, "price_") {
+			field = "price"
+		}
+		if s.selects(field) {
+			selected.columns = append(selected.columns, i)
+		}
+	}
+	return selected
+}
+
+type selectedAdCodec struct {
+	adCodec
+	selection adSelection
+	relations *domain.AdRelations
+	// columns are the indexes of the selected CSV columns.
+	columns []int
+}
+
+// adExpansion holds the related resources embedded in an ad.
+type adExpansion struct {
+	Campaign *domain.Campaign  `json:"campaign,omitempty"`
+	Category *categoryEmbedded `json:"category,omitempty"`
+}
+
+type categoryEmbedded struct {
+	Slug       string                        `json:"slug"`
+	Attributes []*domain.AttributeDefinition `json:"attributes"`
+}
+
+// encodeAd keeps the members of the full encoding that are selected, in
+// their usual order, followed by the expanded resources. Should the ad
+// fail to encode, the full encoding is returned so that the error surfaces
+// where it is written.
+func (c *selectedAdCodec) encodeAd(ad *domain.Ad) interface{} {
+	full := c.adCodec.encodeAd(ad)
+	b, err := json.Marshal(full)
+	if err != nil {
+		return full
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return full
+	}
+
+	var out bytes.Buffer
+	out.WriteByte('{')
+	member := func(key string, value []byte) {
+		if out.Len() > 1 {
+			out.WriteByte(',')
+		}
+		fmt.Fprintf(&out, "%q:", key)
+		out.Write(value)
+	}
+	for _, field := range domain.AdFieldNames {
+		if value, ok := members[field]; ok && c.selection.selects(field) {
+			member(field, value)
+		}
+	}
+	if len(c.selection.expand) > 0 {
+		expanded, err := json.Marshal(c.expansion(ad))
+		if err != nil {
+			return full
+		}
+		member("expanded", expanded)
+	}
+	out.WriteByte('}')
+	return json.RawMessage(out.Bytes())
+}
+
+func (c *selectedAdCodec) expansion(ad *domain.Ad) adExpansion {
+	var expansion adExpansion
+	if c.relations == nil {
+		return expansion
+	}
+	if ad.CampaignID != nil {
+		expansion.Campaign = c.relations.Campaigns[*ad.CampaignID]
+	}
+	if defs, ok := c.relations.Categories[ad.Category]; ok {
+		if defs == nil {
+			defs = []*domain.AttributeDefinition{}
+		}
+		expansion.Category = &categoryEmbedded{Slug: ad.Category, Attributes: defs}
+	}
+	return expansion
+}
+
+func (c *selectedAdCodec) csvHeader() []string {
+	return c.project(c.adCodec.csvHeader())
+}
+
+func (c *selectedAdCodec) csvRecord(ad *domain.Ad) []string {
+	return c.project(c.adCodec.csvRecord(ad))
+}
+
+func (c *selectedAdCodec) project(row []string) []string {
+	projected := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		projected[i] = row[column]
+	}
+	return projected
+}
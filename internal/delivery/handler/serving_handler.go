@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var adSnippet = template.Must(template.New("ad").Parse(
+	`<div class="ad" data-ad-id="{{.ID}}" data-slot="{{.Slot}}">` +
+		`<a href="{{.ClickURL}}" rel="nofollow sponsored" target="_blank">` +
+		`<span class="ad-title">{{.Title}}</span>` +
+		`<span class="ad-description">{{.Description}}</span>` +
+		`</a>` +
+		`<img src="{{.ImpressionURL}}" width="1" height="1" alt="" style="display:none">` +
+		`</div>`))
+
+type servedAd struct {
+	ID            int64   `json:"id"`
+	Slot          string  `json:"-"`
+	Title         string  `json:"title"`
+	Description   string  `json:"description"`
+	Price         float64 `json:"price"`
+	ImpressionURL string  `json:"impression_url"`
+	ClickURL      string  `json:"click_url"`
+	HTML          string  `json:"html"`
+}
+
+type serveResponse struct {
+	Slot     string      `json:"slot"`
+	ViewerID string      `json:"viewer_id,omitempty"`
+	Ads      []*servedAd `json:"ads"`
+}
+
+type ServingHandler struct {
+	service service.ServingService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewServingHandler(service service.ServingService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *ServingHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &ServingHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *ServingHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler Serve")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/serve", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/serve", status).Observe(duration)
+	}()
+
+	query := r.URL.Query()
+
+	slot := query.Get("slot")
+	count, err := strconv.Atoi(query.Get("count"))
+	if err != nil || count <= 0 {
+		count = 1 // Default count
+	}
+
+	var exclude []int64
+	if raw := query.Get("exclude"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				status = "error"
+				span.SetAttributes(attribute.String("error", "invalid exclude parameter"))
+				respondProblem(w, r, CodeValidationFailed, "invalid exclude parameter")
+				return
+			}
+			exclude = append(exclude, id)
+		}
+	}
+
+	filter, err := parseAdFilter(query)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return
+	}
+	filter.Audience = audienceFromRequest(r)
+
+	// Fingerprints are shared by everyone behind the same address and
+	// browser, so only an explicit id is capped and counted.
+	viewer := explicitViewerID(r)
+
+	span.SetAttributes(
+		attribute.String("serve.slot", slot),
+		attribute.Int("serve.count", count),
+	)
+
+	selectionStart := time.Now()
+	ads, err := h.service.Serve(ctx, service.ServeRequest{
+		Slot:     slot,
+		ViewerID: viewer,
+		Count:    count,
+		Exclude:  exclude,
+		Filter:   filter,
+	})
+	selectionStatus := "success"
+	if err != nil {
+		selectionStatus = "error"
+	}
+	h.metrics.SelectionDuration.WithLabelValues(selectionStatus).Observe(time.Since(selectionStart).Seconds())
+
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to serve ads")
+		return
+	}
+
+	response := serveResponse{Slot: slot, ViewerID: viewer, Ads: make([]*servedAd, 0, len(ads))}
+	for _, ad := range ads {
+		served, err := newServedAd(ad, slot, viewer)
+		if err != nil {
+			status = respondError(w, r, h.logger, span, err, "failed to render ad")
+			return
+		}
+		response.Ads = append(response.Ads, served)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func newServedAd(ad *domain.Ad, slot, viewer string) (*servedAd, error) {
+	params := url.Values{}
+	if viewer != "" {
+		params.Set("viewer", viewer)
+	}
+	params.Set("slot", slot)
+
+	served := &servedAd{
+		ID:            ad.ID,
+		Slot:          slot,
+		Title:         ad.Title,
+		Description:   ad.Description,
+		Price:         ad.Price,
+		ImpressionURL: fmt.Sprintf("/ads/%d/pixel.gif?%s", ad.ID, params.Encode()),
+		ClickURL:      fmt.Sprintf("/ads/%d/click?%s", ad.ID, params.Encode()),
+	}
+
+	var buf bytes.Buffer
+	if err := adSnippet.Execute(&buf, served); err != nil {
+		return nil, err
+	}
+	served.HTML = buf.String()
+	return served, nil
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/cache"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"ad-service/internal/service"
+)
+
+var testServiceMetrics = metrics.NewServiceMetrics()
+
+type fakeServableAdRepository struct {
+	repository.AdRepository
+	ads []*domain.Ad
+}
+
+func (r *fakeServableAdRepository) GetServableAds(ctx context.Context, limit int) ([]*domain.Ad, error) {
+	return r.ads, nil
+}
+
+type fakeCampaignRepository struct {
+	repository.CampaignRepository
+}
+
+func (fakeCampaignRepository) GetCampaignsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Campaign, error) {
+	return map[int64]*domain.Campaign{}, nil
+}
+
+type fakeCache struct {
+	cache.Cache
+	increments atomic.Int32
+}
+
+func (c *fakeCache) GetMany(ctx context.Context, keys []string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (c *fakeCache) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	c.increments.Add(1)
+	return 1, nil
+}
+
+func TestServeCountsOnlyKnownViewers(t *testing.T) {
+	tests := []struct {
+		name       string
+		viewer     string
+		increments int32
+	}{
+		{name: "anonymous", increments: 0},
+		{name: "known viewer", viewer: "viewer", increments: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeCache{}
+			ads := &fakeServableAdRepository{ads: []*domain.Ad{{ID: 1, Title: "Bike", Weight: 1}}}
+			svc := service.NewServingService(ads, fakeCampaignRepository{}, c, testServiceMetrics, service.ServingOptions{})
+			h := NewServingHandler(svc, testLoggers(), testMetrics)
+
+			req := httptest.NewRequest(http.MethodGet, "/serve?slot=top", nil)
+			req.Header.Set("User-Agent", "test")
+			if tt.viewer != "" {
+				req.Header.Set("X-Viewer-ID", tt.viewer)
+			}
+			rec := httptest.NewRecorder()
+			h.Serve(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+			}
+			if got := c.increments.Load(); got != tt.increments {
+				t.Errorf("counted %d impressions, want %d", got, tt.increments)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	streamHeartbeat  = 15 * time.Second
+	streamRetryDelay = 3000 // milliseconds
+)
+
+type StreamHandler struct {
+	service service.StreamService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewStreamHandler(service service.StreamService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *StreamHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &StreamHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+// Stream serves ad changes as Server-Sent Events. Clients resume with the
+// Last-Event-ID header, or last_event_id for the first connection; a
+// "reset" event tells them that events were missed and state must be
+// reloaded.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler Stream")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ads/stream", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/stream", status).Observe(duration)
+	}()
+
+	filter, err := parseStreamFilter(r.URL.Query())
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return
+	}
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		respondProblem(w, r, CodeValidationFailed, err.Error())
+		return
+	}
+
+	sub, err := h.service.Subscribe(ctx, lastEventID, filter)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to subscribe to event stream")
+		return
+	}
+	defer sub.Close()
+
+	// Streams outlive the server write timeout.
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryDelay)
+	if sub.Gap {
+		fmt.Fprintf(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range sub.Replay {
+		if err := writeStreamEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Too slow or shutting down; the client reconnects and resumes.
+				return
+			}
+			if err := writeStreamEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event *domain.AdEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+func parseLastEventID(r *http.Request) (int64, error) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New("invalid Last-Event-ID")
+	}
+	return id, nil
+}
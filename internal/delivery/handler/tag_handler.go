@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TagHandler struct {
+	service service.TagService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewTagHandler(service service.TagService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *TagHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &TagHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListTags")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/tags", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/tags", status).Observe(duration)
+	}()
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100 // Default limit
+	}
+
+	span.SetAttributes(attribute.Int("tags.limit", limit))
+
+	tags, err := h.service.ListTags(ctx, limit)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retrieve tags")
+		return
+	}
+
+	if tags == nil {
+		tags = []*domain.TagCount{}
+	}
+	utils.RespondWithJSON(w, http.StatusOK, tags)
+}
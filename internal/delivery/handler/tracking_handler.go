@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// transparentGIF is a 1x1 transparent GIF served by the tracking pixel.
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+const maxStatsDays = 365
+
+type TrackingHandler struct {
+	service   service.TrackingService
+	adService service.AdService
+	logger    *logger.Loggers
+	metrics   *metrics.HandlerMetrics
+	tracer    trace.Tracer
+}
+
+func NewTrackingHandler(service service.TrackingService, adService service.AdService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *TrackingHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &TrackingHandler{
+		service:   service,
+		adService: adService,
+		logger:    logger,
+		metrics:   metrics,
+		tracer:    tracer,
+	}
+}
+
+func (h *TrackingHandler) RecordImpression(w http.ResponseWriter, r *http.Request) {
+	h.record(w, r, domain.TrackingImpression, "/ads/{id}/impressions")
+}
+
+func (h *TrackingHandler) RecordClick(w http.ResponseWriter, r *http.Request) {
+	h.record(w, r, domain.TrackingClick, "/ads/{id}/clicks")
+}
+
+func (h *TrackingHandler) record(w http.ResponseWriter, r *http.Request, eventType domain.TrackingEventType, endpoint string) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler Record "+string(eventType))
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", endpoint, status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", endpoint, status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", id))
+
+	recorded, err := h.service.Track(ctx, &domain.TrackingEvent{AdID: id, Type: eventType, ViewerID: viewerID(r)})
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to process tracking request")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]bool{"recorded": recorded})
+}
+
+func (h *TrackingHandler) Pixel(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler Pixel")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ads/{id}/pixel.gif", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/{id}/pixel.gif", status).Observe(duration)
+	}()
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate")
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid id parameter"))
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(transparentGIF)
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", id))
+
+	if _, err := h.service.Track(ctx, &domain.TrackingEvent{AdID: id, Type: domain.TrackingImpression, ViewerID: viewerID(r)}); err != nil {
+		status = trackingErrorStatus(err)
+		if status == "error" {
+			h.logger.ErrorLogger.Error("failed to record pixel impression", utils.Err(err))
+			span.RecordError(err)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(transparentGIF)
+}
+
+func (h *TrackingHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler Redirect")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ads/{id}/click", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/{id}/click", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", id))
+
+	ad, err := h.adService.GetAdByID(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to process tracking request")
+		return
+	}
+	if ad.TargetURL == "" {
+		status = "not_found"
+		respondProblem(w, r, CodeTargetURLNotFound, "ad has no target url")
+		return
+	}
+
+	if _, err := h.service.Track(ctx, &domain.TrackingEvent{AdID: id, Type: domain.TrackingClick, ViewerID: viewerID(r)}); err != nil {
+		// The viewer still gets redirected, a lost click must not break the ad.
+		h.logger.ErrorLogger.Error("failed to record click", utils.Err(err))
+		span.RecordError(err)
+	}
+
+	http.Redirect(w, r, ad.TargetURL, http.StatusFound)
+}
+
+func (h *TrackingHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetStats")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ads/{id}/stats", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/{id}/stats", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 || days > maxStatsDays {
+		days = 30 // Default window
+	}
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", id),
+		attribute.Int("stats.days", days),
+	)
+
+	stats, err := h.service.GetStats(ctx, id, days)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to process tracking request")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, stats)
+}
+
+func trackingErrorStatus(err error) string {
+	if errors.Is(err, service.ErrAdNotFound) {
+		return "not_found"
+	}
+	return "error"
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type userContextKey struct{}
+
+const maxUserIDLength = 64
+
+// RequireUser identifies the caller from the X-User-ID header set by the
+// gateway in front of the service and rejects anonymous requests.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.TrimSpace(r.Header.Get("X-User-ID"))
+		if userID == "" || len(userID) > maxUserIDLength {
+			respondProblem(w, r, CodeUnauthenticated, "")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey{}, userID)))
+	})
+}
+
+func userFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userContextKey{}).(string)
+	return userID
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type VariantHandler struct {
+	service service.VariantService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewVariantHandler(service service.VariantService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *VariantHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &VariantHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *VariantHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListVariants")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ads/{id}/variants", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/{id}/variants", status).Observe(duration)
+	}()
+
+	adID, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	variants, err := h.service.ListVariants(ctx, adID)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to list variants")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, variants)
+}
+
+func (h *VariantHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler CreateVariant")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/ads/{id}/variants", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/ads/{id}/variants", status).Observe(duration)
+	}()
+
+	adID, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	var variantReq domain.Variant
+	if err := json.NewDecoder(r.Body).Decode(&variantReq); err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid request payload"))
+		span.RecordError(err)
+		respondProblem(w, r, CodeInvalidPayload, "invalid request payload")
+		return
+	}
+	variantReq.AdID = adID
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.String("variant.name", variantReq.Name),
+	)
+
+	created, err := h.service.CreateVariant(ctx, &variantReq)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to create variant")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *VariantHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler DeleteVariant")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("DELETE", "/ads/{id}/variants/{variantId}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("DELETE", "/ads/{id}/variants/{variantId}", status).Observe(duration)
+	}()
+
+	adID, variantID, err := parseVariantParams(r)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.Int64("variant.id", variantID),
+	)
+
+	if err := h.service.DeleteVariant(ctx, adID, variantID); err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to delete variant")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "variant deleted successfully"})
+}
+
+func (h *VariantHandler) AssignVariant(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler AssignVariant")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ads/{id}/variants/assignment", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/{id}/variants/assignment", status).Observe(duration)
+	}()
+
+	adID, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	viewer := viewerID(r)
+	variant, err := h.service.AssignVariant(ctx, adID, viewer)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to assign variant")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"viewer_id": viewer,
+		"variant":   variant,
+	})
+}
+
+func (h *VariantHandler) RecordImpression(w http.ResponseWriter, r *http.Request) {
+	h.record(w, r, domain.TrackingImpression, "/ads/{id}/variants/{variantId}/impressions")
+}
+
+func (h *VariantHandler) RecordClick(w http.ResponseWriter, r *http.Request) {
+	h.record(w, r, domain.TrackingClick, "/ads/{id}/variants/{variantId}/clicks")
+}
+
+func (h *VariantHandler) record(w http.ResponseWriter, r *http.Request, eventType domain.TrackingEventType, endpoint string) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler RecordVariant "+string(eventType))
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", endpoint, status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", endpoint, status).Observe(duration)
+	}()
+
+	adID, variantID, err := parseVariantParams(r)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.Int64("variant.id", variantID),
+	)
+
+	if err := h.service.RecordEvent(ctx, adID, variantID, eventType); err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to record variant event")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]bool{"recorded": true})
+}
+
+func (h *VariantHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetVariantResults")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ads/{id}/variants/results", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ads/{id}/variants/results", status).Observe(duration)
+	}()
+
+	adID, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	results, err := h.service.GetResults(ctx, adID)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to compute variant results")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, results)
+}
+
+func parseVariantParams(r *http.Request) (int64, int64, error) {
+	adID, err := parseIDParam(r, "id")
+	if err != nil {
+		return 0, 0, err
+	}
+	variantID, err := parseIDParam(r, "variantId")
+	if err != nil {
+		return 0, 0, err
+	}
+	return adID, variantID, nil
+}
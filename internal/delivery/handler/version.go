@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ad-service/pkg/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type APIVersion int
+
+const (
+	APIv1 APIVersion = 1
+	APIv2 APIVersion = 2
+
+	defaultAPIVersion = APIv1
+	latestAPIVersion  = APIv2
+)
+
+const vendorMediaTypePrefix = "application/vnd.ad-service.v"
+
+func (v APIVersion) Prefix() string {
+	return "/v" + strconv.Itoa(int(v))
+}
+
+func (v APIVersion) IsValid() bool {
+	return v >= APIv1 && v <= latestAPIVersion
+}
+
+// VersionOptions configures the versioned ad routes.
+type VersionOptions struct {
+	Currency     string
+	Deprecations map[APIVersion]Deprecation
+}
+
+// Deprecation announces the retirement of an API version. Without a Since
+// date the version is reported as deprecated with the value true; without a
+// Sunset date no Sunset header is sent.
+type Deprecation struct {
+	Since     time.Time
+	Sunset    time.Time
+	Successor APIVersion
+}
+
+// Deprecated marks every response of a versioned router with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers and links the same
+// resource in the successor version.
+func Deprecated(version APIVersion, deprecation Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			if deprecation.Since.IsZero() {
+				header.Set("Deprecation", "true")
+			} else {
+				header.Set("Deprecation", fmt.Sprintf("@%d", deprecation.Since.Unix()))
+			}
+			if !deprecation.Sunset.IsZero() {
+				header.Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if deprecation.Successor.IsValid() {
+				successor := deprecation.Successor.Prefix() + strings.TrimPrefix(r.URL.Path, version.Prefix())
+				header.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NegotiateVersion serves requests without a version prefix from the
+// versioned routes. The version comes from the Accept header, either as
+// application/vnd.ad-service.v2+json or as a version parameter such as
+// application/json; version=2, and defaults to v1 so that clients written
+// before versioning keep working. Paths that routes serves without a prefix
+// are left alone.
+func NegotiateVersion(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if routes.Match(chi.NewRouteContext(), r.Method, path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			version, err := versionFromAccept(r.Header.Get("Accept"))
+			if err != nil {
+				respondProblem(w, r, CodeUnsupportedVersion, err.Error())
+				return
+			}
+			if !routes.Match(chi.NewRouteContext(), r.Method, version.Prefix()+path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			utils.AddVary(w.Header(), "Accept")
+			r.URL.Path = version.Prefix() + path
+			r.URL.RawPath = ""
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// versionFromAccept returns the first version named in an Accept header.
+// Any media type may carry a version parameter, so that text/csv;
+// version=2 selects v2 columns.
+func versionFromAccept(accept string) (APIVersion, error) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		var name string
+		switch {
+		case strings.HasPrefix(mediaType, vendorMediaTypePrefix) && strings.HasSuffix(mediaType, "+json"):
+			name = strings.TrimSuffix(strings.TrimPrefix(mediaType, vendorMediaTypePrefix), "+json")
+		case params["version"] != "":
+			name = params["version"]
+		default:
+			continue
+		}
+
+		n, err := strconv.Atoi(name)
+		if err != nil || !APIVersion(n).IsValid() {
+			return 0, fmt.Errorf("unsupported API version %q", name)
+		}
+		return APIVersion(n), nil
+	}
+	return defaultAPIVersion, nil
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+	logger  *logger.Loggers
+	metrics *metrics.HandlerMetrics
+	tracer  trace.Tracer
+}
+
+func NewWebhookHandler(service service.WebhookService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *WebhookHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListSubscriptions")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/webhooks", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/webhooks", status).Observe(duration)
+	}()
+
+	subscriptions, err := h.service.ListSubscriptions(ctx)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retrieve webhook subscriptions")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler GetSubscription")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/webhooks/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/webhooks/{id}", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("webhook.id", id))
+
+	subscription, err := h.service.GetSubscription(ctx, id)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to get webhook subscription")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler CreateSubscription")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/webhooks", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/webhooks", status).Observe(duration)
+	}()
+
+	var subscriptionReq domain.WebhookSubscription
+	if err := json.NewDecoder(r.Body).Decode(&subscriptionReq); err != nil {
+		status = "error"
+		span.SetAttributes(attribute.String("error", "invalid request payload"))
+		span.RecordError(err)
+		respondProblem(w, r, CodeInvalidPayload, "invalid request payload")
+		return
+	}
+
+	created, err := h.service.CreateSubscription(ctx, &subscriptionReq)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to create webhook subscription")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler DeleteSubscription")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("DELETE", "/webhooks/{id}", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("DELETE", "/webhooks/{id}", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("webhook.id", id))
+
+	if err := h.service.DeleteSubscription(ctx, id); err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to delete webhook subscription")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "webhook subscription deleted successfully"})
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListDeliveries")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/webhooks/{id}/deliveries", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/webhooks/{id}/deliveries", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("webhook.id", id))
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	deliveries, err := h.service.ListDeliveries(ctx, id, domain.DeliveryStatus(query.Get("status")), limit)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retrieve webhook deliveries")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler ListDeadLetters")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/webhooks/dead-letters", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/webhooks/dead-letters", status).Observe(duration)
+	}()
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	deliveries, err := h.service.ListDeliveries(ctx, 0, domain.DeliveryDead, limit)
+	if err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to retrieve dead letters")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler Redeliver")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("POST", "/webhooks/deliveries/{deliveryId}/redeliver", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("POST", "/webhooks/deliveries/{deliveryId}/redeliver", status).Observe(duration)
+	}()
+
+	id, err := parseIDParam(r, "deliveryId")
+	if err != nil {
+		status = respondError(w, r, h.logger, span, service.ErrInvalidID, "")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("delivery.id", id))
+
+	if err := h.service.Redeliver(ctx, id); err != nil {
+		status = respondError(w, r, h.logger, span, err, "failed to redeliver webhook")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "delivery queued"})
+}
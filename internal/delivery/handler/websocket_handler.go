@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	wsWriteWait          = 10 * time.Second
+	wsPongWait           = 60 * time.Second
+	wsPingPeriod         = wsPongWait * 9 / 10
+	wsMaxMessageSize     = 4096
+	wsSendBuffer         = 64
+	wsMaxSubscriptions   = 20
+	wsMaxSubscriptionID  = 64
+	wsMessageSubscribe   = "subscribe"
+	wsMessageUnsubscribe = "unsubscribe"
+)
+
+// wsClientMessage is sent by clients to manage subscriptions. Query takes
+// the GET /ads listing filters in query string form; AdIDs, Events and
+// LastEventID mirror the ad_id, events and Last-Event-ID options of
+// /ads/stream.
+type wsClientMessage struct {
+	Type        string               `json:"type"`
+	ID          string               `json:"id"`
+	AdIDs       []int64              `json:"ad_ids,omitempty"`
+	Events      []domain.AdEventType `json:"events,omitempty"`
+	Query       string               `json:"query,omitempty"`
+	LastEventID int64                `json:"last_event_id,omitempty"`
+}
+
+// wsServerMessage is sent to clients. Type is one of subscribed,
+// unsubscribed, event, reset, closed or error; ID names the subscription it
+// belongs to.
+type wsServerMessage struct {
+	Type  string          `json:"type"`
+	ID    string          `json:"id,omitempty"`
+	Event *domain.AdEvent `json:"event,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+type WebSocketHandler struct {
+	service  service.StreamService
+	logger   *logger.Loggers
+	metrics  *metrics.HandlerMetrics
+	tracer   trace.Tracer
+	upgrader websocket.Upgrader
+}
+
+func NewWebSocketHandler(service service.StreamService, logger *logger.Loggers, metrics *metrics.HandlerMetrics) *WebSocketHandler {
+	tracer := otel.Tracer("ad-service/handler")
+	return &WebSocketHandler{
+		service: service,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+		upgrader: websocket.Upgrader{
+			HandshakeTimeout: wsWriteWait,
+		},
+	}
+}
+
+// Serve upgrades the request to a WebSocket on which the client subscribes
+// to ad events by ad id or listing filter. Every subscription forwards its
+// events into a bounded per-connection queue; a client that stops reading
+// stalls its subscriptions until the stream drops them and sends "closed",
+// after which the client may resubscribe with last_event_id.
+func (h *WebSocketHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "Handler WebSocket")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		h.metrics.RequestCount.WithLabelValues("GET", "/ws", status).Inc()
+		h.metrics.RequestDuration.WithLabelValues("GET", "/ws", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.String("user.id", userFromContext(ctx)))
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied with an HTTP error.
+		status = "error"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return
+	}
+
+	c := &wsConn{
+		conn: conn,
+		send: make(chan wsServerMessage, wsSendBuffer),
+		done: make(chan struct{}),
+		subs: make(map[string]*service.StreamSubscription),
+	}
+	defer c.close()
+
+	go c.writePump()
+	h.readPump(ctx, c)
+}
+
+func (h *WebSocketHandler) readPump(ctx context.Context, c *wsConn) {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg wsClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.enqueue(wsServerMessage{Type: "error", Error: "invalid message"})
+			continue
+		}
+
+		switch msg.Type {
+		case wsMessageSubscribe:
+			h.subscribe(ctx, c, msg)
+		case wsMessageUnsubscribe:
+			if !c.unsubscribe(msg.ID) {
+				c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "unknown subscription"})
+				continue
+			}
+			c.enqueue(wsServerMessage{Type: "unsubscribed", ID: msg.ID})
+		default:
+			c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "unknown message type"})
+		}
+	}
+}
+
+func (h *WebSocketHandler) subscribe(ctx context.Context, c *wsConn, msg wsClientMessage) {
+	ctx, span := h.tracer.Start(ctx, "Handler WebSocketSubscribe")
+	defer span.End()
+
+	if msg.ID == "" || len(msg.ID) > wsMaxSubscriptionID {
+		c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "subscription id is required"})
+		return
+	}
+	if c.has(msg.ID) {
+		c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "subscription id already in use"})
+		return
+	}
+	if c.count() >= wsMaxSubscriptions {
+		c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "too many subscriptions"})
+		return
+	}
+
+	query, err := url.ParseQuery(msg.Query)
+	if err != nil {
+		c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "invalid query"})
+		return
+	}
+	filter, err := parseStreamFilter(query)
+	if err != nil {
+		c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: err.Error()})
+		return
+	}
+	filter.AdIDs = append(filter.AdIDs, msg.AdIDs...)
+	filter.Events = append(filter.Events, msg.Events...)
+
+	span.SetAttributes(attribute.String("subscription.id", msg.ID))
+
+	sub, err := h.service.Subscribe(ctx, msg.LastEventID, filter)
+	if err != nil {
+		var validationErr *service.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: validationErr.Error()})
+		case errors.Is(err, service.ErrStreamClosed):
+			c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "event stream unavailable"})
+		default:
+			h.logger.ErrorLogger.Error("failed to subscribe to event stream", utils.Err(err))
+			span.RecordError(err)
+			c.enqueue(wsServerMessage{Type: "error", ID: msg.ID, Error: "internal server error"})
+		}
+		return
+	}
+
+	if !c.add(msg.ID, sub) {
+		sub.Close()
+		return
+	}
+	c.enqueue(wsServerMessage{Type: "subscribed", ID: msg.ID})
+	go c.forward(msg.ID, sub)
+}
+
+// wsConn serializes all writes to a connection through the send queue
+// drained by writePump and tracks the connection's subscriptions.
+type wsConn struct {
+	conn      *websocket.Conn
+	send      chan wsServerMessage
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu     sync.Mutex
+	subs   map[string]*service.StreamSubscription
+	closed bool
+}
+
+// enqueue blocks while the send queue is full, so a slow client pushes back
+// on its subscriptions instead of growing memory.
+func (c *wsConn) enqueue(msg wsServerMessage) bool {
+	select {
+	case c.send <- msg:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+func (c *wsConn) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *wsConn) forward(id string, sub *service.StreamSubscription) {
+	if sub.Gap && !c.enqueue(wsServerMessage{Type: "reset", ID: id}) {
+		return
+	}
+	for _, event := range sub.Replay {
+		if !c.enqueue(wsServerMessage{Type: "event", ID: id, Event: event}) {
+			return
+		}
+	}
+	for event := range sub.Events {
+		if !c.enqueue(wsServerMessage{Type: "event", ID: id, Event: event}) {
+			return
+		}
+	}
+
+	// The stream dropped the subscription because it fell behind or is
+	// shutting down, unless the client unsubscribed.
+	if c.remove(id, sub) {
+		c.enqueue(wsServerMessage{Type: "closed", ID: id, Error: "subscription closed, resubscribe with last_event_id"})
+	}
+}
+
+func (c *wsConn) has(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.subs[id]
+	return ok
+}
+
+func (c *wsConn) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.subs)
+}
+
+func (c *wsConn) add(id string, sub *service.StreamSubscription) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	c.subs[id] = sub
+	return true
+}
+
+func (c *wsConn) remove(id string, sub *service.StreamSubscription) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subs[id] != sub {
+		return false
+	}
+	delete(c.subs, id)
+	return true
+}
+
+func (c *wsConn) unsubscribe(id string) bool {
+	c.mu.Lock()
+	sub, ok := c.subs[id]
+	delete(c.subs, id)
+	c.mu.Unlock()
+
+	if ok {
+		sub.Close()
+	}
+	return ok
+}
+
+func (c *wsConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		c.mu.Lock()
+		c.closed = true
+		subs := c.subs
+		c.subs = make(map[string]*service.StreamSubscription)
+		c.mu.Unlock()
+
+		for _, sub := range subs {
+			sub.Close()
+		}
+		c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(wsWriteWait))
+		c.conn.Close()
+	})
+}
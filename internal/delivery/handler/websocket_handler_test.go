@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ad-service/api/openapi"
+	"ad-service/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+)
+
+// TestWebSocketThroughMiddleware upgrades /ws behind the middleware the
+// server runs, each of which wraps the response writer.
+func TestWebSocketThroughMiddleware(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := NewOpenAPIValidator(doc, testLoggers(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Use(RequestID)
+	r.Use(Compress(CompressOptions{MinSize: 1, ContentTypes: []string{"application/json"}}))
+	r.Use(CacheControl(map[string]string{"/ws": "no-store", "/v1/ads/{id}": "public, max-age=60"}))
+	r.Use(NegotiateVersion(r))
+	r.Use(validator.Middleware)
+	var streams service.StreamService
+	r.With(RequireUser).Get("/ws", NewWebSocketHandler(streams, testLoggers(), testMetrics).Serve)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("X-User-ID", "user-1")
+	header.Set("Accept-Encoding", "gzip")
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("Dial() error = %v, status %d", err, status)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(wsClientMessage{Type: wsMessageUnsubscribe, ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	var msg wsServerMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != "error" || msg.ID != "a" {
+		t.Errorf("reply = %+v, want an error for subscription a", msg)
+	}
+}
@@ -1,20 +1,218 @@
 package router
 
 import (
+	"net/http"
+
+	"ad-service/api/openapi"
+	"ad-service/internal/delivery/gql"
 	"ad-service/internal/delivery/handler"
 	"ad-service/internal/infrastructure/metrics"
 	"ad-service/internal/service"
 	"ad-service/pkg/logger"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 )
 
-func SetupAdRoutes(adRouter *chi.Mux, adService service.AdService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
-	adHandler := handler.NewAdHandler(adService, loggers, metrics)
+// SetupAdRoutes mounts the ad routes of every API version under its prefix.
+// Requests without a prefix are routed by SetupVersioning.
+func SetupAdRoutes(adRouter *chi.Mux, adService service.AdService, importService service.ImportService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics, options handler.VersionOptions) {
+	for _, version := range []handler.APIVersion{handler.APIv1, handler.APIv2} {
+		adHandler := handler.NewAdHandler(adService, importService, version, options.Currency, loggers, metrics)
+		deprecation, deprecated := options.Deprecations[version]
+
+		adRouter.Route(version.Prefix(), func(r chi.Router) {
+			if deprecated {
+				r.Use(handler.Deprecated(version, deprecation))
+			}
+
+			r.Get("/ads", adHandler.GetAllAds)
+			r.Get("/ads/export", adHandler.ExportAds)
+			r.Post("/ads/import", adHandler.ImportAds)
+			r.Get("/ads/{id}", adHandler.GetAdByID)
+			r.Post("/ads", adHandler.CreateAd)
+			r.Put("/ads/{id}", adHandler.UpdateAd)
+			r.Delete("/ads/{id}", adHandler.DeleteAd)
+		})
+	}
+}
+
+// SetupCompression compresses responses. It must run before the other
+// middlewares so that they see the uncompressed response.
+func SetupCompression(r *chi.Mux, options handler.CompressOptions) {
+	r.Use(handler.Compress(options))
+}
+
+// SetupCaching sets Cache-Control on the routes that have a policy, keyed
+// by route pattern.
+func SetupCaching(r *chi.Mux, policies map[string]string) {
+	r.Use(handler.CacheControl(policies))
+}
+
+// SetupVersioning serves requests without a version prefix from the
+// version negotiated through the Accept header. It must run before
+// SetupValidation so that requests are validated against the version that
+// serves them.
+func SetupVersioning(r *chi.Mux) {
+	r.Use(handler.NegotiateVersion(r))
+}
+
+// SetupValidation checks requests against the OpenAPI document. It must run
+// before any route is added to the router.
+func SetupValidation(r *chi.Mux, doc *openapi3.T, loggers *logger.Loggers, validateResponses bool) error {
+	validator, err := handler.NewOpenAPIValidator(doc, loggers, validateResponses)
+	if err != nil {
+		return err
+	}
+
+	r.Use(validator.Middleware)
+	return nil
+}
+
+// SetupDocsRoutes serves the OpenAPI document of the ad routes at
+// /openapi.json and its UI at /docs/. It fails when the document and
+// SetupAdRoutes disagree so that a stale spec stops the service from
+// starting instead of misleading clients.
+func SetupDocsRoutes(docsRouter *chi.Mux, doc *openapi3.T, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) error {
+	adRoutes := chi.NewRouter()
+	SetupAdRoutes(adRoutes, nil, nil, loggers, metrics, handler.VersionOptions{})
+	if err := openapi.CheckRoutes(doc, adRoutes); err != nil {
+		return err
+	}
+
+	docsHandler, err := handler.NewDocsHandler(doc)
+	if err != nil {
+		return err
+	}
+
+	docsRouter.Get("/openapi.json", docsHandler.Spec)
+	docsRouter.Get("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently).ServeHTTP)
+	docsRouter.Get("/docs/", docsHandler.Page)
+	docsRouter.Get("/docs/*", docsHandler.Assets)
+	return nil
+}
+
+func SetupImportRoutes(importRouter *chi.Mux, importService service.ImportService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	importHandler := handler.NewImportHandler(importService, loggers, metrics)
+
+	importRouter.Get("/imports/{id}", importHandler.GetImport)
+	importRouter.Get("/imports/{id}/errors", importHandler.GetImportErrors)
+}
+
+func SetupJobRoutes(jobRouter *chi.Mux, jobService service.JobService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	jobHandler := handler.NewJobHandler(jobService, loggers, metrics)
+
+	jobRouter.Get("/jobs", jobHandler.ListJobs)
+	jobRouter.Get("/jobs/{id}", jobHandler.GetJob)
+	jobRouter.Get("/jobs/{id}/progress", jobHandler.GetJobProgress)
+	jobRouter.Post("/jobs/{id}/cancel", jobHandler.CancelJob)
+	jobRouter.Post("/jobs/{id}/retry", jobHandler.RetryJob)
+}
+
+func SetupAttributeRoutes(attributeRouter *chi.Mux, attributeService service.AttributeService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	attributeHandler := handler.NewAttributeHandler(attributeService, loggers, metrics)
+
+	attributeRouter.Get("/categories/{category}/attributes", attributeHandler.ListDefinitions)
+	attributeRouter.Post("/categories/{category}/attributes", attributeHandler.CreateDefinition)
+	attributeRouter.Delete("/categories/{category}/attributes/{id}", attributeHandler.DeleteDefinition)
+}
+
+func SetupTagRoutes(tagRouter *chi.Mux, tagService service.TagService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	tagHandler := handler.NewTagHandler(tagService, loggers, metrics)
+
+	tagRouter.Get("/tags", tagHandler.ListTags)
+}
+
+func SetupTrackingRoutes(trackingRouter *chi.Mux, trackingService service.TrackingService, adService service.AdService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	trackingHandler := handler.NewTrackingHandler(trackingService, adService, loggers, metrics)
+
+	trackingRouter.Post("/ads/{id}/impressions", trackingHandler.RecordImpression)
+	trackingRouter.Post("/ads/{id}/clicks", trackingHandler.RecordClick)
+	trackingRouter.Get("/ads/{id}/pixel.gif", trackingHandler.Pixel)
+	trackingRouter.Get("/ads/{id}/click", trackingHandler.Redirect)
+	trackingRouter.Get("/ads/{id}/stats", trackingHandler.GetStats)
+}
+
+func SetupCampaignRoutes(campaignRouter *chi.Mux, campaignService service.CampaignService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	campaignHandler := handler.NewCampaignHandler(campaignService, loggers, metrics)
+
+	campaignRouter.Get("/campaigns", campaignHandler.ListCampaigns)
+	campaignRouter.Get("/campaigns/{id}", campaignHandler.GetCampaignByID)
+	campaignRouter.Post("/campaigns", campaignHandler.CreateCampaign)
+	campaignRouter.Put("/campaigns/{id}", campaignHandler.UpdateCampaign)
+	campaignRouter.Delete("/campaigns/{id}", campaignHandler.DeleteCampaign)
+}
+
+func SetupServingRoutes(servingRouter *chi.Mux, servingService service.ServingService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	servingHandler := handler.NewServingHandler(servingService, loggers, metrics)
+
+	servingRouter.Get("/serve", servingHandler.Serve)
+}
+
+func SetupVariantRoutes(variantRouter *chi.Mux, variantService service.VariantService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	variantHandler := handler.NewVariantHandler(variantService, loggers, metrics)
+
+	variantRouter.Get("/ads/{id}/variants", variantHandler.ListVariants)
+	variantRouter.Post("/ads/{id}/variants", variantHandler.CreateVariant)
+	variantRouter.Get("/ads/{id}/variants/assignment", variantHandler.AssignVariant)
+	variantRouter.Get("/ads/{id}/variants/results", variantHandler.GetResults)
+	variantRouter.Delete("/ads/{id}/variants/{variantId}", variantHandler.DeleteVariant)
+	variantRouter.Post("/ads/{id}/variants/{variantId}/impressions", variantHandler.RecordImpression)
+	variantRouter.Post("/ads/{id}/variants/{variantId}/clicks", variantHandler.RecordClick)
+}
+
+func SetupFavoriteRoutes(favoriteRouter *chi.Mux, favoriteService service.FavoriteService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	favoriteHandler := handler.NewFavoriteHandler(favoriteService, loggers, metrics)
+
+	favoriteRouter.Group(func(r chi.Router) {
+		r.Use(handler.RequireUser)
+
+		r.Get("/me/favorites", favoriteHandler.ListFavorites)
+		r.Put("/me/favorites/{adId}", favoriteHandler.AddFavorite)
+		r.Delete("/me/favorites/{adId}", favoriteHandler.RemoveFavorite)
+	})
+}
+
+func SetupSavedSearchRoutes(savedSearchRouter *chi.Mux, savedSearchService service.SavedSearchService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	savedSearchHandler := handler.NewSavedSearchHandler(savedSearchService, loggers, metrics)
+
+	savedSearchRouter.Group(func(r chi.Router) {
+		r.Use(handler.RequireUser)
+
+		r.Get("/me/saved-searches", savedSearchHandler.ListSavedSearches)
+		r.Post("/me/saved-searches", savedSearchHandler.CreateSavedSearch)
+		r.Delete("/me/saved-searches/{id}", savedSearchHandler.DeleteSavedSearch)
+	})
+}
+
+func SetupWebhookRoutes(webhookRouter *chi.Mux, webhookService service.WebhookService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	webhookHandler := handler.NewWebhookHandler(webhookService, loggers, metrics)
+
+	webhookRouter.Get("/webhooks", webhookHandler.ListSubscriptions)
+	webhookRouter.Post("/webhooks", webhookHandler.CreateSubscription)
+	webhookRouter.Get("/webhooks/dead-letters", webhookHandler.ListDeadLetters)
+	webhookRouter.Post("/webhooks/deliveries/{deliveryId}/redeliver", webhookHandler.Redeliver)
+	webhookRouter.Get("/webhooks/{id}", webhookHandler.GetSubscription)
+	webhookRouter.Delete("/webhooks/{id}", webhookHandler.DeleteSubscription)
+	webhookRouter.Get("/webhooks/{id}/deliveries", webhookHandler.ListDeliveries)
+}
+
+func SetupStreamRoutes(streamRouter *chi.Mux, streamService service.StreamService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics) {
+	streamHandler := handler.NewStreamHandler(streamService, loggers, metrics)
+
+	webSocketHandler := handler.NewWebSocketHandler(streamService, loggers, metrics)
+
+	streamRouter.Get("/ads/stream", streamHandler.Stream)
+	streamRouter.With(handler.RequireUser).Get("/ws", webSocketHandler.Serve)
+}
+
+func SetupGraphQLRoutes(graphqlRouter *chi.Mux, adService service.AdService, loggers *logger.Loggers, metrics *metrics.HandlerMetrics, options gql.Options) error {
+	graphqlHandler, err := gql.NewHandler(adService, loggers, metrics, options)
+	if err != nil {
+		return err
+	}
 
-	adRouter.Get("/ads", adHandler.GetAllAds)
-	adRouter.Get("/ads/{id}", adHandler.GetAdByID)
-	adRouter.Post("/ads", adHandler.CreateAd)
-	adRouter.Put("/ads/{id}", adHandler.UpdateAd)
-	adRouter.Delete("/ads/{id}", adHandler.DeleteAd)
+	graphqlRouter.Method(http.MethodGet, "/graphql", graphqlHandler)
+	graphqlRouter.Method(http.MethodPost, "/graphql", graphqlHandler)
+	return nil
 }
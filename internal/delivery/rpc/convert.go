@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	adv1 "ad-service/api/ad/v1"
+	"ad-service/internal/domain"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoAd(ad *domain.Ad) (*adv1.Ad, error) {
+	if ad == nil {
+		return nil, nil
+	}
+
+	pb := &adv1.Ad{
+		Id:            ad.ID,
+		Title:         ad.Title,
+		Description:   ad.Description,
+		Price:         ad.Price,
+		Category:      ad.Category,
+		Tags:          ad.Tags,
+		TargetUrl:     ad.TargetURL,
+		CampaignId:    ad.CampaignID,
+		Weight:        int32(ad.Weight),
+		Targeting:     toProtoTargeting(ad.Targeting),
+		FavoriteCount: ad.FavoriteCount,
+		CreatedAt:     timestamppb.New(ad.CreatedAt),
+		UpdatedAt:     timestamppb.New(ad.UpdatedAt),
+		Active:        ad.Active,
+	}
+	if len(ad.Attributes) > 0 {
+		attributes, err := structpb.NewStruct(ad.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert attributes of ad %d: %w", ad.ID, err)
+		}
+		pb.Attributes = attributes
+	}
+	return pb, nil
+}
+
+func fromProtoAd(pb *adv1.Ad) *domain.Ad {
+	if pb == nil {
+		return &domain.Ad{}
+	}
+
+	ad := &domain.Ad{
+		ID:          pb.GetId(),
+		Title:       pb.GetTitle(),
+		Description: pb.GetDescription(),
+		Price:       pb.GetPrice(),
+		Category:    pb.GetCategory(),
+		Tags:        pb.GetTags(),
+		TargetURL:   pb.GetTargetUrl(),
+		CampaignID:  pb.CampaignId,
+		Weight:      int(pb.GetWeight()),
+		Targeting:   fromProtoTargeting(pb.GetTargeting()),
+		Active:      pb.GetActive(),
+	}
+	if pb.GetAttributes() != nil {
+		ad.Attributes = pb.GetAttributes().AsMap()
+	}
+	return ad
+}
+
+func toProtoTargeting(t *domain.Targeting) *adv1.Targeting {
+	if t == nil {
+		return nil
+	}
+
+	pb := &adv1.Targeting{
+		Locales:   t.Locales,
+		Countries: t.Countries,
+		Timezone:  t.Timezone,
+	}
+	for _, device := range t.Devices {
+		pb.Devices = append(pb.Devices, string(device))
+	}
+	for _, window := range t.Hours {
+		pb.Hours = append(pb.Hours, &adv1.Targeting_HourWindow{Start: int32(window.Start), End: int32(window.End)})
+	}
+	if len(t.Segments) > 0 {
+		pb.Segments = make(map[string]*adv1.Targeting_Segment, len(t.Segments))
+		for key, values := range t.Segments {
+			pb.Segments[key] = &adv1.Targeting_Segment{Values: values}
+		}
+	}
+	return pb
+}
+
+func fromProtoTargeting(pb *adv1.Targeting) *domain.Targeting {
+	if pb == nil {
+		return nil
+	}
+
+	t := &domain.Targeting{
+		Locales:   pb.GetLocales(),
+		Countries: pb.GetCountries(),
+		Timezone:  pb.GetTimezone(),
+	}
+	for _, device := range pb.GetDevices() {
+		t.Devices = append(t.Devices, domain.DeviceType(device))
+	}
+	for _, window := range pb.GetHours() {
+		t.Hours = append(t.Hours, domain.HourWindow{Start: int(window.GetStart()), End: int(window.GetEnd())})
+	}
+	if len(pb.GetSegments()) > 0 {
+		t.Segments = make(map[string][]string, len(pb.GetSegments()))
+		for key, segment := range pb.GetSegments() {
+			t.Segments[key] = segment.GetValues()
+		}
+	}
+	return t
+}
+
+func fromProtoFilter(pb *adv1.AdFilter) domain.AdFilter {
+	filter := domain.AdFilter{
+		Category:   pb.GetCategory(),
+		Tags:       pb.GetTags(),
+		TagMode:    domain.TagMatchMode(pb.GetTagMode()),
+		CampaignID: pb.GetCampaignId(),
+	}
+	for _, attr := range pb.GetAttributes() {
+		filter.Attributes = append(filter.Attributes, domain.AttributeFilter{
+			Name:  attr.GetName(),
+			Value: attr.GetValue(),
+			Min:   attr.Min,
+			Max:   attr.Max,
+		})
+	}
+	return filter
+}
+
+func toProtoEvent(event *domain.AdEvent) (*adv1.AdEvent, error) {
+	ad, err := toProtoAd(event.Ad)
+	if err != nil {
+		return nil, err
+	}
+	return &adv1.AdEvent{
+		Id:         event.ID,
+		Type:       string(event.Type),
+		AdId:       event.AdID,
+		Ad:         ad,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}, nil
+}
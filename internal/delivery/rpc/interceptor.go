@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"ad-service/internal/infrastructure/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryInterceptor traces and measures unary calls the way the HTTP
+// handlers do for requests.
+func UnaryInterceptor(metrics *metrics.GRPCMetrics) grpc.UnaryServerInterceptor {
+	tracer := otel.Tracer("ad-service/grpc")
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := tracer.Start(ctx, "GRPC "+path.Base(info.FullMethod))
+		defer span.End()
+
+		startTime := time.Now()
+		resp, err := handler(ctx, req)
+		observe(metrics, span, info.FullMethod, startTime, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor traces and measures streaming calls over their whole
+// lifetime.
+func StreamInterceptor(metrics *metrics.GRPCMetrics) grpc.StreamServerInterceptor {
+	tracer := otel.Tracer("ad-service/grpc")
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := tracer.Start(ss.Context(), "GRPC "+path.Base(info.FullMethod))
+		defer span.End()
+
+		startTime := time.Now()
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		observe(metrics, span, info.FullMethod, startTime, err)
+		return err
+	}
+}
+
+func observe(metrics *metrics.GRPCMetrics, span trace.Span, method string, startTime time.Time, err error) {
+	code := status.Code(err)
+	span.SetAttributes(
+		attribute.String("rpc.method", method),
+		attribute.String("rpc.grpc.status_code", code.String()),
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	duration := time.Since(startTime).Seconds()
+	metrics.RequestCount.WithLabelValues(method, code.String()).Inc()
+	metrics.RequestDuration.WithLabelValues(method, code.String()).Observe(duration)
+}
+
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
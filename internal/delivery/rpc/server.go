@@ -0,0 +1,198 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	adv1 "ad-service/api/ad/v1"
+	"ad-service/internal/domain"
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// AdServer implements the ad.v1.AdService gRPC API on top of the same
+// services as the REST handlers.
+type AdServer struct {
+	adv1.UnimplementedAdServiceServer
+
+	service service.AdService
+	stream  service.StreamService
+	logger  *logger.Loggers
+}
+
+func NewAdServer(service service.AdService, stream service.StreamService, logger *logger.Loggers) *AdServer {
+	return &AdServer{
+		service: service,
+		stream:  stream,
+		logger:  logger,
+	}
+}
+
+func (s *AdServer) Register(server *grpc.Server) {
+	adv1.RegisterAdServiceServer(server, s)
+}
+
+func (s *AdServer) GetAd(ctx context.Context, req *adv1.GetAdRequest) (*adv1.Ad, error) {
+	ad, err := s.service.GetAdByID(ctx, req.GetId())
+	if err != nil {
+		return nil, s.toStatus(err, "failed to retrieve ad")
+	}
+	return s.respondAd(ad)
+}
+
+func (s *AdServer) ListAds(ctx context.Context, req *adv1.ListAdsRequest) (*adv1.ListAdsResponse, error) {
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 10 // Default limit
+	}
+
+	page := int(req.GetPage())
+	if page <= 0 {
+		page = 1 // Default page number
+	}
+
+	sortBy := req.GetSortBy()
+	if sortBy == "" {
+		sortBy = "created_at" // Default sort column
+	}
+
+	order := req.GetOrder()
+	if order == "" {
+		order = "ASC" // Default sort order
+	}
+
+	result, err := s.service.GetAllAds(ctx, limit, (page-1)*limit, sortBy, order, fromProtoFilter(req.GetFilter()))
+	if err != nil {
+		return nil, s.toStatus(err, "failed to retrieve ads")
+	}
+
+	resp := &adv1.ListAdsResponse{
+		CurrentPage: int32(result.CurrentPage),
+		NextPage:    int32(result.NextPage),
+		PrevPage:    int32(result.PrevPage),
+		TotalPages:  int32(result.TotalPages),
+	}
+	for _, ad := range result.Ads {
+		pb, err := toProtoAd(ad)
+		if err != nil {
+			return nil, s.toStatus(err, "failed to encode ad")
+		}
+		resp.Ads = append(resp.Ads, pb)
+	}
+	return resp, nil
+}
+
+func (s *AdServer) CreateAd(ctx context.Context, req *adv1.CreateAdRequest) (*adv1.Ad, error) {
+	ad := fromProtoAd(req.GetAd())
+	ad.ID = 0
+
+	created, err := s.service.CreateAd(ctx, ad)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to create ad")
+	}
+	return s.respondAd(created)
+}
+
+func (s *AdServer) UpdateAd(ctx context.Context, req *adv1.UpdateAdRequest) (*adv1.Ad, error) {
+	updated, err := s.service.UpdateAd(ctx, fromProtoAd(req.GetAd()))
+	if err != nil {
+		return nil, s.toStatus(err, "failed to update ad")
+	}
+	return s.respondAd(updated)
+}
+
+func (s *AdServer) DeleteAd(ctx context.Context, req *adv1.DeleteAdRequest) (*emptypb.Empty, error) {
+	if err := s.service.DeleteAd(ctx, req.GetId()); err != nil {
+		return nil, s.toStatus(err, "failed to delete ad")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// WatchAds streams ad events like GET /ads/stream. The stream ends with
+// Unavailable when the client falls behind or the server shuts down; the
+// client resumes with the id of the last event it received.
+func (s *AdServer) WatchAds(req *adv1.WatchAdsRequest, stream adv1.AdService_WatchAdsServer) error {
+	ctx := stream.Context()
+
+	filter := domain.StreamFilter{
+		AdIDs:  req.GetAdIds(),
+		Filter: fromProtoFilter(req.GetFilter()),
+	}
+	for _, eventType := range req.GetEvents() {
+		filter.Events = append(filter.Events, domain.AdEventType(eventType))
+	}
+
+	sub, err := s.stream.Subscribe(ctx, req.GetLastEventId(), filter)
+	if err != nil {
+		return s.toStatus(err, "failed to subscribe to event stream")
+	}
+	defer sub.Close()
+
+	if sub.Gap {
+		if err := stream.Send(&adv1.WatchAdsResponse{Payload: &adv1.WatchAdsResponse_Resync{Resync: true}}); err != nil {
+			return err
+		}
+	}
+	for _, event := range sub.Replay {
+		if err := s.sendEvent(stream, event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream closed, resume with last_event_id")
+			}
+			if err := s.sendEvent(stream, event); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func (s *AdServer) sendEvent(stream adv1.AdService_WatchAdsServer, event *domain.AdEvent) error {
+	pb, err := toProtoEvent(event)
+	if err != nil {
+		return s.toStatus(err, "failed to encode ad event")
+	}
+	return stream.Send(&adv1.WatchAdsResponse{Payload: &adv1.WatchAdsResponse_Event{Event: pb}})
+}
+
+func (s *AdServer) respondAd(ad *domain.Ad) (*adv1.Ad, error) {
+	pb, err := toProtoAd(ad)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to encode ad")
+	}
+	return pb, nil
+}
+
+// toStatus maps service errors to gRPC status codes, mirroring the HTTP
+// status codes of the REST handlers.
+func (s *AdServer) toStatus(err error, logMessage string) error {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.Is(err, service.ErrAdNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidID):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	case errors.Is(err, service.ErrStreamClosed):
+		return status.Error(codes.Unavailable, "event stream unavailable")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		s.logger.ErrorLogger.Error(logMessage, utils.Err(err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
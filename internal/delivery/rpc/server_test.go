@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"ad-service/internal/service"
+	"ad-service/pkg/logger"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	loggers, err := logger.SetupLogger("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewAdServer(nil, nil, loggers)
+
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{name: "not found", err: service.ErrAdNotFound, code: codes.NotFound, message: "ad not found"},
+		{name: "wrapped not found", err: fmt.Errorf("get ad 7: %w", service.ErrAdNotFound), code: codes.NotFound, message: "get ad 7: ad not found"},
+		{name: "invalid id", err: service.ErrInvalidID, code: codes.InvalidArgument, message: "invalid ad ID"},
+		{name: "validation", err: &service.ValidationError{Field: "title", Message: "is required"}, code: codes.InvalidArgument},
+		{name: "stream closed", err: service.ErrStreamClosed, code: codes.Unavailable, message: "event stream unavailable"},
+		{name: "canceled", err: context.Canceled, code: codes.Canceled},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), code: codes.DeadlineExceeded},
+		{name: "internal", err: errors.New("connection refused"), code: codes.Internal, message: "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(s.toStatus(tt.err, "failed"))
+			if !ok {
+				t.Fatalf("toStatus() did not return a status")
+			}
+			if st.Code() != tt.code {
+				t.Errorf("code = %s, want %s", st.Code(), tt.code)
+			}
+			if tt.message != "" && st.Message() != tt.message {
+				t.Errorf("message = %q, want %q", st.Message(), tt.message)
+			}
+		})
+	}
+}
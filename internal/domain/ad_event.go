@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type AdEventType string
+
+const (
+	AdCreated       AdEventType = "ad.created"
+	AdUpdated       AdEventType = "ad.updated"
+	AdDeleted       AdEventType = "ad.deleted"
+	AdStatusChanged AdEventType = "ad.status_changed"
+)
+
+func (t AdEventType) IsValid() bool {
+	switch t {
+	case AdCreated, AdUpdated, AdDeleted, AdStatusChanged:
+		return true
+	}
+	return false
+}
+
+// AdEvent describes a change of an ad after it has been stored. Ad holds the
+// stored ad, or the last known state for deletions. ID is the position of
+// the event in the outbox and increases with every change.
+type AdEvent struct {
+	ID         int64       `json:"id"`
+	Type       AdEventType `json:"type"`
+	AdID       int64       `json:"ad_id"`
+	Ad         *Ad         `json:"ad,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+type AttributeType string
+
+const (
+	AttributeTypeString  AttributeType = "string"
+	AttributeTypeNumber  AttributeType = "number"
+	AttributeTypeInteger AttributeType = "integer"
+	AttributeTypeBoolean AttributeType = "boolean"
+	AttributeTypeEnum    AttributeType = "enum"
+)
+
+func (t AttributeType) IsValid() bool {
+	switch t {
+	case AttributeTypeString, AttributeTypeNumber, AttributeTypeInteger, AttributeTypeBoolean, AttributeTypeEnum:
+		return true
+	}
+	return false
+}
+
+func (t AttributeType) IsNumeric() bool {
+	return t == AttributeTypeNumber || t == AttributeTypeInteger
+}
+
+// AttributeDefinition describes a custom field that ads of a category may carry.
+type AttributeDefinition struct {
+	ID         int64         `json:"id"`
+	Category   string        `json:"category"`
+	Name       string        `json:"name"`
+	Type       AttributeType `json:"type"`
+	Required   bool          `json:"required"`
+	EnumValues []string      `json:"enum_values,omitempty"`
+	Min        *float64      `json:"min,omitempty"`
+	Max        *float64      `json:"max,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+type PacingMode string
+
+const (
+	// PacingStandard spreads the daily budget evenly over the day.
+	PacingStandard PacingMode = "standard"
+	// PacingAccelerated spends the daily budget as fast as traffic allows.
+	PacingAccelerated PacingMode = "accelerated"
+)
+
+func (m PacingMode) IsValid() bool {
+	return m == PacingStandard || m == PacingAccelerated
+}
+
+type Campaign struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	TotalBudget float64    `json:"total_budget"`
+	DailyBudget float64    `json:"daily_budget"`
+	Spent       float64    `json:"spent"`
+	SpentToday  float64    `json:"spent_today"`
+	PacingMode  PacingMode `json:"pacing_mode"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BudgetExhausted reports whether the campaign has spent its total budget
+// or today's share of it.
+func (c *Campaign) BudgetExhausted() bool {
+	return c.TotalBudgetExhausted() || c.DailyBudgetExhausted()
+}
+
+func (c *Campaign) TotalBudgetExhausted() bool {
+	return c.Spent >= c.TotalBudget
+}
+
+// DailyBudgetExhausted reports whether the campaign has spent its daily
+// budget today. Campaigns without a daily budget never do.
+func (c *Campaign) DailyBudgetExhausted() bool {
+	return c.DailyBudget > 0 && c.SpentToday >= c.DailyBudget
+}
+
+func (c *Campaign) IsRunning(now time.Time) bool {
+	if now.Before(c.StartDate) {
+		return false
+	}
+	return c.EndDate == nil || now.Before(*c.EndDate)
+}
+
+// CanSpend reports whether the campaign may be charged for more traffic at
+// the given moment, taking the total budget, the daily budget and the pacing
+// mode into account.
+func (c *Campaign) CanSpend(now time.Time) bool {
+	if !c.IsRunning(now) || c.BudgetExhausted() {
+		return false
+	}
+	if c.DailyBudget <= 0 {
+		return true
+	}
+	if c.PacingMode == PacingStandard {
+		now = now.UTC()
+		dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		elapsed := now.Sub(dayStart).Seconds() / (24 * time.Hour).Seconds()
+		return c.SpentToday < c.DailyBudget*elapsed
+	}
+	return true
+}
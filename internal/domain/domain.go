@@ -3,11 +3,43 @@ package domain
 import "time"
 
 type Ad struct {
-	ID          int64     `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"` // added since it is common practice to add update too
-	Active      bool      `json:"active"`
+	ID            int64                  `json:"id"`
+	ExternalRef   string                 `json:"external_ref,omitempty"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	Price         float64                `json:"price"`
+	Category      string                 `json:"category,omitempty"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	TargetURL     string                 `json:"target_url,omitempty"`
+	CampaignID    *int64                 `json:"campaign_id,omitempty"`
+	Weight        int                    `json:"weight,omitempty"`
+	Targeting     *Targeting             `json:"targeting,omitempty"`
+	FavoriteCount int64                  `json:"favorite_count"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"` // added since it is common practice to add update too
+	Active        bool                   `json:"active"`
+}
+
+// AdFieldNames lists the fields of an ad that a client can select, in the
+// order they are encoded.
+var AdFieldNames = []string{
+	"id", "external_ref", "title", "description", "price", "category",
+	"attributes", "tags", "target_url", "campaign_id", "weight", "targeting",
+	"favorite_count", "created_at", "updated_at", "active",
+}
+
+// Related resources that can be embedded in ads.
+const (
+	AdExpandCampaign = "campaign"
+	AdExpandCategory = "category"
+)
+
+var AdExpansions = []string{AdExpandCampaign, AdExpandCategory}
+
+// AdRelations holds the related resources of a set of ads: campaigns by id
+// and the attribute definitions of every category by slug.
+type AdRelations struct {
+	Campaigns  map[int64]*Campaign
+	Categories map[string][]*AttributeDefinition
 }
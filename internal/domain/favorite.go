@@ -0,0 +1,9 @@
+package domain
+
+import "time"
+
+type Favorite struct {
+	AdID        int64     `json:"ad_id"`
+	FavoritedAt time.Time `json:"favorited_at"`
+	Ad          *Ad       `json:"ad"`
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "fmt"
+
+// AttributeFilter matches ads by a single attribute value. Value is compared
+// for equality, Min and Max bound numeric attributes.
+type AttributeFilter struct {
+	Name  string   `json:"name"`
+	Value string   `json:"value,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+type AdFilter struct {
+	Category   string            `json:"category,omitempty"`
+	Attributes []AttributeFilter `json:"attributes,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	TagMode    TagMatchMode      `json:"tag_mode,omitempty"`
+	CampaignID int64             `json:"campaign_id,omitempty"`
+
+	// Audience is matched against the targeting of each ad. It describes
+	// the current viewer and is never persisted.
+	Audience *AudienceContext `json:"-"`
+
+	// Fields limits the columns loaded to the named AdFieldNames; the id
+	// is always loaded. Empty loads every field.
+	Fields []string `json:"-"`
+}
+
+func (f AdFilter) IsEmpty() bool {
+	return f.Category == "" && len(f.Attributes) == 0 && len(f.Tags) == 0 && f.CampaignID == 0 && f.Audience == nil
+}
+
+// Matches reports whether the ad satisfies the filter. It mirrors the SQL
+// filtering of the ad listing for callers that work on ads in memory.
+func (f AdFilter) Matches(ad *Ad) bool {
+	if f.Category != "" && ad.Category != f.Category {
+		return false
+	}
+	if f.CampaignID > 0 && (ad.CampaignID == nil || *ad.CampaignID != f.CampaignID) {
+		return false
+	}
+	if f.Audience != nil && !ad.Targeting.Matches(f.Audience) {
+		return false
+	}
+
+	for _, attr := range f.Attributes {
+		value, ok := ad.Attributes[attr.Name]
+		if !ok || value == nil {
+			return false
+		}
+		if attr.Value != "" && fmt.Sprint(value) != attr.Value {
+			return false
+		}
+		if attr.Min != nil || attr.Max != nil {
+			n, ok := value.(float64)
+			if !ok {
+				return false
+			}
+			if attr.Min != nil && n < *attr.Min {
+				return false
+			}
+			if attr.Max != nil && n > *attr.Max {
+				return false
+			}
+		}
+	}
+
+	if len(f.Tags) > 0 {
+		adTags := make(map[string]struct{}, len(ad.Tags))
+		for _, tag := range ad.Tags {
+			adTags[tag] = struct{}{}
+		}
+		matched := 0
+		for _, tag := range f.Tags {
+			if _, ok := adTags[tag]; ok {
+				matched++
+			}
+		}
+		if f.TagMode == TagMatchAll && matched < len(f.Tags) {
+			return false
+		}
+		if matched == 0 {
+			return false
+		}
+	}
+
+	return true
+}
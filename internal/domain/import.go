@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportSucceeded ImportStatus = "succeeded"
+	ImportFailed    ImportStatus = "failed"
+)
+
+func (s ImportStatus) IsFinished() bool {
+	return s == ImportSucceeded || s == ImportFailed
+}
+
+// ImportJob tracks a bulk import of ads. Rows are counted as they are read;
+// Created and Updated count the ads written, or that would have been
+// written in a dry run.
+type ImportJob struct {
+	ID         string       `json:"id"`
+	Status     ImportStatus `json:"status"`
+	Format     string       `json:"format"`
+	DryRun     bool         `json:"dry_run"`
+	Total      int          `json:"total_rows"`
+	Created    int          `json:"created_rows"`
+	Updated    int          `json:"updated_rows"`
+	Failed     int          `json:"failed_rows"`
+	Error      string       `json:"error,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	StartedAt  *time.Time   `json:"started_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+}
+
+// ImportRowError explains why a row of an import was skipped. Row numbers
+// start at 1 with the first row of data; Field is empty when the row could
+// not be read at all.
+type ImportRowError struct {
+	Row         int    `json:"row"`
+	ExternalRef string `json:"external_ref,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Message     string `json:"message"`
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+func (s JobStatus) IsValid() bool {
+	return s == JobPending || s == JobRunning || s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+func (s JobStatus) IsFinished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Job is a unit of background work run by the job workers. A pending job
+// runs once RunAt has passed; a running job belongs to the worker holding
+// its lease until the lease expires. The payload is internal to the job
+// type and not exposed through the API.
+type Job struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Status          JobStatus       `json:"status"`
+	Payload         json.RawMessage `json:"-"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Progress        JobProgress     `json:"progress"`
+	Attempts        int             `json:"attempts"`
+	MaxAttempts     int             `json:"max_attempts"`
+	Error           string          `json:"error,omitempty"`
+	CancelRequested bool            `json:"cancel_requested,omitempty"`
+	RunAt           time.Time       `json:"run_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+}
+
+// JobProgress counts the work a job has done, in units of its own choosing.
+// Total is 0 while it is unknown.
+type JobProgress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total"`
+}
+
+// Percent is the share of Total that is done, or -1 while Total is unknown.
+func (p JobProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	percent := float64(p.Done) * 100 / float64(p.Total)
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+type JobFilter struct {
+	Type   string
+	Status JobStatus
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// OutboxMessage is an ad event stored in the same transaction as the change
+// it describes, waiting to be published.
+type OutboxMessage struct {
+	ID          int64
+	AggregateID int64
+	EventType   AdEventType
+	Payload     []byte
+	// Age is how long the message has been waiting, measured by the database.
+	Age time.Duration
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// SavedSearch is a listing query a user wants to be notified about. Query
+// keeps the original GET /ads query string, Filter is its parsed form.
+type SavedSearch struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Filter    AdFilter  `json:"filter"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether a newly created or updated ad should be reported
+// to the owner of the search.
+func (s *SavedSearch) Matches(ad *Ad) bool {
+	return ad.Active && s.Filter.Matches(ad)
+}
+
+type SearchNotification struct {
+	UserID          string    `json:"user_id"`
+	SavedSearchID   int64     `json:"saved_search_id"`
+	SavedSearchName string    `json:"saved_search_name"`
+	Ad              *Ad       `json:"ad"`
+	MatchedAt       time.Time `json:"matched_at"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+// StreamFilter selects the ad events a live subscriber receives. Events and
+// AdIDs narrow by event type and ad, Filter applies the ad listing filter to
+// the ad carried by the event.
+type StreamFilter struct {
+	Events []AdEventType `json:"events,omitempty"`
+	AdIDs  []int64       `json:"ad_ids,omitempty"`
+	Filter AdFilter      `json:"filter"`
+}
+
+func (f *StreamFilter) Matches(event *AdEvent) bool {
+	if len(f.Events) > 0 && !containsEventType(f.Events, event.Type) {
+		return false
+	}
+	if len(f.AdIDs) > 0 && !containsID(f.AdIDs, event.AdID) {
+		return false
+	}
+	if f.Filter.IsEmpty() {
+		return true
+	}
+	return event.Ad != nil && f.Filter.Matches(event.Ad)
+}
+
+func containsEventType(types []AdEventType, t AdEventType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type DeviceType string
+
+const (
+	DeviceDesktop DeviceType = "desktop"
+	DeviceMobile  DeviceType = "mobile"
+	DeviceTablet  DeviceType = "tablet"
+)
+
+func (d DeviceType) IsValid() bool {
+	return d == DeviceDesktop || d == DeviceMobile || d == DeviceTablet
+}
+
+// HourWindow is a half-open range of hours of the day. A window whose start
+// is after its end wraps around midnight, e.g. 22-6.
+type HourWindow struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (w HourWindow) Contains(hour int) bool {
+	if w.Start <= w.End {
+		return hour >= w.Start && hour < w.End
+	}
+	return hour >= w.Start || hour < w.End
+}
+
+// Targeting restricts the audience of an ad. Every non-empty criterion must
+// match; an empty criterion matches everybody.
+type Targeting struct {
+	Locales   []string            `json:"locales,omitempty"`
+	Countries []string            `json:"countries,omitempty"`
+	Devices   []DeviceType        `json:"devices,omitempty"`
+	Hours     []HourWindow        `json:"hours,omitempty"`
+	Timezone  string              `json:"timezone,omitempty"`
+	Segments  map[string][]string `json:"segments,omitempty"`
+}
+
+func (t *Targeting) IsEmpty() bool {
+	return t == nil || (len(t.Locales) == 0 && len(t.Countries) == 0 && len(t.Devices) == 0 && len(t.Hours) == 0 && len(t.Segments) == 0)
+}
+
+// AudienceContext describes who is looking at ads, built from the request.
+type AudienceContext struct {
+	Locale   string
+	Country  string
+	Device   DeviceType
+	Time     time.Time
+	Segments map[string]string
+}
+
+func (t *Targeting) Matches(audience *AudienceContext) bool {
+	if t.IsEmpty() {
+		return true
+	}
+	if audience == nil {
+		return false
+	}
+
+	if len(t.Locales) > 0 && !matchLocale(t.Locales, audience.Locale) {
+		return false
+	}
+	if len(t.Countries) > 0 && !containsString(t.Countries, audience.Country) {
+		return false
+	}
+	if len(t.Devices) > 0 {
+		matched := false
+		for _, device := range t.Devices {
+			if device == audience.Device {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(t.Hours) > 0 && !t.matchHours(audience.Time) {
+		return false
+	}
+	for key, values := range t.Segments {
+		value, ok := audience.Segments[key]
+		if !ok || !containsString(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Targeting) matchHours(at time.Time) bool {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	loc := time.UTC
+	if t.Timezone != "" {
+		if l, err := time.LoadLocation(t.Timezone); err == nil {
+			loc = l
+		}
+	}
+	hour := at.In(loc).Hour()
+	for _, window := range t.Hours {
+		if window.Contains(hour) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLocale accepts the audience locale when it equals a targeted locale or
+// when a targeted bare language covers it, so "en" matches "en-us".
+func matchLocale(locales []string, locale string) bool {
+	if locale == "" {
+		return false
+	}
+	language := locale
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		language = locale[:i]
+	}
+	for _, l := range locales {
+		if l == locale || l == language {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
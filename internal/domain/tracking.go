@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+type TrackingEventType string
+
+const (
+	TrackingImpression TrackingEventType = "impression"
+	TrackingClick      TrackingEventType = "click"
+)
+
+type TrackingEvent struct {
+	// ID identifies the event across redeliveries so it is charged once.
+	ID         string            `json:"id"`
+	AdID       int64             `json:"ad_id"`
+	Type       TrackingEventType `json:"type"`
+	ViewerID   string            `json:"viewer_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+type DailyAdStats struct {
+	Day         string  `json:"day"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	CTR         float64 `json:"ctr"`
+}
+
+type AdStats struct {
+	AdID        int64           `json:"ad_id"`
+	Impressions int64           `json:"impressions"`
+	Clicks      int64           `json:"clicks"`
+	CTR         float64         `json:"ctr"`
+	Days        []*DailyAdStats `json:"days"`
+}
+
+func ClickThroughRate(impressions, clicks int64) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return float64(clicks) / float64(impressions)
+}
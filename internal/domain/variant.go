@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Variant is an alternative creative of an ad used for A/B testing.
+type Variant struct {
+	ID          int64     `json:"id"`
+	AdID        int64     `json:"ad_id"`
+	Name        string    `json:"name"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Weight      int       `json:"weight"`
+	Impressions int64     `json:"impressions"`
+	Clicks      int64     `json:"clicks"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AssignVariant deterministically maps a viewer to one of the variants,
+// proportionally to their weights. Variants must be ordered by id so that the
+// same viewer keeps getting the same variant.
+func AssignVariant(adID int64, viewerID string, variants []*Variant) *Variant {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(strconv.FormatInt(adID, 10) + ":" + viewerID))
+	bucket := int(h.Sum64() % uint64(total))
+
+	for _, v := range variants {
+		bucket -= v.Weight
+		if bucket < 0 {
+			return v
+		}
+	}
+	return variants[len(variants)-1]
+}
+
+type VariantResult struct {
+	VariantID      int64   `json:"variant_id"`
+	Name           string  `json:"name"`
+	Impressions    int64   `json:"impressions"`
+	Clicks         int64   `json:"clicks"`
+	ConversionRate float64 `json:"conversion_rate"`
+	Lift           float64 `json:"lift"`
+	ZScore         float64 `json:"z_score"`
+	PValue         float64 `json:"p_value"`
+	Significant    bool    `json:"significant"`
+}
+
+type VariantResults struct {
+	AdID             int64            `json:"ad_id"`
+	ControlVariantID int64            `json:"control_variant_id"`
+	Alpha            float64          `json:"alpha"`
+	Variants         []*VariantResult `json:"variants"`
+}
+
+// CompareVariants evaluates every variant against the control (the first
+// variant) with a two-sided two-proportion z-test on click-through rates.
+func CompareVariants(adID int64, variants []*Variant, alpha float64) *VariantResults {
+	results := &VariantResults{AdID: adID, Alpha: alpha, Variants: []*VariantResult{}}
+	if len(variants) == 0 {
+		return results
+	}
+
+	control := variants[0]
+	results.ControlVariantID = control.ID
+	controlRate := ClickThroughRate(control.Impressions, control.Clicks)
+
+	for _, v := range variants {
+		result := &VariantResult{
+			VariantID:      v.ID,
+			Name:           v.Name,
+			Impressions:    v.Impressions,
+			Clicks:         v.Clicks,
+			ConversionRate: ClickThroughRate(v.Impressions, v.Clicks),
+			PValue:         1,
+		}
+		if v.ID != control.ID {
+			if controlRate > 0 {
+				result.Lift = (result.ConversionRate - controlRate) / controlRate
+			}
+			result.ZScore, result.PValue = twoProportionZTest(control.Impressions, control.Clicks, v.Impressions, v.Clicks)
+			result.Significant = result.PValue < alpha
+		}
+		results.Variants = append(results.Variants, result)
+	}
+	return results
+}
+
+func twoProportionZTest(n1, x1, n2, x2 int64) (float64, float64) {
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+	p1 := float64(x1) / float64(n1)
+	p2 := float64(x2) / float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0, 1
+	}
+	z := (p2 - p1) / se
+	return z, math.Erfc(math.Abs(z) / math.Sqrt2)
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestCompareVariants(t *testing.T) {
+	control := &Variant{ID: 1, Name: "control", Impressions: 1000, Clicks: 100}
+	tests := []struct {
+		name        string
+		variant     *Variant
+		z           float64
+		p           float64
+		lift        float64
+		significant bool
+	}{
+		{name: "clear winner", variant: &Variant{ID: 2, Impressions: 1000, Clicks: 150}, z: 3.3806, p: 0.000723, lift: 0.5, significant: true},
+		{name: "within noise", variant: &Variant{ID: 2, Impressions: 1000, Clicks: 110}, z: 0.7294, p: 0.4657, lift: 0.1},
+		{name: "worse but not significantly", variant: &Variant{ID: 2, Impressions: 1000, Clicks: 80}, z: -1.5627, p: 0.1181, lift: -0.2},
+		{name: "no impressions", variant: &Variant{ID: 2}, p: 1, lift: -1},
+		{name: "no clicks", variant: &Variant{ID: 2, Impressions: 1000}, z: -10.2598, p: 0, lift: -1, significant: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := CompareVariants(7, []*Variant{control, tt.variant}, 0.05)
+			if results.ControlVariantID != control.ID || len(results.Variants) != 2 {
+				t.Fatalf("CompareVariants() = %+v", results)
+			}
+			if got := results.Variants[0]; got.PValue != 1 || got.Significant {
+				t.Errorf("control compared against itself: %+v", got)
+			}
+
+			got := results.Variants[1]
+			if math.Abs(got.ZScore-tt.z) > 1e-3 {
+				t.Errorf("z = %.4f, want %.4f", got.ZScore, tt.z)
+			}
+			if math.Abs(got.PValue-tt.p) > 1e-4 {
+				t.Errorf("p = %.6f, want %.6f", got.PValue, tt.p)
+			}
+			if math.Abs(got.Lift-tt.lift) > 1e-9 {
+				t.Errorf("lift = %.4f, want %.4f", got.Lift, tt.lift)
+			}
+			if got.Significant != tt.significant {
+				t.Errorf("significant = %v, want %v", got.Significant, tt.significant)
+			}
+		})
+	}
+}
+
+func TestAssignVariant(t *testing.T) {
+	variants := []*Variant{{ID: 1, Weight: 3}, {ID: 2, Weight: 1}}
+	tests := []struct {
+		name     string
+		variants []*Variant
+		viewers  int
+		want     map[int64]float64
+	}{
+		{name: "weighted", variants: variants, viewers: 4000, want: map[int64]float64{1: 0.75, 2: 0.25}},
+		{name: "no weight", variants: []*Variant{{ID: 1}}, viewers: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make(map[int64]int)
+			for i := 0; i < tt.viewers; i++ {
+				viewer := "viewer-" + strconv.Itoa(i)
+				v := AssignVariant(7, viewer, tt.variants)
+				if tt.want == nil {
+					if v != nil {
+						t.Fatalf("AssignVariant() = %+v, want nil", v)
+					}
+					continue
+				}
+				if again := AssignVariant(7, viewer, tt.variants); again != v {
+					t.Fatalf("viewer %s moved from variant %d to %d", viewer, v.ID, again.ID)
+				}
+				counts[v.ID]++
+			}
+			for id, share := range tt.want {
+				if got := float64(counts[id]) / float64(tt.viewers); math.Abs(got-share) > 0.05 {
+					t.Errorf("variant %d got %.2f of viewers, want about %.2f", id, got, share)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@ type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value string, expiration time.Duration) error
 	Delete(ctx context.Context, key string) error
+	GetMany(ctx context.Context, keys []string) (map[string]string, error)
+	Increment(ctx context.Context, key string, expiration time.Duration) (int64, error)
 }
 
 func NewRedisCache(client *redis.Client) Cache {
@@ -34,3 +36,36 @@ func (r *RedisCache) Set(ctx context.Context, key string, value string, expirati
 func (r *RedisCache) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
+
+func (r *RedisCache) GetMany(ctx context.Context, keys []string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	results, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if s, ok := result.(string); ok {
+			values[keys[i]] = s
+		}
+	}
+	return values, nil
+}
+
+// Increment bumps the counter stored at key. The expiration is only set when
+// the counter is created, so the window starts with the first increment.
+func (r *RedisCache) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, expiration).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
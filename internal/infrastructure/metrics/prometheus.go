@@ -8,13 +8,23 @@ import (
 )
 
 type HandlerMetrics struct {
-	RequestCount    *prometheus.CounterVec
-	RequestDuration *prometheus.HistogramVec
+	RequestCount      *prometheus.CounterVec
+	RequestDuration   *prometheus.HistogramVec
+	SelectionDuration *prometheus.HistogramVec
 }
 
 type ServiceMetrics struct {
-	MethodCount    *prometheus.CounterVec
-	MethodDuration *prometheus.HistogramVec
+	MethodCount           *prometheus.CounterVec
+	MethodDuration        *prometheus.HistogramVec
+	OutboxLag             prometheus.Histogram
+	OutboxBacklogAge      prometheus.Gauge
+	OutboxFailures        *prometheus.CounterVec
+	TrackingEventsDropped *prometheus.CounterVec
+}
+
+type GRPCMetrics struct {
+	RequestCount    *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
 }
 
 type RepositoryMetrics struct {
@@ -40,11 +50,21 @@ func NewHandlerMetrics() *HandlerMetrics {
 		[]string{"method", "endpoint", "status"},
 	)
 
-	prometheus.MustRegister(requestCount, requestDuration)
+	selectionDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "handler_ad_selection_duration_seconds",
+			Help:    "Histogram of ad selection latency for the serving endpoint in seconds.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		},
+		[]string{"status"},
+	)
+
+	prometheus.MustRegister(requestCount, requestDuration, selectionDuration)
 
 	return &HandlerMetrics{
-		RequestCount:    requestCount,
-		RequestDuration: requestDuration,
+		RequestCount:      requestCount,
+		RequestDuration:   requestDuration,
+		SelectionDuration: selectionDuration,
 	}
 }
 
@@ -66,11 +86,72 @@ func NewServiceMetrics() *ServiceMetrics {
 		[]string{"method", "status"},
 	)
 
-	prometheus.MustRegister(methodCount, methodDuration)
+	outboxLag := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "service_outbox_relay_lag_seconds",
+			Help:    "Histogram of the time between an outbox event being committed and published in seconds.",
+			Buckets: []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+		},
+	)
+
+	outboxBacklogAge := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "service_outbox_backlog_age_seconds",
+			Help: "Age of the oldest unpublished outbox event in seconds, zero when the outbox is drained.",
+		},
+	)
+
+	outboxFailures := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_outbox_publish_failures_total",
+			Help: "Total number of outbox events a publisher failed to accept. Every failure holds back the outbox for all publishers.",
+		},
+		[]string{"publisher"},
+	)
+
+	trackingEventsDropped := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "service_tracking_events_dropped_total",
+			Help: "Total number of tracked events dropped without being stored or without their spend being recorded.",
+		},
+		[]string{"reason"},
+	)
+
+	prometheus.MustRegister(methodCount, methodDuration, outboxLag, outboxBacklogAge, outboxFailures, trackingEventsDropped)
 
 	return &ServiceMetrics{
-		MethodCount:    methodCount,
-		MethodDuration: methodDuration,
+		MethodCount:           methodCount,
+		MethodDuration:        methodDuration,
+		OutboxLag:             outboxLag,
+		OutboxBacklogAge:      outboxBacklogAge,
+		OutboxFailures:        outboxFailures,
+		TrackingEventsDropped: trackingEventsDropped,
+	}
+}
+
+func NewGRPCMetrics() *GRPCMetrics {
+	requestCount := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_requests_total",
+			Help: "Total number of gRPC calls handled by the gRPC server.",
+		},
+		[]string{"method", "code"},
+	)
+
+	requestDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_request_duration_seconds",
+			Help:    "Histogram of gRPC call latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "code"},
+	)
+
+	prometheus.MustRegister(requestCount, requestDuration)
+
+	return &GRPCMetrics{
+		RequestCount:    requestCount,
+		RequestDuration: requestDuration,
 	}
 }
 
@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/pkg/logger"
+	"context"
+)
+
+type logNotifier struct {
+	logger *logger.Loggers
+}
+
+// NewLogNotifier writes notifications to the info log. It is meant for
+// development and for deployments without a notification gateway.
+func NewLogNotifier(logger *logger.Loggers) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, notification *domain.SearchNotification) error {
+	n.logger.InfoLogger.Info("Saved search matched",
+		"user_id", notification.UserID,
+		"saved_search_id", notification.SavedSearchID,
+		"saved_search_name", notification.SavedSearchName,
+		"ad_id", notification.Ad.ID,
+	)
+	return nil
+}
@@ -0,0 +1,11 @@
+package notifier
+
+import (
+	"ad-service/internal/domain"
+	"context"
+)
+
+// Notifier delivers saved search matches to their owners.
+type Notifier interface {
+	Notify(ctx context.Context, notification *domain.SearchNotification) error
+}
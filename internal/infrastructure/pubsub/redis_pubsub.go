@@ -0,0 +1,60 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type PubSub interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channel string) Subscription
+}
+
+// Subscription delivers message payloads until it is closed. The underlying
+// connection reconnects on its own; messages sent while disconnected are lost.
+type Subscription interface {
+	Messages() <-chan []byte
+	Close() error
+}
+
+type RedisPubSub struct {
+	client *redis.Client
+}
+
+func NewRedisPubSub(client *redis.Client) PubSub {
+	return &RedisPubSub{
+		client: client,
+	}
+}
+
+func (r *RedisPubSub) Publish(ctx context.Context, channel string, payload []byte) error {
+	return r.client.Publish(ctx, channel, payload).Err()
+}
+
+func (r *RedisPubSub) Subscribe(ctx context.Context, channel string) Subscription {
+	ps := r.client.Subscribe(ctx, channel)
+	messages := make(chan []byte)
+
+	go func() {
+		defer close(messages)
+		for msg := range ps.Channel() {
+			messages <- []byte(msg.Payload)
+		}
+	}()
+
+	return &redisSubscription{pubsub: ps, messages: messages}
+}
+
+type redisSubscription struct {
+	pubsub   *redis.PubSub
+	messages chan []byte
+}
+
+func (s *redisSubscription) Messages() <-chan []byte {
+	return s.messages
+}
+
+func (s *redisSubscription) Close() error {
+	return s.pubsub.Close()
+}
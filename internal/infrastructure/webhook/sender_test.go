@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestHTTPSenderSend(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "accepted", statusCode: http.StatusOK},
+		{name: "no content", statusCode: http.StatusNoContent},
+		{name: "redirect", statusCode: http.StatusNotModified, wantErr: true},
+		{name: "receiver error", statusCode: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(`{"type":"ad.created"}`)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received, _ := io.ReadAll(r.Body)
+				if string(received) != string(body) {
+					t.Errorf("body = %s, want %s", received, body)
+				}
+				if got := r.Header.Get(EventHeader); got != "ad.created" {
+					t.Errorf("%s = %q", EventHeader, got)
+				}
+				if got := r.Header.Get(DeliveryHeader); got != "42" {
+					t.Errorf("%s = %q", DeliveryHeader, got)
+				}
+				timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+				if err != nil {
+					t.Errorf("%s: %v", TimestampHeader, err)
+				}
+				if !Verify("secret", timestamp, received, r.Header.Get(SignatureHeader)) {
+					t.Errorf("%s %q does not verify", SignatureHeader, r.Header.Get(SignatureHeader))
+				}
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			statusCode, err := NewHTTPSender(server.Client()).Send(context.Background(), &Request{
+				URL:        server.URL,
+				Secret:     "secret",
+				Event:      "ad.created",
+				DeliveryID: 42,
+				Body:       body,
+			})
+			if statusCode != tt.statusCode {
+				t.Errorf("Send() status = %d, want %d", statusCode, tt.statusCode)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{}`)
+	signature := Sign("secret", 1700000000, body)
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      bool
+	}{
+		{name: "valid", secret: "secret", timestamp: 1700000000, body: body, want: true},
+		{name: "wrong secret", secret: "other", timestamp: 1700000000, body: body},
+		{name: "replayed timestamp", secret: "secret", timestamp: 1700000001, body: body},
+		{name: "tampered body", secret: "secret", timestamp: 1700000000, body: []byte(`{"a":1}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.body, signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
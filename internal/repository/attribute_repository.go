@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AttributeRepository interface {
+	ListDefinitions(ctx context.Context, category string) ([]*domain.AttributeDefinition, error)
+	CreateDefinition(ctx context.Context, def *domain.AttributeDefinition) (*domain.AttributeDefinition, error)
+	DeleteDefinition(ctx context.Context, category string, id int64) error
+}
+
+type mysqlAttributeRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlAttributeRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) AttributeRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlAttributeRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+const attributeDefinitionColumns = "id, category, name, type, required, enum_values, min_value, max_value, created_at"
+
+func scanAttributeDefinition(row rowScanner) (*domain.AttributeDefinition, error) {
+	var def domain.AttributeDefinition
+	var enumValues []byte
+	var min, max sql.NullFloat64
+	if err := row.Scan(&def.ID, &def.Category, &def.Name, &def.Type, &def.Required, &enumValues, &min, &max, &def.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(enumValues) > 0 {
+		if err := json.Unmarshal(enumValues, &def.EnumValues); err != nil {
+			return nil, fmt.Errorf("failed to decode enum values: %w", err)
+		}
+	}
+	if min.Valid {
+		def.Min = &min.Float64
+	}
+	if max.Valid {
+		def.Max = &max.Float64
+	}
+	return &def, nil
+}
+
+func (r *mysqlAttributeRepository) ListDefinitions(ctx context.Context, category string) ([]*domain.AttributeDefinition, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListDefinitions")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("category", category))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListDefinitions", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListDefinitions", status).Observe(duration)
+	}()
+
+	query := "SELECT " + attributeDefinitionColumns + " FROM attribute_definitions WHERE category = ? ORDER BY name"
+
+	rows, err := r.db.QueryContext(ctx, query, category)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve attribute definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var defs []*domain.AttributeDefinition
+	for rows.Next() {
+		def, err := scanAttributeDefinition(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan attribute definition: %w", err)
+		}
+		defs = append(defs, def)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return defs, nil
+}
+
+func (r *mysqlAttributeRepository) CreateDefinition(ctx context.Context, def *domain.AttributeDefinition) (*domain.AttributeDefinition, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository CreateDefinition")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("category", def.Category),
+		attribute.String("attribute.name", def.Name),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CreateDefinition", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CreateDefinition", status).Observe(duration)
+	}()
+
+	var enumValues interface{}
+	if len(def.EnumValues) > 0 {
+		data, err := json.Marshal(def.EnumValues)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to encode enum values: %w", err)
+		}
+		enumValues = string(data)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO attribute_definitions (category, name, type, required, enum_values, min_value, max_value) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		def.Category, def.Name, def.Type, def.Required, enumValues, def.Min, def.Max)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			status = "conflict"
+			return nil, ErrDuplicate
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to insert attribute definition: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	query := "SELECT " + attributeDefinitionColumns + " FROM attribute_definitions WHERE id = ?"
+	created, err := scanAttributeDefinition(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to fetch inserted attribute definition: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *mysqlAttributeRepository) DeleteDefinition(ctx context.Context, category string, id int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository DeleteDefinition")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("category", category),
+		attribute.Int64("attribute.id", id),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeleteDefinition", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeleteDefinition", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM attribute_definitions WHERE id = ? AND category = ?", id, category)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete attribute definition: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type CampaignRepository interface {
+	ListCampaigns(ctx context.Context, limit int, offset int) ([]*domain.Campaign, error)
+	GetCampaignByID(ctx context.Context, id int64) (*domain.Campaign, error)
+	GetCampaignsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Campaign, error)
+	CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error)
+	UpdateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error)
+	DeleteCampaign(ctx context.Context, id int64) error
+	GetAdCampaigns(ctx context.Context, adIDs []int64) (map[int64]int64, error)
+	AddSpend(ctx context.Context, campaignID int64, day time.Time, charges map[string]float64) (float64, error)
+}
+
+type mysqlCampaignRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlCampaignRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) CampaignRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlCampaignRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+const campaignColumns = `id, name, total_budget, daily_budget, spent,
+	COALESCE((SELECT amount FROM campaign_daily_spend s WHERE s.campaign_id = campaigns.id AND s.day = UTC_DATE()), 0) AS spent_today,
+	pacing_mode, start_date, end_date, created_at, updated_at`
+
+func scanCampaign(row rowScanner) (*domain.Campaign, error) {
+	var campaign domain.Campaign
+	var endDate sql.NullTime
+	if err := row.Scan(
+		&campaign.ID,
+		&campaign.Name,
+		&campaign.TotalBudget,
+		&campaign.DailyBudget,
+		&campaign.Spent,
+		&campaign.SpentToday,
+		&campaign.PacingMode,
+		&campaign.StartDate,
+		&endDate,
+		&campaign.CreatedAt,
+		&campaign.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if endDate.Valid {
+		campaign.EndDate = &endDate.Time
+	}
+	return &campaign, nil
+}
+
+func (r *mysqlCampaignRepository) ListCampaigns(ctx context.Context, limit int, offset int) ([]*domain.Campaign, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListCampaigns")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListCampaigns", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListCampaigns", status).Observe(duration)
+	}()
+
+	query := "SELECT " + campaignColumns + " FROM campaigns ORDER BY id LIMIT ? OFFSET ?"
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	var campaigns []*domain.Campaign
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan campaign: %w", err)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+func (r *mysqlCampaignRepository) GetCampaignByID(ctx context.Context, id int64) (*domain.Campaign, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetCampaignByID")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("campaign.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetCampaignByID", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetCampaignByID", status).Observe(duration)
+	}()
+
+	campaign, err := scanCampaign(r.db.QueryRowContext(ctx, "SELECT "+campaignColumns+" FROM campaigns WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			status = "not_found"
+		} else {
+			status = "error"
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (r *mysqlCampaignRepository) GetCampaignsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Campaign, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetCampaignsByIDs")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("campaigns.count", len(ids)))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetCampaignsByIDs", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetCampaignsByIDs", status).Observe(duration)
+	}()
+
+	campaigns := make(map[int64]*domain.Campaign, len(ids))
+	if len(ids) == 0 {
+		return campaigns, nil
+	}
+
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	query := "SELECT " + campaignColumns + " FROM campaigns WHERE id IN (" + placeholders(len(ids)) + ")"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan campaign: %w", err)
+		}
+		campaigns[campaign.ID] = campaign
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+func (r *mysqlCampaignRepository) CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository CreateCampaign")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("campaign.name", campaign.Name))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CreateCampaign", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CreateCampaign", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO campaigns (name, total_budget, daily_budget, pacing_mode, start_date, end_date) VALUES (?, ?, ?, ?, ?, ?)",
+		campaign.Name, campaign.TotalBudget, campaign.DailyBudget, campaign.PacingMode, campaign.StartDate, campaign.EndDate)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to insert campaign: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	created, err := scanCampaign(r.db.QueryRowContext(ctx, "SELECT "+campaignColumns+" FROM campaigns WHERE id = ?", id))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to fetch inserted campaign: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *mysqlCampaignRepository) UpdateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository UpdateCampaign")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("campaign.id", campaign.ID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("UpdateCampaign", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("UpdateCampaign", status).Observe(duration)
+	}()
+
+	query := `
+		UPDATE campaigns
+		SET name = ?, total_budget = ?, daily_budget = ?, pacing_mode = ?, start_date = ?, end_date = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		campaign.Name, campaign.TotalBudget, campaign.DailyBudget, campaign.PacingMode, campaign.StartDate, campaign.EndDate, campaign.ID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return nil, sql.ErrNoRows
+	}
+
+	updated, err := scanCampaign(r.db.QueryRowContext(ctx, "SELECT "+campaignColumns+" FROM campaigns WHERE id = ?", campaign.ID))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to fetch updated campaign: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (r *mysqlCampaignRepository) DeleteCampaign(ctx context.Context, id int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository DeleteCampaign")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("campaign.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeleteCampaign", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeleteCampaign", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM campaigns WHERE id = ?", id)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *mysqlCampaignRepository) GetAdCampaigns(ctx context.Context, adIDs []int64) (map[int64]int64, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetAdCampaigns")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("ads.count", len(adIDs)))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetAdCampaigns", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetAdCampaigns", status).Observe(duration)
+	}()
+
+	campaigns := make(map[int64]int64)
+	if len(adIDs) == 0 {
+		return campaigns, nil
+	}
+
+	args := make([]interface{}, 0, len(adIDs))
+	for _, id := range adIDs {
+		args = append(args, id)
+	}
+
+	query := "SELECT id, campaign_id FROM ads WHERE campaign_id IS NOT NULL AND id IN (" + placeholders(len(adIDs)) + ")"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve ad campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var adID, campaignID int64
+		if err := rows.Scan(&adID, &campaignID); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan ad campaign: %w", err)
+		}
+		campaigns[adID] = campaignID
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+// AddSpend charges the campaign for the events of one day, keyed by event
+// id. Events charged before are skipped; the amount actually charged is
+// returned.
+func (r *mysqlCampaignRepository) AddSpend(ctx context.Context, campaignID int64, day time.Time, charges map[string]float64) (float64, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository AddSpend")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("campaign.id", campaignID),
+		attribute.Int("spend.events", len(charges)),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("AddSpend", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("AddSpend", status).Observe(duration)
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT IGNORE INTO campaign_charges (event_id, campaign_id, amount) VALUES (?, ?, ?)")
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to prepare campaign charge: %w", err)
+	}
+	defer stmt.Close()
+
+	var amount float64
+	for eventID, charge := range charges {
+		result, err := stmt.ExecContext(ctx, eventID, campaignID, charge)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return 0, fmt.Errorf("failed to insert campaign charge: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return 0, fmt.Errorf("failed to retrieve rows affected: %w", err)
+		}
+		if rowsAffected > 0 {
+			amount += charge
+		}
+	}
+
+	span.SetAttributes(attribute.Float64("spend.amount", amount))
+	if amount <= 0 {
+		status = "duplicate"
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE campaigns SET spent = spent + ? WHERE id = ?", amount, campaignID); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to update campaign spend: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO campaign_daily_spend (campaign_id, day, amount) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE amount = amount + VALUES(amount)",
+		campaignID, day.UTC().Format("2006-01-02"), amount); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to update campaign daily spend: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	ErrDuplicate = errors.New("duplicate entry")
+	// ErrLeaseLost means another worker took over a job whose lease ran
+	// out.
+	ErrLeaseLost = errors.New("job lease lost")
+	// ErrJobStatus means a job is not in a status that allows the change.
+	ErrJobStatus = errors.New("job status does not allow this")
+)
+
+const mysqlDuplicateEntry = 1062
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
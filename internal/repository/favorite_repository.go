@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/cache"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type FavoriteRepository interface {
+	AddFavorite(ctx context.Context, userID string, adID int64) (bool, error)
+	RemoveFavorite(ctx context.Context, userID string, adID int64) error
+	ListFavorites(ctx context.Context, userID string, limit int, offset int) ([]*domain.Favorite, error)
+}
+
+type mysqlFavoriteRepository struct {
+	db      *sql.DB
+	cache   cache.Cache
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlFavoriteRepository(db *sql.DB, cache cache.Cache, metrics *metrics.RepositoryMetrics) FavoriteRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlFavoriteRepository{
+		db:      db,
+		cache:   cache,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+// AddFavorite reports whether a new favorite was stored; favoriting an ad
+// twice is not an error.
+func (r *mysqlFavoriteRepository) AddFavorite(ctx context.Context, userID string, adID int64) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository AddFavorite")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("AddFavorite", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("AddFavorite", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx, "INSERT IGNORE INTO favorites (user_id, ad_id) VALUES (?, ?)", userID, adID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to insert favorite: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "duplicate"
+		return false, nil
+	}
+
+	r.invalidateAd(ctx, adID)
+	return true, nil
+}
+
+func (r *mysqlFavoriteRepository) RemoveFavorite(ctx context.Context, userID string, adID int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository RemoveFavorite")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("RemoveFavorite", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("RemoveFavorite", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM favorites WHERE user_id = ? AND ad_id = ?", userID, adID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete favorite: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	r.invalidateAd(ctx, adID)
+	return nil
+}
+
+func (r *mysqlFavoriteRepository) ListFavorites(ctx context.Context, userID string, limit int, offset int) ([]*domain.Favorite, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListFavorites")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListFavorites", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListFavorites", status).Observe(duration)
+	}()
+
+	query := `
+		SELECT f.favorited_at, ` + adColumns + `
+		FROM favorites f
+		JOIN ads ON ads.id = f.ad_id
+		WHERE f.user_id = ?
+		ORDER BY f.favorited_at DESC, f.ad_id DESC
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve favorites: %w", err)
+	}
+	defer rows.Close()
+
+	var favorites []*domain.Favorite
+	for rows.Next() {
+		var favoritedAt time.Time
+		ad, err := scanAd(prefixedScanner{row: rows, prefix: []interface{}{&favoritedAt}})
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan favorite: %w", err)
+		}
+		favorites = append(favorites, &domain.Favorite{AdID: ad.ID, FavoritedAt: favoritedAt, Ad: ad})
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return favorites, nil
+}
+
+func (r *mysqlFavoriteRepository) invalidateAd(ctx context.Context, adID int64) {
+	cacheSpanCtx, cacheSpan := r.tracer.Start(ctx, "Redis Delete")
+	r.cache.Delete(cacheSpanCtx, fmt.Sprintf("ad:%d", adID))
+	cacheSpan.End()
+}
+
+// prefixedScanner lets scanAd read rows that select extra columns in front
+// of adColumns.
+type prefixedScanner struct {
+	row    rowScanner
+	prefix []interface{}
+}
+
+func (s prefixedScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(s.prefix, dest...)...)
+}
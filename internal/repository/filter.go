@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func buildAdFilter(filter domain.AdFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Category != "" {
+		conditions = append(conditions, "category = ?")
+		args = append(args, filter.Category)
+	}
+
+	if filter.CampaignID > 0 {
+		conditions = append(conditions, "campaign_id = ?")
+		args = append(args, filter.CampaignID)
+	}
+
+	for _, attr := range filter.Attributes {
+		path := attributePath(attr.Name)
+		if attr.Value != "" {
+			conditions = append(conditions, "JSON_UNQUOTE(JSON_EXTRACT(attributes, ?)) = ?")
+			args = append(args, path, attr.Value)
+		}
+		if attr.Min != nil {
+			conditions = append(conditions, "CAST(JSON_EXTRACT(attributes, ?) AS DECIMAL(20, 6)) >= ?")
+			args = append(args, path, *attr.Min)
+		}
+		if attr.Max != nil {
+			conditions = append(conditions, "CAST(JSON_EXTRACT(attributes, ?) AS DECIMAL(20, 6)) <= ?")
+			args = append(args, path, *attr.Max)
+		}
+	}
+
+	if len(filter.Tags) > 0 {
+		tagQuery := "id IN (SELECT at.ad_id FROM ad_tags at JOIN tags t ON t.id = at.tag_id WHERE t.name IN (" + placeholders(len(filter.Tags)) + ")"
+		for _, tag := range filter.Tags {
+			args = append(args, tag)
+		}
+		if filter.TagMode == domain.TagMatchAll {
+			tagQuery += " GROUP BY at.ad_id HAVING COUNT(DISTINCT t.id) = ?"
+			args = append(args, len(filter.Tags))
+		}
+		conditions = append(conditions, tagQuery+")")
+	}
+
+	if filter.Audience != nil {
+		audienceConditions, audienceArgs := buildAudienceFilter(filter.Audience)
+		conditions = append(conditions, audienceConditions...)
+		args = append(args, audienceArgs...)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// buildAudienceFilter mirrors Targeting.Matches in SQL so that pages and
+// totals of a targeted listing agree. Every targeting criterion matches when
+// it is empty or when it holds the value of the viewer. Hours are taken in
+// the timezone of the ad, which needs the MySQL time zone tables; without
+// them CONVERT_TZ yields NULL and hours fall back to UTC, as unknown zones
+// do in Go.
+func buildAudienceFilter(audience *domain.AudienceContext) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	locales := []string{audience.Locale}
+	if i := strings.IndexByte(audience.Locale, '-'); i > 0 {
+		locales = append(locales, audience.Locale[:i])
+	}
+	for _, criterion := range []struct {
+		path   string
+		values []string
+	}{
+		{"$.locales", locales},
+		{"$.countries", []string{audience.Country}},
+		{"$.devices", []string{string(audience.Device)}},
+	} {
+		alternatives := []string{fmt.Sprintf("COALESCE(JSON_LENGTH(targeting, '%s'), 0) = 0", criterion.path)}
+		for _, value := range criterion.values {
+			if value != "" {
+				alternatives = append(alternatives, fmt.Sprintf("JSON_CONTAINS(targeting, JSON_QUOTE(?), '%s')", criterion.path))
+				args = append(args, value)
+			}
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+
+	// A window [start, end) contains the hour when the hour is less than
+	// the length of the window past its start, which covers windows that
+	// wrap around midnight.
+	at := audience.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	utc := at.UTC().Format("2006-01-02 15:04:05")
+	conditions = append(conditions, "(COALESCE(JSON_LENGTH(targeting, '$.hours'), 0) = 0 OR EXISTS ("+
+		"SELECT 1 FROM JSON_TABLE(targeting, '$.hours[*]' COLUMNS (start_hour INT PATH '$.start', end_hour INT PATH '$.end')) AS w "+
+		"WHERE MOD(HOUR(COALESCE(CONVERT_TZ(?, '+00:00', JSON_UNQUOTE(JSON_EXTRACT(targeting, '$.timezone'))), ?)) - w.start_hour + 24, 24) "+
+		"< MOD(w.end_hour - w.start_hour + 23, 24) + 1))")
+	args = append(args, utc, utc)
+
+	segments, _ := json.Marshal(audience.Segments)
+	conditions = append(conditions, "NOT EXISTS ("+
+		"SELECT 1 FROM JSON_TABLE(COALESCE(JSON_KEYS(targeting, '$.segments'), JSON_ARRAY()), '$[*]' COLUMNS (segment VARCHAR(64) PATH '$')) AS s "+
+		"WHERE NOT COALESCE(JSON_CONTAINS(JSON_EXTRACT(targeting, CONCAT('$.segments.\"', s.segment, '\"')), "+
+		"JSON_QUOTE(JSON_UNQUOTE(JSON_EXTRACT(?, CONCAT('$.\"', s.segment, '\"'))))), FALSE))")
+	args = append(args, string(segments))
+
+	return conditions, args
+}
+
+func attributePath(name string) string {
+	return fmt.Sprintf(`$."%s"`, name)
+}
+
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+)
+
+func TestBuildAudienceFilter(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		audience *domain.AudienceContext
+		args     []interface{}
+	}{
+		{
+			name:     "unknown viewer",
+			audience: &domain.AudienceContext{Time: at},
+			args:     []interface{}{"2026-10-18 09:30:00", "2026-10-18 09:30:00", "null"},
+		},
+		{
+			name: "regional locale",
+			audience: &domain.AudienceContext{
+				Locale:   "en-us",
+				Country:  "US",
+				Device:   domain.DeviceMobile,
+				Time:     at,
+				Segments: map[string]string{"plan": "pro"},
+			},
+			args: []interface{}{"en-us", "en", "US", "mobile", "2026-10-18 09:30:00", "2026-10-18 09:30:00", `{"plan":"pro"}`},
+		},
+		{
+			name:     "local time",
+			audience: &domain.AudienceContext{Locale: "tk", Time: at.In(time.FixedZone("TMT", 5*60*60))},
+			args:     []interface{}{"tk", "2026-10-18 09:30:00", "2026-10-18 09:30:00", "null"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions, args := buildAudienceFilter(tt.audience)
+			if len(conditions) != 5 {
+				t.Fatalf("got %d conditions, want one per criterion", len(conditions))
+			}
+			if placeholders := strings.Count(strings.Join(conditions, " "), "?"); placeholders != len(args) {
+				t.Errorf("conditions have %d placeholders for %d args", placeholders, len(args))
+			}
+			if !slices.Equal(args, tt.args) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestBuildAdFilterAudience(t *testing.T) {
+	where, _ := buildAdFilter(domain.AdFilter{Category: "cars"})
+	if strings.Contains(where, "targeting") {
+		t.Errorf("filter without an audience matches targeting: %s", where)
+	}
+	where, _ = buildAdFilter(domain.AdFilter{Category: "cars", Audience: &domain.AudienceContext{}})
+	if !strings.Contains(where, "targeting") {
+		t.Errorf("filter with an audience ignores targeting: %s", where)
+	}
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ImportRepository interface {
+	CreateJob(ctx context.Context, job *domain.ImportJob) error
+	GetJob(ctx context.Context, id string) (*domain.ImportJob, error)
+	UpdateJob(ctx context.Context, job *domain.ImportJob) error
+	AddRowErrors(ctx context.Context, jobID string, rowErrors []domain.ImportRowError) error
+	DeleteRowErrors(ctx context.Context, jobID string) error
+	StreamRowErrors(ctx context.Context, jobID string, fn func(domain.ImportRowError) error) error
+}
+
+type mysqlImportRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlImportRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) ImportRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlImportRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+const importJobColumns = "id, status, format, dry_run, total_rows, created_rows, updated_rows, failed_rows, error, created_at, started_at, finished_at"
+
+func scanImportJob(row rowScanner) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	var jobErr sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	if err := row.Scan(&job.ID, &job.Status, &job.Format, &job.DryRun, &job.Total, &job.Created, &job.Updated, &job.Failed, &jobErr, &job.CreatedAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	job.Error = jobErr.String
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+func (r *mysqlImportRepository) CreateJob(ctx context.Context, job *domain.ImportJob) error {
+	ctx, span := r.tracer.Start(ctx, "Repository CreateImportJob")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("import.id", job.ID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CreateImportJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CreateImportJob", status).Observe(duration)
+	}()
+
+	if _, err := r.db.ExecContext(ctx,
+		"INSERT INTO import_jobs (id, status, format, dry_run, created_at) VALUES (?, ?, ?, ?, ?)",
+		job.ID, job.Status, job.Format, job.DryRun, job.CreatedAt); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to insert import job: %w", err)
+	}
+	return nil
+}
+
+func (r *mysqlImportRepository) GetJob(ctx context.Context, id string) (*domain.ImportJob, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetImportJob")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("import.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetImportJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetImportJob", status).Observe(duration)
+	}()
+
+	job, err := scanImportJob(r.db.QueryRowContext(ctx, "SELECT "+importJobColumns+" FROM import_jobs WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			status = "not_found"
+			return nil, err
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return job, nil
+}
+
+// UpdateJob saves the status, counters and timestamps of a job.
+func (r *mysqlImportRepository) UpdateJob(ctx context.Context, job *domain.ImportJob) error {
+	ctx, span := r.tracer.Start(ctx, "Repository UpdateImportJob")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("import.id", job.ID),
+		attribute.String("import.status", string(job.Status)),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("UpdateImportJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("UpdateImportJob", status).Observe(duration)
+	}()
+
+	var jobErr interface{}
+	if job.Error != "" {
+		jobErr = job.Error
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE import_jobs
+		SET status = ?, total_rows = ?, created_rows = ?, updated_rows = ?, failed_rows = ?, error = ?, started_at = ?, finished_at = ?
+		WHERE id = ?`,
+		job.Status, job.Total, job.Created, job.Updated, job.Failed, jobErr, job.StartedAt, job.FinishedAt, job.ID); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+	return nil
+}
+
+func (r *mysqlImportRepository) AddRowErrors(ctx context.Context, jobID string, rowErrors []domain.ImportRowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+
+	ctx, span := r.tracer.Start(ctx, "Repository AddImportRowErrors")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("import.id", jobID),
+		attribute.Int("import.errors", len(rowErrors)),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("AddImportRowErrors", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("AddImportRowErrors", status).Observe(duration)
+	}()
+
+	args := make([]interface{}, 0, len(rowErrors)*5)
+	for _, rowErr := range rowErrors {
+		args = append(args, jobID, rowErr.Row, nullableString(rowErr.ExternalRef), rowErr.Field, rowErr.Message)
+	}
+	query := "INSERT INTO import_errors (job_id, row_num, external_ref, field, message) VALUES " +
+		strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?), ", len(rowErrors)), ", ")
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to insert import errors: %w", err)
+	}
+	return nil
+}
+
+// DeleteRowErrors removes the row errors of a job before it runs again.
+func (r *mysqlImportRepository) DeleteRowErrors(ctx context.Context, jobID string) error {
+	ctx, span := r.tracer.Start(ctx, "Repository DeleteImportRowErrors")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("import.id", jobID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeleteImportRowErrors", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeleteImportRowErrors", status).Observe(duration)
+	}()
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM import_errors WHERE job_id = ?", jobID); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete import errors: %w", err)
+	}
+	return nil
+}
+
+// StreamRowErrors calls fn for every row error of a job in row order.
+func (r *mysqlImportRepository) StreamRowErrors(ctx context.Context, jobID string, fn func(domain.ImportRowError) error) error {
+	ctx, span := r.tracer.Start(ctx, "Repository StreamImportRowErrors")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("import.id", jobID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("StreamImportRowErrors", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("StreamImportRowErrors", status).Observe(duration)
+	}()
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT row_num, external_ref, field, message FROM import_errors WHERE job_id = ? ORDER BY row_num, id", jobID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to query import errors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowErr domain.ImportRowError
+		var externalRef sql.NullString
+		if err := rows.Scan(&rowErr.Row, &externalRef, &rowErr.Field, &rowErr.Message); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return fmt.Errorf("failed to scan import error: %w", err)
+		}
+		rowErr.ExternalRef = externalRef.String
+		if err := fn(rowErr); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("rows error: %w", err)
+	}
+	return nil
+}
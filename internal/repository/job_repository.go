@@ -0,0 +1,613 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type JobRepository interface {
+	CreateJob(ctx context.Context, job *domain.Job) error
+	GetJob(ctx context.Context, id string) (*domain.Job, error)
+	ListJobs(ctx context.Context, filter domain.JobFilter, limit int) ([]*domain.Job, error)
+	ClaimJob(ctx context.Context, types []string, owner string, lease time.Duration) (*domain.Job, error)
+	Heartbeat(ctx context.Context, id string, owner string, progress domain.JobProgress, lease time.Duration) (cancelRequested bool, err error)
+	MarkSucceeded(ctx context.Context, id string, owner string, progress domain.JobProgress, result []byte) error
+	MarkFailed(ctx context.Context, id string, owner string, jobErr string, retryIn time.Duration, final bool) error
+	MarkCancelled(ctx context.Context, id string, owner string) error
+	Release(ctx context.Context, id string, owner string) error
+	CancelJob(ctx context.Context, id string) (*domain.Job, error)
+	RetryJob(ctx context.Context, id string) (*domain.Job, error)
+	DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error)
+}
+
+type mysqlJobRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlJobRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) JobRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlJobRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+const jobColumns = "id, type, status, payload, result, progress_done, progress_total, attempts, max_attempts, error, cancel_requested, run_at, created_at, updated_at, started_at, finished_at"
+
+func scanJob(row rowScanner) (*domain.Job, error) {
+	var job domain.Job
+	var payload, result []byte
+	var jobErr sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	if err := row.Scan(&job.ID, &job.Type, &job.Status, &payload, &result, &job.Progress.Done, &job.Progress.Total,
+		&job.Attempts, &job.MaxAttempts, &jobErr, &job.CancelRequested, &job.RunAt, &job.CreatedAt, &job.UpdatedAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	job.Result = result
+	job.Error = jobErr.String
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func (r *mysqlJobRepository) CreateJob(ctx context.Context, job *domain.Job) error {
+	ctx, span := r.tracer.Start(ctx, "Repository CreateJob")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("job.type", job.Type),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CreateJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CreateJob", status).Observe(duration)
+	}()
+
+	if _, err := r.db.ExecContext(ctx,
+		"INSERT INTO jobs (id, type, status, payload, max_attempts, run_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		job.ID, job.Type, job.Status, nullableJSON(job.Payload), job.MaxAttempts, job.RunAt, job.CreatedAt); err != nil {
+		if isDuplicateEntry(err) {
+			status = "conflict"
+			return ErrDuplicate
+		}
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+func (r *mysqlJobRepository) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetJob")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetJob", status).Observe(duration)
+	}()
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			status = "not_found"
+			return nil, err
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs returns the most recent jobs, optionally narrowed to one type and
+// one status.
+func (r *mysqlJobRepository) ListJobs(ctx context.Context, filter domain.JobFilter, limit int) ([]*domain.Job, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListJobs")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListJobs", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListJobs", status).Observe(duration)
+	}()
+
+	var conditions []string
+	var args []interface{}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	query := "SELECT " + jobColumns + " FROM jobs"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]*domain.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return jobs, nil
+}
+
+// ClaimJob leases the next due job of one of the types to owner, or returns
+// nil when there is none. Jobs whose lease expired are due again, so the job
+// of a worker that died is taken over; every claim counts as an attempt.
+// Rows locked by another instance are skipped.
+func (r *mysqlJobRepository) ClaimJob(ctx context.Context, types []string, owner string, lease time.Duration) (*domain.Job, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	ctx, span := r.tracer.Start(ctx, "Repository ClaimJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ClaimJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ClaimJob", status).Observe(duration)
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	args := make([]interface{}, 0, len(types)+2)
+	for _, jobType := range types {
+		args = append(args, jobType)
+	}
+	args = append(args, domain.JobPending, domain.JobRunning)
+
+	job, err := scanJob(tx.QueryRowContext(ctx, `
+		SELECT `+jobColumns+` FROM jobs
+		WHERE type IN (`+placeholders(len(types))+`)
+			AND ((status = ? AND run_at <= NOW()) OR (status = ? AND lease_expires_at < NOW()))
+		ORDER BY run_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve due job: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE jobs
+		SET status = ?, lease_owner = ?, lease_expires_at = NOW() + INTERVAL ? SECOND, attempts = attempts + 1, started_at = COALESCE(started_at, NOW())
+		WHERE id = ?`,
+		domain.JobRunning, owner, int64(lease.Seconds()), job.ID); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to lease job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	job.Status = domain.JobRunning
+	job.Attempts++
+	span.SetAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("job.type", job.Type),
+		attribute.Int("job.attempt", job.Attempts),
+	)
+	return job, nil
+}
+
+// Heartbeat extends the lease of a running job and saves its progress. It
+// reports whether the job should be cancelled, and ErrLeaseLost when owner
+// no longer holds the job.
+func (r *mysqlJobRepository) Heartbeat(ctx context.Context, id string, owner string, progress domain.JobProgress, lease time.Duration) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository JobHeartbeat")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("JobHeartbeat", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("JobHeartbeat", status).Observe(duration)
+	}()
+
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE jobs
+		SET lease_expires_at = NOW() + INTERVAL ? SECOND, progress_done = ?, progress_total = ?
+		WHERE id = ? AND lease_owner = ? AND status = ?`,
+		int64(lease.Seconds()), progress.Done, progress.Total, id, owner, domain.JobRunning); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to extend job lease: %w", err)
+	}
+
+	// MySQL counts changed rows rather than matched ones, so whether the
+	// lease is still held is read back.
+	var leaseOwner sql.NullString
+	var jobStatus domain.JobStatus
+	var cancelRequested bool
+	err := r.db.QueryRowContext(ctx, "SELECT lease_owner, status, cancel_requested FROM jobs WHERE id = ?", id).
+		Scan(&leaseOwner, &jobStatus, &cancelRequested)
+	if err == sql.ErrNoRows || (err == nil && (leaseOwner.String != owner || jobStatus != domain.JobRunning)) {
+		status = "lease_lost"
+		return false, ErrLeaseLost
+	}
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to read job lease: %w", err)
+	}
+	return cancelRequested, nil
+}
+
+func (r *mysqlJobRepository) MarkSucceeded(ctx context.Context, id string, owner string, progress domain.JobProgress, result []byte) error {
+	ctx, span := r.tracer.Start(ctx, "Repository MarkJobSucceeded")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("MarkJobSucceeded", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("MarkJobSucceeded", status).Observe(duration)
+	}()
+
+	err := r.finishAttempt(ctx, `
+		UPDATE jobs
+		SET status = ?, result = ?, error = NULL, progress_done = ?, progress_total = ?,
+			lease_owner = NULL, lease_expires_at = NULL, finished_at = NOW()
+		WHERE id = ? AND lease_owner = ? AND status = ?`,
+		domain.JobSucceeded, nullableJSON(result), progress.Done, progress.Total, id, owner, domain.JobRunning)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt. Unless final, the job is due again
+// after retryIn.
+func (r *mysqlJobRepository) MarkFailed(ctx context.Context, id string, owner string, jobErr string, retryIn time.Duration, final bool) error {
+	ctx, span := r.tracer.Start(ctx, "Repository MarkJobFailed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("job.id", id),
+		attribute.Bool("job.final", final),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("MarkJobFailed", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("MarkJobFailed", status).Observe(duration)
+	}()
+
+	var err error
+	if final {
+		err = r.finishAttempt(ctx, `
+			UPDATE jobs
+			SET status = ?, error = ?, lease_owner = NULL, lease_expires_at = NULL, finished_at = NOW()
+			WHERE id = ? AND lease_owner = ? AND status = ?`,
+			domain.JobFailed, jobErr, id, owner, domain.JobRunning)
+	} else {
+		err = r.finishAttempt(ctx, `
+			UPDATE jobs
+			SET status = ?, error = ?, lease_owner = NULL, lease_expires_at = NULL, run_at = NOW() + INTERVAL ? SECOND
+			WHERE id = ? AND lease_owner = ? AND status = ?`,
+			domain.JobPending, jobErr, int64(retryIn.Seconds()), id, owner, domain.JobRunning)
+	}
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (r *mysqlJobRepository) MarkCancelled(ctx context.Context, id string, owner string) error {
+	ctx, span := r.tracer.Start(ctx, "Repository MarkJobCancelled")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("MarkJobCancelled", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("MarkJobCancelled", status).Observe(duration)
+	}()
+
+	err := r.finishAttempt(ctx, `
+		UPDATE jobs
+		SET status = ?, lease_owner = NULL, lease_expires_at = NULL, finished_at = NOW()
+		WHERE id = ? AND lease_owner = ? AND status = ?`,
+		domain.JobCancelled, id, owner, domain.JobRunning)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// Release hands a running job back without counting the attempt, for
+// workers that stop before the job is done.
+func (r *mysqlJobRepository) Release(ctx context.Context, id string, owner string) error {
+	ctx, span := r.tracer.Start(ctx, "Repository ReleaseJob")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ReleaseJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ReleaseJob", status).Observe(duration)
+	}()
+
+	err := r.finishAttempt(ctx, `
+		UPDATE jobs
+		SET status = ?, attempts = GREATEST(attempts - 1, 0), lease_owner = NULL, lease_expires_at = NULL, run_at = NOW()
+		WHERE id = ? AND lease_owner = ? AND status = ?`,
+		domain.JobPending, id, owner, domain.JobRunning)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// finishAttempt runs an update guarded by the lease owner and returns
+// ErrLeaseLost when it matched nothing. Every such update changes the status,
+// so a matched row is always counted.
+func (r *mysqlJobRepository) finishAttempt(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// CancelJob cancels a pending job at once and asks the worker of a running
+// job to stop. Finished jobs cannot be cancelled.
+func (r *mysqlJobRepository) CancelJob(ctx context.Context, id string) (*domain.Job, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository CancelJob")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CancelJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CancelJob", status).Observe(duration)
+	}()
+
+	job, err := r.updateLocked(ctx, id, func(tx *sql.Tx, job *domain.Job) error {
+		switch job.Status {
+		case domain.JobPending:
+			_, err := tx.ExecContext(ctx, "UPDATE jobs SET status = ?, cancel_requested = TRUE, finished_at = NOW() WHERE id = ?", domain.JobCancelled, id)
+			return err
+		case domain.JobRunning:
+			_, err := tx.ExecContext(ctx, "UPDATE jobs SET cancel_requested = TRUE WHERE id = ?", id)
+			return err
+		}
+		return ErrJobStatus
+	})
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			status = "not_found"
+		case ErrJobStatus:
+			status = "conflict"
+		default:
+			status = "error"
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// RetryJob queues a failed or cancelled job again with a fresh set of
+// attempts.
+func (r *mysqlJobRepository) RetryJob(ctx context.Context, id string) (*domain.Job, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository RetryJob")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("RetryJob", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("RetryJob", status).Observe(duration)
+	}()
+
+	job, err := r.updateLocked(ctx, id, func(tx *sql.Tx, job *domain.Job) error {
+		if job.Status != domain.JobFailed && job.Status != domain.JobCancelled {
+			return ErrJobStatus
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE jobs
+			SET status = ?, attempts = 0, error = NULL, result = NULL, cancel_requested = FALSE, progress_done = 0, progress_total = 0,
+				run_at = NOW(), started_at = NULL, finished_at = NULL
+			WHERE id = ?`,
+			domain.JobPending, id)
+		return err
+	})
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			status = "not_found"
+		case ErrJobStatus:
+			status = "conflict"
+		default:
+			status = "error"
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// updateLocked runs update on a job locked for the transaction and returns
+// the job as it is afterwards.
+func (r *mysqlJobRepository) updateLocked(ctx context.Context, id string, update func(tx *sql.Tx, job *domain.Job) error) (*domain.Job, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	job, err := scanJob(tx.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = ? FOR UPDATE", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if err := update(tx, job); err != nil {
+		return nil, err
+	}
+
+	job, err = scanJob(tx.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = ?", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return job, nil
+}
+
+// DeleteFinishedJobs removes the jobs that finished before the cutoff.
+func (r *mysqlJobRepository) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository DeleteFinishedJobs")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeleteFinishedJobs", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeleteFinishedJobs", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM jobs WHERE status IN (?, ?, ?) AND finished_at < ?",
+		domain.JobSucceeded, domain.JobFailed, domain.JobCancelled, before)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	span.SetAttributes(attribute.Int64("jobs.deleted", deleted))
+	return deleted, nil
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const outboxRelayLockName = "ad_service_outbox_relay"
+
+type OutboxRepository interface {
+	FetchUnpublished(ctx context.Context, limit int) ([]*domain.OutboxMessage, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error)
+	AcquireRelayLock(ctx context.Context) (*RelayLock, error)
+}
+
+type mysqlOutboxRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlOutboxRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) OutboxRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlOutboxRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+// writeOutbox records an ad event inside the transaction that changes the
+// ad, so the event exists if and only if the change is committed.
+func writeOutbox(ctx context.Context, tx *sql.Tx, eventType domain.AdEventType, ad *domain.Ad) error {
+	payload, err := json.Marshal(&domain.AdEvent{
+		Type:       eventType,
+		AdID:       ad.ID,
+		Ad:         ad,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox event: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO outbox (aggregate_id, event_type, payload) VALUES (?, ?, ?)", ad.ID, eventType, string(payload)); err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return nil
+}
+
+// FetchUnpublished returns the oldest unpublished messages in id order. Ids
+// are assigned on insert, so a message whose transaction commits late can
+// follow messages with higher ids. Writes of one ad lock its row, which
+// keeps the messages of an ad in the order they were committed.
+func (r *mysqlOutboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository FetchUnpublished")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("FetchUnpublished", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("FetchUnpublished", status).Observe(duration)
+	}()
+
+	query := `
+		SELECT id, aggregate_id, event_type, payload, TIMESTAMPDIFF(MICROSECOND, created_at, NOW(6))
+		FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		var m domain.OutboxMessage
+		var ageMicros int64
+		if err := rows.Scan(&m.ID, &m.AggregateID, &m.EventType, &m.Payload, &ageMicros); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		m.Age = time.Duration(ageMicros) * time.Microsecond
+		messages = append(messages, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("outbox.count", len(messages)))
+	return messages, nil
+}
+
+func (r *mysqlOutboxRepository) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, span := r.tracer.Start(ctx, "Repository MarkPublished")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("outbox.count", len(ids)))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("MarkPublished", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("MarkPublished", status).Observe(duration)
+	}()
+
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	query := "UPDATE outbox SET published_at = NOW(6) WHERE id IN (" + placeholders(len(ids)) + ")"
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to mark outbox messages published: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mysqlOutboxRepository) DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository DeletePublished")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeletePublished", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeletePublished", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM outbox WHERE published_at IS NOT NULL AND published_at < NOW(6) - INTERVAL ? SECOND LIMIT 10000",
+		int64(olderThan.Seconds()))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to delete published outbox messages: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// RelayLock is a MySQL named lock held on a dedicated connection. Only the
+// instance holding it relays the outbox, which keeps events in order across
+// instances.
+type RelayLock struct {
+	conn *sql.Conn
+}
+
+// AcquireRelayLock returns nil without an error when another instance
+// holds the lock.
+func (r *mysqlOutboxRepository) AcquireRelayLock(ctx context.Context) (*RelayLock, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for relay lock: %w", err)
+	}
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", outboxRelayLockName).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire relay lock: %w", err)
+	}
+	if acquired.Int64 != 1 {
+		conn.Close()
+		return nil, nil
+	}
+
+	return &RelayLock{conn: conn}, nil
+}
+
+// Held reports whether the lock is still owned; it is lost together with
+// the connection.
+func (l *RelayLock) Held(ctx context.Context) bool {
+	var held sql.NullBool
+	err := l.conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", outboxRelayLockName).Scan(&held)
+	return err == nil && held.Valid && held.Bool
+}
+
+func (l *RelayLock) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	l.conn.ExecContext(ctx, "DO RELEASE_LOCK(?)", outboxRelayLockName)
+	l.conn.Close()
+}
@@ -7,7 +7,10 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -16,12 +19,200 @@ import (
 )
 
 type AdRepository interface {
-	GetAllAds(ctx context.Context, page int, pageSize int, sortBy string, sortOrder string) ([]*domain.Ad, error)
+	GetAllAds(ctx context.Context, page int, pageSize int, sortBy string, sortOrder string, filter domain.AdFilter) ([]*domain.Ad, error)
 	GetAdByID(ctx context.Context, id int64) (*domain.Ad, error)
+	GetAdsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Ad, error)
 	CreateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error)
 	UpdateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error)
 	DeleteAd(ctx context.Context, id int64) error
-	CountAds(ctx context.Context) (int, error)
+	CountAds(ctx context.Context, filter domain.AdFilter) (int, error)
+	PauseAdsByCampaign(ctx context.Context, campaignID int64, until *time.Time) ([]int64, error)
+	ResumeAdsByCampaign(ctx context.Context, campaignID int64) ([]int64, error)
+	GetServableAds(ctx context.Context, limit int) ([]*domain.Ad, error)
+	ImportAds(ctx context.Context, ads []*domain.Ad, dryRun bool) (created int, updated int, err error)
+	StreamAds(ctx context.Context, filter domain.AdFilter, fn func(*domain.Ad) error) error
+}
+
+// adFieldColumns maps the fields of domain.AdFieldNames to the expressions
+// that load them.
+var adFieldColumns = map[string]string{
+	"id":             "id",
+	"external_ref":   "external_ref",
+	"title":          "title",
+	"description":    "description",
+	"price":          "price",
+	"category":       "category",
+	"attributes":     "attributes",
+	"tags":           "(SELECT GROUP_CONCAT(t.name ORDER BY t.name SEPARATOR ',') FROM ad_tags at JOIN tags t ON t.id = at.tag_id WHERE at.ad_id = ads.id) AS tags",
+	"target_url":     "target_url",
+	"campaign_id":    "campaign_id",
+	"weight":         "weight",
+	"targeting":      "targeting",
+	"favorite_count": "(SELECT COUNT(*) FROM favorites fav WHERE fav.ad_id = ads.id) AS favorite_count",
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+	"active":         "active",
+}
+
+var adColumns = selectAdColumns(nil)
+
+// selectAdColumns returns the column list that loads fields, which scanAdFields
+// reads back. The subqueries of tags and favorite counts only run when
+// their field is selected.
+func selectAdColumns(fields []string) string {
+	selected := selectedAdFields(fields)
+	columns := make([]string, len(selected))
+	for i, field := range selected {
+		columns[i] = adFieldColumns[field]
+	}
+	return strings.Join(columns, ", ")
+}
+
+// selectedAdFields returns the known fields among fields and the id in
+// their encoding order, or every field when fields is empty.
+func selectedAdFields(fields []string) []string {
+	if len(fields) == 0 {
+		return domain.AdFieldNames
+	}
+	selected := make([]string, 0, len(fields)+1)
+	for _, field := range domain.AdFieldNames {
+		if field == "id" || slices.Contains(fields, field) {
+			selected = append(selected, field)
+		}
+	}
+	return selected
+}
+
+const insertAdQuery = "INSERT INTO ads (external_ref, title, description, price, category, attributes, target_url, campaign_id, weight, targeting, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+// updateAdQuery keeps the end of a budget pause while the ad stays in its
+// campaign. MySQL assigns left to right, so paused_until is compared with the
+// campaign before it changes.
+const updateAdQuery = `
+		UPDATE ads
+		SET paused_until = IF(campaign_id <=> ?, paused_until, NULL), external_ref = ?, title = ?, description = ?, price = ?, category = ?, attributes = ?, target_url = ?, campaign_id = ?, weight = ?, targeting = ?, active = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAd(row rowScanner) (*domain.Ad, error) {
+	return scanAdFields(row, nil)
+}
+
+// scanAdFields reads a row selected with selectAdColumns(fields). Fields
+// that were not selected keep their zero value.
+func scanAdFields(row rowScanner, fields []string) (*domain.Ad, error) {
+	var ad domain.Ad
+	var attributes []byte
+	var campaignID sql.NullInt64
+	var targeting []byte
+	var tags sql.NullString
+	var externalRef sql.NullString
+	targets := map[string]interface{}{
+		"id":             &ad.ID,
+		"external_ref":   &externalRef,
+		"title":          &ad.Title,
+		"description":    &ad.Description,
+		"price":          &ad.Price,
+		"category":       &ad.Category,
+		"attributes":     &attributes,
+		"tags":           &tags,
+		"target_url":     &ad.TargetURL,
+		"campaign_id":    &campaignID,
+		"weight":         &ad.Weight,
+		"targeting":      &targeting,
+		"favorite_count": &ad.FavoriteCount,
+		"created_at":     &ad.CreatedAt,
+		"updated_at":     &ad.UpdatedAt,
+		"active":         &ad.Active,
+	}
+	selected := selectedAdFields(fields)
+	dest := make([]interface{}, len(selected))
+	for i, field := range selected {
+		dest[i] = targets[field]
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	ad.ExternalRef = externalRef.String
+	if campaignID.Valid {
+		ad.CampaignID = &campaignID.Int64
+	}
+	if len(targeting) > 0 {
+		if err := json.Unmarshal(targeting, &ad.Targeting); err != nil {
+			return nil, fmt.Errorf("failed to decode ad targeting: %w", err)
+		}
+	}
+	if tags.Valid && tags.String != "" {
+		ad.Tags = strings.Split(tags.String, ",")
+	}
+	if len(attributes) > 0 {
+		if err := json.Unmarshal(attributes, &ad.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to decode ad attributes: %w", err)
+		}
+	}
+	return &ad, nil
+}
+
+// nullableString stores empty strings as NULL, so that unique columns
+// such as external_ref allow any number of ads without a value.
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func marshalTargeting(targeting *domain.Targeting) (interface{}, error) {
+	if targeting.IsEmpty() {
+		return nil, nil
+	}
+	data, err := json.Marshal(targeting)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ad targeting: %w", err)
+	}
+	return string(data), nil
+}
+
+func marshalAttributes(attributes map[string]interface{}) (interface{}, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ad attributes: %w", err)
+	}
+	return string(data), nil
+}
+
+// replaceAdTags makes the given tags the complete tag set of the ad,
+// creating missing tags on the way.
+func replaceAdTags(ctx context.Context, tx *sql.Tx, adID int64, tags []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM ad_tags WHERE ad_id = ?", adID); err != nil {
+		return fmt.Errorf("failed to clear ad tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(tags)+1)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+
+	insertTags := "INSERT IGNORE INTO tags (name) VALUES " + strings.TrimSuffix(strings.Repeat("(?), ", len(tags)), ", ")
+	if _, err := tx.ExecContext(ctx, insertTags, args...); err != nil {
+		return fmt.Errorf("failed to insert tags: %w", err)
+	}
+
+	linkTags := "INSERT INTO ad_tags (ad_id, tag_id) SELECT ?, id FROM tags WHERE name IN (" + placeholders(len(tags)) + ")"
+	if _, err := tx.ExecContext(ctx, linkTags, append([]interface{}{adID}, args...)...); err != nil {
+		return fmt.Errorf("failed to link ad tags: %w", err)
+	}
+	return nil
 }
 
 type mysqlAdRepository struct {
@@ -41,7 +232,7 @@ func NewMysqlAdRepository(db *sql.DB, cache cache.Cache, metrics *metrics.Reposi
 	}
 }
 
-func (r *mysqlAdRepository) GetAllAds(ctx context.Context, limit int, offset int, sortBy string, order string) ([]*domain.Ad, error) {
+func (r *mysqlAdRepository) GetAllAds(ctx context.Context, limit int, offset int, sortBy string, order string, filter domain.AdFilter) ([]*domain.Ad, error) {
 	ctx, span := r.tracer.Start(ctx, "Repository GetAllAds")
 	defer span.End()
 
@@ -54,7 +245,7 @@ func (r *mysqlAdRepository) GetAllAds(ctx context.Context, limit int, offset int
 		r.metrics.QueryDuration.WithLabelValues("GetAllAds", status).Observe(duration)
 	}()
 
-	isDefaultPagination := limit == 10 && offset == 0 && sortBy == "created_at" && order == "ASC"
+	isDefaultPagination := limit == 10 && offset == 0 && sortBy == "created_at" && order == "ASC" && filter.IsEmpty() && len(filter.Fields) == 0
 	cacheKey := "ads:default_page"
 
 	if isDefaultPagination {
@@ -70,13 +261,22 @@ func (r *mysqlAdRepository) GetAllAds(ctx context.Context, limit int, offset int
 		}
 	}
 
+	// The sort column is always loaded: favorite_count is computed in the
+	// select list, so ORDER BY can only name it when it is selected.
+	fields := filter.Fields
+	if len(fields) > 0 && !slices.Contains(fields, sortBy) {
+		fields = append(slices.Clip(fields), sortBy)
+	}
+
+	where, args := buildAdFilter(filter)
 	query := fmt.Sprintf(`
-		SELECT id, title, description, price, created_at, updated_at, active
+		SELECT %s
 		FROM ads
+		%s
 		ORDER BY %s %s
-		LIMIT ? OFFSET ?`, sortBy, order)
+		LIMIT ? OFFSET ?`, selectAdColumns(fields), where, sortBy, order)
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
 	if err != nil {
 		status = "error"
 		span.RecordError(err)
@@ -93,13 +293,13 @@ func (r *mysqlAdRepository) GetAllAds(ctx context.Context, limit int, offset int
 
 	var ads []*domain.Ad
 	for rows.Next() {
-		var ad domain.Ad
-		if err := rows.Scan(&ad.ID, &ad.Title, &ad.Description, &ad.Price, &ad.CreatedAt, &ad.UpdatedAt, &ad.Active); err != nil {
+		ad, err := scanAdFields(rows, fields)
+		if err != nil {
 			status = "error"
 			span.RecordError(err)
 			return nil, fmt.Errorf("failed to scan ad: %w", err)
 		}
-		ads = append(ads, &ad)
+		ads = append(ads, ad)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -139,24 +339,9 @@ func (r *mysqlAdRepository) GetAdByID(ctx context.Context, id int64) (*domain.Ad
 		}
 	}
 
-	query := `
-		SELECT id, title, description, price, created_at, updated_at, active 
-		FROM ads 
-		WHERE id = ?
-	`
-
-	ad := &domain.Ad{}
-
-	err = r.db.QueryRowContext(ctx, query, id).Scan(
-		&ad.ID,
-		&ad.Title,
-		&ad.Description,
-		&ad.Price,
-		&ad.CreatedAt,
-		&ad.UpdatedAt,
-		&ad.Active,
-	)
+	query := "SELECT " + adColumns + " FROM ads WHERE id = ?"
 
+	ad, err := scanAd(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
@@ -172,6 +357,61 @@ func (r *mysqlAdRepository) GetAdByID(ctx context.Context, id int64) (*domain.Ad
 	return ad, nil
 }
 
+// GetAdsByIDs loads several ads in one query, bypassing the per-ad cache.
+// Missing ads are absent from the result.
+func (r *mysqlAdRepository) GetAdsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Ad, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetAdsByIDs")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("ads.count", len(ids)))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetAdsByIDs", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetAdsByIDs", status).Observe(duration)
+	}()
+
+	ads := make(map[int64]*domain.Ad, len(ids))
+	if len(ids) == 0 {
+		return ads, nil
+	}
+
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	query := "SELECT " + adColumns + " FROM ads WHERE id IN (" + placeholders(len(ids)) + ")"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve ads: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ad, err := scanAd(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan ad: %w", err)
+		}
+		ads[ad.ID] = ad
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return ads, nil
+}
+
 func (r *mysqlAdRepository) CreateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error) {
 	ctx, span := r.tracer.Start(ctx, "Repository CreateAd")
 	defer span.End()
@@ -190,10 +430,36 @@ func (r *mysqlAdRepository) CreateAd(ctx context.Context, ad *domain.Ad) (*domai
 		r.metrics.QueryDuration.WithLabelValues("CreateAd", status).Observe(duration)
 	}()
 
-	result, err := r.db.ExecContext(ctx,
-		"INSERT INTO ads (title, description, price, active) VALUES (?, ?, ?, ?)",
-		ad.Title, ad.Description, ad.Price, ad.Active)
+	attributes, err := marshalAttributes(ad.Attributes)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	targeting, err := marshalTargeting(ad.Targeting)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		insertAdQuery,
+		nullableString(ad.ExternalRef), ad.Title, ad.Description, ad.Price, ad.Category, attributes, ad.TargetURL, ad.CampaignID, ad.Weight, targeting, ad.Active)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			status = "conflict"
+			return nil, ErrDuplicate
+		}
 		status = "error"
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to insert ad: %w", err)
@@ -206,23 +472,32 @@ func (r *mysqlAdRepository) CreateAd(ctx context.Context, ad *domain.Ad) (*domai
 		return nil, fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
-	var insertedAd domain.Ad
-	err = r.db.QueryRowContext(ctx, "SELECT id, title, description, price, active, created_at, updated_at FROM ads WHERE id = ?", id).Scan(
-		&insertedAd.ID,
-		&insertedAd.Title,
-		&insertedAd.Description,
-		&insertedAd.Price,
-		&insertedAd.Active,
-		&insertedAd.CreatedAt,
-		&insertedAd.UpdatedAt,
-	)
+	if err := replaceAdTags(ctx, tx, id, ad.Tags); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	insertedAd, err := scanAd(tx.QueryRowContext(ctx, "SELECT "+adColumns+" FROM ads WHERE id = ?", id))
 	if err != nil {
 		status = "error"
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to fetch inserted ad: %w", err)
 	}
 
-	return &insertedAd, nil
+	if err := writeOutbox(ctx, tx, domain.AdCreated, insertedAd); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return insertedAd, nil
 }
 
 func (r *mysqlAdRepository) UpdateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error) {
@@ -244,14 +519,45 @@ func (r *mysqlAdRepository) UpdateAd(ctx context.Context, ad *domain.Ad) (*domai
 		r.metrics.QueryDuration.WithLabelValues("UpdateAd", status).Observe(duration)
 	}()
 
-	query := `
-		UPDATE ads
-		SET title = ?, description = ?, price = ?, active = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
+	attributes, err := marshalAttributes(ad.Attributes)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	targeting, err := marshalTargeting(ad.Targeting)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
 
-	result, err := r.db.ExecContext(ctx, query, ad.Title, ad.Description, ad.Price, ad.Active, ad.ID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var wasActive bool
+	if err := tx.QueryRowContext(ctx, "SELECT active FROM ads WHERE id = ? FOR UPDATE", ad.ID).Scan(&wasActive); err != nil {
+		if err == sql.ErrNoRows {
+			status = "not_found"
+			return nil, err
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to lock ad: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, updateAdQuery, ad.CampaignID, nullableString(ad.ExternalRef), ad.Title, ad.Description, ad.Price, ad.Category, attributes, ad.TargetURL, ad.CampaignID, ad.Weight, targeting, ad.Active, ad.ID)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			status = "conflict"
+			return nil, ErrDuplicate
+		}
 		status = "error"
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to update ad: %w", err)
@@ -269,36 +575,52 @@ func (r *mysqlAdRepository) UpdateAd(ctx context.Context, ad *domain.Ad) (*domai
 		return nil, sql.ErrNoRows
 	}
 
-	cacheKey := fmt.Sprintf("ad:%d", ad.ID)
-
-	cacheSpanCtx, cacheSpan := r.tracer.Start(ctx, "Redis Delete")
-	r.cache.Delete(cacheSpanCtx, cacheKey)
-	cacheSpan.End()
+	if err := replaceAdTags(ctx, tx, ad.ID, ad.Tags); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
 
-	var updatedAd domain.Ad
-	err = r.db.QueryRowContext(ctx, "SELECT id, title, description, price, active, created_at, updated_at FROM ads WHERE id = ?", ad.ID).Scan(
-		&updatedAd.ID,
-		&updatedAd.Title,
-		&updatedAd.Description,
-		&updatedAd.Price,
-		&updatedAd.Active,
-		&updatedAd.CreatedAt,
-		&updatedAd.UpdatedAt,
-	)
+	updatedAd, err := scanAd(tx.QueryRowContext(ctx, "SELECT "+adColumns+" FROM ads WHERE id = ?", ad.ID))
 	if err != nil {
 		status = "error"
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to fetch updated ad: %w", err)
 	}
 
-	updatedAdJSON, err := json.Marshal(&updatedAd)
+	if err := writeOutbox(ctx, tx, domain.AdUpdated, updatedAd); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	if wasActive != updatedAd.Active {
+		if err := writeOutbox(ctx, tx, domain.AdStatusChanged, updatedAd); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	cacheKey := fmt.Sprintf("ad:%d", ad.ID)
+
+	cacheSpanCtx, cacheSpan := r.tracer.Start(ctx, "Redis Delete")
+	r.cache.Delete(cacheSpanCtx, cacheKey)
+	cacheSpan.End()
+
+	updatedAdJSON, err := json.Marshal(updatedAd)
 	if err == nil {
 		cacheSpanCtx, cacheSpan = r.tracer.Start(ctx, "Redis Set")
 		r.cache.Set(cacheSpanCtx, cacheKey, string(updatedAdJSON), 10*time.Minute)
 		cacheSpan.End()
 	}
 
-	return &updatedAd, nil
+	return updatedAd, nil
 }
 
 func (r *mysqlAdRepository) DeleteAd(ctx context.Context, id int64) error {
@@ -320,7 +642,32 @@ func (r *mysqlAdRepository) DeleteAd(ctx context.Context, id int64) error {
 		DELETE FROM ads WHERE id = ?
 	`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deletedAd, err := scanAd(tx.QueryRowContext(ctx, "SELECT "+adColumns+" FROM ads WHERE id = ? FOR UPDATE", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			status = "not_found"
+			return err
+		}
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to lock ad: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM favorites WHERE ad_id = ?", id); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete ad favorites: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
 		status = "error"
 		span.RecordError(err)
@@ -339,6 +686,18 @@ func (r *mysqlAdRepository) DeleteAd(ctx context.Context, id int64) error {
 		return sql.ErrNoRows
 	}
 
+	if err := writeOutbox(ctx, tx, domain.AdDeleted, deletedAd); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	cacheKey := fmt.Sprintf("ad:%d", id)
 
 	cacheSpanCtx, cacheSpan := r.tracer.Start(ctx, "Redis Delete")
@@ -348,7 +707,7 @@ func (r *mysqlAdRepository) DeleteAd(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (r *mysqlAdRepository) CountAds(ctx context.Context) (int, error) {
+func (r *mysqlAdRepository) CountAds(ctx context.Context, filter domain.AdFilter) (int, error) {
 	ctx, span := r.tracer.Start(ctx, "Repository CountAds")
 	defer span.End()
 
@@ -362,8 +721,9 @@ func (r *mysqlAdRepository) CountAds(ctx context.Context) (int, error) {
 	}()
 
 	var count int
-	query := "SELECT COUNT(*) FROM ads"
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	where, args := buildAdFilter(filter)
+	query := "SELECT COUNT(*) FROM ads " + where
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		status = "error"
 		span.RecordError(err)
@@ -371,3 +731,356 @@ func (r *mysqlAdRepository) CountAds(ctx context.Context) (int, error) {
 	}
 	return count, nil
 }
+
+// StreamAds calls fn for every ad matching the filter in id order. Rows are
+// read from the cursor one at a time, so memory does not grow with the
+// number of ads. An error from fn stops the iteration and is returned as is.
+func (r *mysqlAdRepository) StreamAds(ctx context.Context, filter domain.AdFilter, fn func(*domain.Ad) error) error {
+	ctx, span := r.tracer.Start(ctx, "Repository StreamAds")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("StreamAds", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("StreamAds", status).Observe(duration)
+	}()
+
+	where, args := buildAdFilter(filter)
+	query := "SELECT " + selectAdColumns(filter.Fields) + " FROM ads " + where + " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to stream ads: %w", err)
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		ad, err := scanAdFields(rows, filter.Fields)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return fmt.Errorf("failed to scan ad: %w", err)
+		}
+		if err := fn(ad); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return err
+		}
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("ads.count", count))
+	return nil
+}
+
+// ImportAds writes a batch of imported ads in one transaction. An ad whose
+// external reference is taken updates the ad holding it; every other ad is
+// inserted. A dry run rolls the transaction back, so that the counts report
+// what the import would have done.
+func (r *mysqlAdRepository) ImportAds(ctx context.Context, ads []*domain.Ad, dryRun bool) (int, int, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ImportAds")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("ads.count", len(ads)),
+		attribute.Bool("import.dry_run", dryRun),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ImportAds", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ImportAds", status).Observe(duration)
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var created int
+	var updatedIDs []int64
+	for _, ad := range ads {
+		attributes, err := marshalAttributes(ad.Attributes)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return 0, 0, err
+		}
+		targeting, err := marshalTargeting(ad.Targeting)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return 0, 0, err
+		}
+
+		var id int64
+		var wasActive, exists bool
+		if ad.ExternalRef != "" {
+			err := tx.QueryRowContext(ctx, "SELECT id, active FROM ads WHERE external_ref = ? FOR UPDATE", ad.ExternalRef).Scan(&id, &wasActive)
+			switch {
+			case err == nil:
+				exists = true
+			case !errors.Is(err, sql.ErrNoRows):
+				status = "error"
+				span.RecordError(err)
+				return 0, 0, fmt.Errorf("failed to look up external reference: %w", err)
+			}
+		}
+
+		eventType := domain.AdCreated
+		if exists {
+			eventType = domain.AdUpdated
+			if _, err := tx.ExecContext(ctx, updateAdQuery, ad.CampaignID, nullableString(ad.ExternalRef), ad.Title, ad.Description, ad.Price, ad.Category, attributes, ad.TargetURL, ad.CampaignID, ad.Weight, targeting, ad.Active, id); err != nil {
+				status = "error"
+				span.RecordError(err)
+				return 0, 0, fmt.Errorf("failed to update ad: %w", err)
+			}
+			updatedIDs = append(updatedIDs, id)
+		} else {
+			result, err := tx.ExecContext(ctx, insertAdQuery, nullableString(ad.ExternalRef), ad.Title, ad.Description, ad.Price, ad.Category, attributes, ad.TargetURL, ad.CampaignID, ad.Weight, targeting, ad.Active)
+			if err != nil {
+				status = "error"
+				span.RecordError(err)
+				return 0, 0, fmt.Errorf("failed to insert ad: %w", err)
+			}
+			if id, err = result.LastInsertId(); err != nil {
+				status = "error"
+				span.RecordError(err)
+				return 0, 0, fmt.Errorf("failed to get last insert id: %w", err)
+			}
+			created++
+		}
+
+		if err := replaceAdTags(ctx, tx, id, ad.Tags); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return 0, 0, err
+		}
+		if dryRun {
+			continue
+		}
+
+		stored, err := scanAd(tx.QueryRowContext(ctx, "SELECT "+adColumns+" FROM ads WHERE id = ?", id))
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return 0, 0, fmt.Errorf("failed to fetch imported ad: %w", err)
+		}
+		if err := writeOutbox(ctx, tx, eventType, stored); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return 0, 0, err
+		}
+		if exists && wasActive != stored.Active {
+			if err := writeOutbox(ctx, tx, domain.AdStatusChanged, stored); err != nil {
+				status = "error"
+				span.RecordError(err)
+				return 0, 0, err
+			}
+		}
+	}
+
+	if dryRun {
+		return created, len(updatedIDs), nil
+	}
+	if err := tx.Commit(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if len(updatedIDs) > 0 {
+		cacheSpanCtx, cacheSpan := r.tracer.Start(ctx, "Redis Delete")
+		for _, id := range updatedIDs {
+			r.cache.Delete(cacheSpanCtx, fmt.Sprintf("ad:%d", id))
+		}
+		cacheSpan.End()
+	}
+
+	return created, len(updatedIDs), nil
+}
+
+// PauseAdsByCampaign deactivates the active ads of the campaign. Ads paused
+// until a given time are resumed by ResumeAdsByCampaign once it has passed.
+func (r *mysqlAdRepository) PauseAdsByCampaign(ctx context.Context, campaignID int64, until *time.Time) ([]int64, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository PauseAdsByCampaign")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("campaign.id", campaignID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("PauseAdsByCampaign", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("PauseAdsByCampaign", status).Observe(duration)
+	}()
+
+	ids, err := r.setCampaignAdsActive(ctx, false, "campaign_id = ? AND active = TRUE", []interface{}{campaignID}, until)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("ads.paused", len(ids)))
+	return ids, nil
+}
+
+// ResumeAdsByCampaign reactivates the ads of the campaign whose pause has
+// run out. Ads paused without an end stay paused. paused_until is written
+// in UTC, so the current time is bound rather than taken from the session.
+func (r *mysqlAdRepository) ResumeAdsByCampaign(ctx context.Context, campaignID int64) ([]int64, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ResumeAdsByCampaign")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("campaign.id", campaignID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ResumeAdsByCampaign", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ResumeAdsByCampaign", status).Observe(duration)
+	}()
+
+	ids, err := r.setCampaignAdsActive(ctx, true, "campaign_id = ? AND active = FALSE AND paused_until <= ?", []interface{}{campaignID, time.Now().UTC()}, nil)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("ads.resumed", len(ids)))
+	return ids, nil
+}
+
+// setCampaignAdsActive switches the ads matching where on or off, writes a
+// status change to the outbox for each and returns their ids.
+func (r *mysqlAdRepository) setCampaignAdsActive(ctx context.Context, active bool, where string, args []interface{}, pausedUntil *time.Time) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM ads WHERE "+where+" FOR UPDATE", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select campaign ads: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan ad id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	updateArgs := make([]interface{}, 0, len(ids)+2)
+	updateArgs = append(updateArgs, active, pausedUntil)
+	for _, id := range ids {
+		updateArgs = append(updateArgs, id)
+	}
+	query := "UPDATE ads SET active = ?, paused_until = ?, updated_at = CURRENT_TIMESTAMP WHERE id IN (" + placeholders(len(ids)) + ")"
+	if _, err := tx.ExecContext(ctx, query, updateArgs...); err != nil {
+		return nil, fmt.Errorf("failed to update campaign ads: %w", err)
+	}
+
+	for _, id := range ids {
+		ad, err := scanAd(tx.QueryRowContext(ctx, "SELECT "+adColumns+" FROM ads WHERE id = ?", id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch updated ad: %w", err)
+		}
+		if err := writeOutbox(ctx, tx, domain.AdStatusChanged, ad); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, id := range ids {
+		cacheSpanCtx, cacheSpan := r.tracer.Start(ctx, "Redis Delete")
+		r.cache.Delete(cacheSpanCtx, fmt.Sprintf("ad:%d", id))
+		cacheSpan.End()
+	}
+
+	return ids, nil
+}
+
+func (r *mysqlAdRepository) GetServableAds(ctx context.Context, limit int) ([]*domain.Ad, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetServableAds")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("limit", limit))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetServableAds", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetServableAds", status).Observe(duration)
+	}()
+
+	query := "SELECT " + adColumns + " FROM ads WHERE active = TRUE AND weight > 0 ORDER BY weight DESC, id ASC LIMIT ?"
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve servable ads: %w", err)
+	}
+	defer rows.Close()
+
+	var ads []*domain.Ad
+	for rows.Next() {
+		ad, err := scanAd(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan ad: %w", err)
+		}
+		ads = append(ads, ad)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return ads, nil
+}
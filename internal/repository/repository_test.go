@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/cache"
+)
+
+type recordingCache struct {
+	cache.Cache
+
+	gets []string
+	sets []string
+}
+
+func (c *recordingCache) Get(ctx context.Context, key string) (string, error) {
+	c.gets = append(c.gets, key)
+	return "", errors.New("cache miss")
+}
+
+func (c *recordingCache) Set(ctx context.Context, key string, value string, expiration time.Duration) error {
+	c.sets = append(c.sets, key)
+	return nil
+}
+
+func (c *recordingCache) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+func TestGetAllAdsDefaultPageCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter domain.AdFilter
+		cached bool
+	}{
+		{name: "default page", cached: true},
+		{name: "category", filter: domain.AdFilter{Category: "cars"}},
+		{name: "fields", filter: domain.AdFilter{Fields: []string{"title"}}},
+		{name: "targeted", filter: domain.AdFilter{Audience: &domain.AudienceContext{Country: "US"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := openRecordingDB()
+			defer db.Close()
+			c := &recordingCache{}
+			repo := NewMysqlAdRepository(db, c, testMetrics)
+
+			if _, err := repo.GetAllAds(context.Background(), 10, 0, "created_at", "ASC", tt.filter); err != nil {
+				t.Fatalf("GetAllAds() error = %v", err)
+			}
+
+			if got := slices.Contains(c.gets, "ads:default_page"); got != tt.cached {
+				t.Errorf("read default page cache = %v, want %v", got, tt.cached)
+			}
+			if got := slices.Contains(c.sets, "ads:default_page"); got != tt.cached {
+				t.Errorf("wrote default page cache = %v, want %v", got, tt.cached)
+			}
+		})
+	}
+}
+
+func TestGetAllAdsLoadsSortColumn(t *testing.T) {
+	tests := []struct {
+		name   string
+		sortBy string
+		fields []string
+		column string
+	}{
+		{name: "all fields", sortBy: "favorite_count", column: "AS favorite_count"},
+		{name: "sort field selected", sortBy: "favorite_count", fields: []string{"favorite_count"}, column: "AS favorite_count"},
+		{name: "sort field left out", sortBy: "favorite_count", fields: []string{"id", "title"}, column: "AS favorite_count"},
+		{name: "plain column", sortBy: "price", fields: []string{"title"}, column: "price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, d := openRecordingDB()
+			defer db.Close()
+			repo := NewMysqlAdRepository(db, &recordingCache{}, testMetrics)
+
+			filter := domain.AdFilter{Fields: tt.fields}
+			if _, err := repo.GetAllAds(context.Background(), 10, 0, tt.sortBy, "DESC", filter); err != nil {
+				t.Fatalf("GetAllAds() error = %v", err)
+			}
+
+			if len(d.queries) != 1 {
+				t.Fatalf("ran %d queries, want 1", len(d.queries))
+			}
+			query := d.queries[0]
+			selectList := query[:strings.Index(query, "FROM ads")]
+			if !strings.Contains(selectList, tt.column) {
+				t.Errorf("select list %q does not load %q", selectList, tt.column)
+			}
+		})
+	}
+}
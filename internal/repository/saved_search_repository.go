@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type SavedSearchRepository interface {
+	ListSavedSearches(ctx context.Context, userID string) ([]*domain.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, search *domain.SavedSearch) (*domain.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, userID string, id int64) error
+	ListSearchesForCategory(ctx context.Context, category string) ([]*domain.SavedSearch, error)
+	RecordMatch(ctx context.Context, notification *domain.SearchNotification) (bool, error)
+	MarkNotified(ctx context.Context, searchID int64, adID int64) error
+	RecordNotifyFailure(ctx context.Context, searchID int64, adID int64) error
+	ListPendingMatches(ctx context.Context, matchedBefore time.Time, maxAttempts int, limit int) ([]*domain.SearchNotification, error)
+}
+
+type mysqlSavedSearchRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlSavedSearchRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) SavedSearchRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlSavedSearchRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+const savedSearchColumns = "id, user_id, name, query, filter, created_at"
+
+func scanSavedSearch(row rowScanner) (*domain.SavedSearch, error) {
+	var s domain.SavedSearch
+	var filter []byte
+	if err := row.Scan(&s.ID, &s.UserID, &s.Name, &s.Query, &filter, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &s.Filter); err != nil {
+		return nil, fmt.Errorf("failed to decode saved search filter: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *mysqlSavedSearchRepository) querySavedSearches(ctx context.Context, query string, args ...interface{}) ([]*domain.SavedSearch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []*domain.SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return searches, nil
+}
+
+func (r *mysqlSavedSearchRepository) ListSavedSearches(ctx context.Context, userID string) ([]*domain.SavedSearch, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListSavedSearches")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListSavedSearches", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListSavedSearches", status).Observe(duration)
+	}()
+
+	searches, err := r.querySavedSearches(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+// ListSearchesForCategory returns the saved searches an ad of the given
+// category can possibly match: those of that category and those without one.
+func (r *mysqlSavedSearchRepository) ListSearchesForCategory(ctx context.Context, category string) ([]*domain.SavedSearch, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListSearchesForCategory")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("category", category))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListSearchesForCategory", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListSearchesForCategory", status).Observe(duration)
+	}()
+
+	searches, err := r.querySavedSearches(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE category IN ('', ?) ORDER BY id", category)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (r *mysqlSavedSearchRepository) CreateSavedSearch(ctx context.Context, search *domain.SavedSearch) (*domain.SavedSearch, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository CreateSavedSearch")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("saved_search.name", search.Name))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CreateSavedSearch", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CreateSavedSearch", status).Observe(duration)
+	}()
+
+	filter, err := json.Marshal(search.Filter)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to encode saved search filter: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO saved_searches (user_id, name, query, category, filter) VALUES (?, ?, ?, ?, ?)",
+		search.UserID, search.Name, search.Query, search.Filter.Category, string(filter))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to insert saved search: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	created, err := scanSavedSearch(r.db.QueryRowContext(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE id = ?", id))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to fetch inserted saved search: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *mysqlSavedSearchRepository) DeleteSavedSearch(ctx context.Context, userID string, id int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository DeleteSavedSearch")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("saved_search.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeleteSavedSearch", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeleteSavedSearch", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM saved_searches WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordMatch stores the notification of a match as pending and reports
+// whether this is the first match of the ad for the search, so updates do
+// not notify twice. The match counts as notified once MarkNotified is
+// called for it.
+func (r *mysqlSavedSearchRepository) RecordMatch(ctx context.Context, notification *domain.SearchNotification) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository RecordMatch")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("saved_search.id", notification.SavedSearchID),
+		attribute.Int64("ad.id", notification.Ad.ID),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("RecordMatch", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("RecordMatch", status).Observe(duration)
+	}()
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to encode saved search notification: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT IGNORE INTO saved_search_matches (saved_search_id, ad_id, notification) VALUES (?, ?, ?)",
+		notification.SavedSearchID, notification.Ad.ID, string(payload))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to record saved search match: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "duplicate"
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (r *mysqlSavedSearchRepository) MarkNotified(ctx context.Context, searchID int64, adID int64) error {
+	return r.updateMatch(ctx, "MarkNotified", "UPDATE saved_search_matches SET notified_at = CURRENT_TIMESTAMP, attempts = attempts + 1 WHERE saved_search_id = ? AND ad_id = ?", searchID, adID)
+}
+
+func (r *mysqlSavedSearchRepository) RecordNotifyFailure(ctx context.Context, searchID int64, adID int64) error {
+	return r.updateMatch(ctx, "RecordNotifyFailure", "UPDATE saved_search_matches SET attempts = attempts + 1 WHERE saved_search_id = ? AND ad_id = ?", searchID, adID)
+}
+
+func (r *mysqlSavedSearchRepository) updateMatch(ctx context.Context, name string, query string, searchID int64, adID int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository "+name)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("saved_search.id", searchID),
+		attribute.Int64("ad.id", adID),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues(name, status).Inc()
+		r.metrics.QueryDuration.WithLabelValues(name, status).Observe(duration)
+	}()
+
+	if _, err := r.db.ExecContext(ctx, query, searchID, adID); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to update saved search match: %w", err)
+	}
+	return nil
+}
+
+// ListPendingMatches returns the notifications of matches recorded before
+// matchedBefore that were not delivered in fewer than maxAttempts
+// attempts, oldest first.
+func (r *mysqlSavedSearchRepository) ListPendingMatches(ctx context.Context, matchedBefore time.Time, maxAttempts int, limit int) ([]*domain.SearchNotification, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListPendingMatches")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListPendingMatches", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListPendingMatches", status).Observe(duration)
+	}()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT notification
+		FROM saved_search_matches
+		WHERE notified_at IS NULL AND notification IS NOT NULL AND matched_at < ? AND attempts < ?
+		ORDER BY matched_at
+		LIMIT ?`, matchedBefore.UTC(), maxAttempts, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve pending saved search matches: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*domain.SearchNotification
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan saved search match: %w", err)
+		}
+		var notification domain.SearchNotification
+		if err := json.Unmarshal(payload, &notification); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to decode saved search notification: %w", err)
+		}
+		notifications = append(notifications, &notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return notifications, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TagRepository interface {
+	ListTagCounts(ctx context.Context, limit int) ([]*domain.TagCount, error)
+}
+
+type mysqlTagRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlTagRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) TagRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlTagRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (r *mysqlTagRepository) ListTagCounts(ctx context.Context, limit int) ([]*domain.TagCount, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListTagCounts")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("limit", limit))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListTagCounts", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListTagCounts", status).Observe(duration)
+	}()
+
+	query := `
+		SELECT t.name, COUNT(*) AS usage_count
+		FROM tags t
+		JOIN ad_tags at ON at.tag_id = t.id
+		GROUP BY t.id, t.name
+		ORDER BY usage_count DESC, t.name ASC
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve tag counts: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*domain.TagCount
+	for rows.Next() {
+		var tag domain.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan tag count: %w", err)
+		}
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TrackingRepository interface {
+	InsertEvents(ctx context.Context, events []*domain.TrackingEvent) error
+	GetDailyStats(ctx context.Context, adID int64, since time.Time) ([]*domain.DailyAdStats, error)
+}
+
+type mysqlTrackingRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlTrackingRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) TrackingRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlTrackingRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+func (r *mysqlTrackingRepository) InsertEvents(ctx context.Context, events []*domain.TrackingEvent) error {
+	ctx, span := r.tracer.Start(ctx, "Repository InsertEvents")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("events.count", len(events)))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("InsertEvents", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("InsertEvents", status).Observe(duration)
+	}()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*4)
+	for _, event := range events {
+		values = append(values, "(?, ?, ?, ?)")
+		args = append(args, event.AdID, event.Type, event.ViewerID, event.OccurredAt)
+	}
+
+	// IGNORE skips the events of ads deleted since they were tracked instead
+	// of failing the whole batch on the foreign key.
+	query := "INSERT IGNORE INTO ad_events (ad_id, event_type, viewer_id, occurred_at) VALUES " + strings.Join(values, ", ")
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to insert ad events: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mysqlTrackingRepository) GetDailyStats(ctx context.Context, adID int64, since time.Time) ([]*domain.DailyAdStats, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetDailyStats")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetDailyStats", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetDailyStats", status).Observe(duration)
+	}()
+
+	query := `
+		SELECT DATE(occurred_at) AS day,
+			SUM(event_type = 'impression') AS impressions,
+			SUM(event_type = 'click') AS clicks
+		FROM ad_events
+		WHERE ad_id = ? AND occurred_at >= ?
+		GROUP BY day
+		ORDER BY day`
+
+	rows, err := r.db.QueryContext(ctx, query, adID, since)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve ad stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*domain.DailyAdStats
+	for rows.Next() {
+		var day time.Time
+		var daily domain.DailyAdStats
+		if err := rows.Scan(&day, &daily.Impressions, &daily.Clicks); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan ad stats: %w", err)
+		}
+		daily.Day = day.Format("2006-01-02")
+		daily.CTR = domain.ClickThroughRate(daily.Impressions, daily.Clicks)
+		stats = append(stats, &daily)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type VariantRepository interface {
+	ListVariants(ctx context.Context, adID int64) ([]*domain.Variant, error)
+	CreateVariant(ctx context.Context, variant *domain.Variant) (*domain.Variant, error)
+	DeleteVariant(ctx context.Context, adID int64, id int64) error
+	IncrementCounter(ctx context.Context, adID int64, id int64, eventType domain.TrackingEventType) error
+}
+
+type mysqlVariantRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlVariantRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) VariantRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlVariantRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+const variantColumns = "id, ad_id, name, title, description, weight, impressions, clicks, created_at"
+
+func scanVariant(row rowScanner) (*domain.Variant, error) {
+	var v domain.Variant
+	if err := row.Scan(&v.ID, &v.AdID, &v.Name, &v.Title, &v.Description, &v.Weight, &v.Impressions, &v.Clicks, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *mysqlVariantRepository) ListVariants(ctx context.Context, adID int64) ([]*domain.Variant, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListVariants")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListVariants", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListVariants", status).Observe(duration)
+	}()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+variantColumns+" FROM ad_variants WHERE ad_id = ? ORDER BY id", adID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []*domain.Variant
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan variant: %w", err)
+		}
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return variants, nil
+}
+
+func (r *mysqlVariantRepository) CreateVariant(ctx context.Context, variant *domain.Variant) (*domain.Variant, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository CreateVariant")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", variant.AdID),
+		attribute.String("variant.name", variant.Name),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CreateVariant", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CreateVariant", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO ad_variants (ad_id, name, title, description, weight) VALUES (?, ?, ?, ?, ?)",
+		variant.AdID, variant.Name, variant.Title, variant.Description, variant.Weight)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			status = "conflict"
+			return nil, ErrDuplicate
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to insert variant: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	created, err := scanVariant(r.db.QueryRowContext(ctx, "SELECT "+variantColumns+" FROM ad_variants WHERE id = ?", id))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to fetch inserted variant: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *mysqlVariantRepository) DeleteVariant(ctx context.Context, adID int64, id int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository DeleteVariant")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.Int64("variant.id", id),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeleteVariant", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeleteVariant", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM ad_variants WHERE id = ? AND ad_id = ?", id, adID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete variant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *mysqlVariantRepository) IncrementCounter(ctx context.Context, adID int64, id int64, eventType domain.TrackingEventType) error {
+	ctx, span := r.tracer.Start(ctx, "Repository IncrementCounter")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.Int64("variant.id", id),
+		attribute.String("event.type", string(eventType)),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("IncrementCounter", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("IncrementCounter", status).Observe(duration)
+	}()
+
+	var query string
+	switch eventType {
+	case domain.TrackingImpression:
+		query = "UPDATE ad_variants SET impressions = impressions + 1 WHERE id = ? AND ad_id = ?"
+	case domain.TrackingClick:
+		query = "UPDATE ad_variants SET clicks = clicks + 1 WHERE id = ? AND ad_id = ?"
+	default:
+		status = "error"
+		return fmt.Errorf("unsupported variant event type %q", eventType)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, id, adID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to increment variant counter: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
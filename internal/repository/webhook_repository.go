@@ -0,0 +1,546 @@
+package repository
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WebhookRepository interface {
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	GetSubscriptionByID(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	EnqueueDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	MarkFailed(ctx context.Context, id int64, statusCode int, deliveryErr string, retryIn time.Duration, dead bool) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id int64) error
+}
+
+type mysqlWebhookRepository struct {
+	db      *sql.DB
+	metrics *metrics.RepositoryMetrics
+	tracer  trace.Tracer
+}
+
+func NewMysqlWebhookRepository(db *sql.DB, metrics *metrics.RepositoryMetrics) WebhookRepository {
+	tracer := otel.Tracer("ad-service/repository")
+	return &mysqlWebhookRepository{
+		db:      db,
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+const (
+	subscriptionColumns = "id, url, secret, events, active, created_at"
+	deliveryColumns     = "id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at"
+)
+
+func scanSubscription(row rowScanner) (*domain.WebhookSubscription, error) {
+	var s domain.WebhookSubscription
+	var events []byte
+	if err := row.Scan(&s.ID, &s.URL, &s.Secret, &events, &s.Active, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &s.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook events: %w", err)
+	}
+	return &s, nil
+}
+
+func scanDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var payload []byte
+	var statusCode sql.NullInt64
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+	var eventID sql.NullInt64
+	if err := row.Scan(&d.ID, &d.SubscriptionID, &eventID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &statusCode, &lastError, &d.CreatedAt, &deliveredAt); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	d.EventID = eventID.Int64
+	d.LastStatusCode = int(statusCode.Int64)
+	d.LastError = lastError.String
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return &d, nil
+}
+
+func (r *mysqlWebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListSubscriptions")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListSubscriptions", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListSubscriptions", status).Observe(duration)
+	}()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*domain.WebhookSubscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *mysqlWebhookRepository) GetSubscriptionByID(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository GetSubscriptionByID")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("webhook.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("GetSubscriptionByID", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("GetSubscriptionByID", status).Observe(duration)
+	}()
+
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscriptions WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			status = "not_found"
+			return nil, err
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (r *mysqlWebhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository CreateSubscription")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("CreateSubscription", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("CreateSubscription", status).Observe(duration)
+	}()
+
+	events := subscription.Events
+	if events == nil {
+		events = []domain.AdEventType{}
+	}
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO webhook_subscriptions (url, secret, events, active) VALUES (?, ?, ?, ?)",
+		subscription.URL, subscription.Secret, string(eventsJSON), subscription.Active)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	created, err := scanSubscription(r.db.QueryRowContext(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscriptions WHERE id = ?", id))
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to fetch inserted webhook subscription: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("webhook.id", created.ID))
+	return created, nil
+}
+
+func (r *mysqlWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository DeleteSubscription")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("webhook.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("DeleteSubscription", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("DeleteSubscription", status).Observe(duration)
+	}()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = ?", id)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// nullableID stores unset ids as NULL.
+func nullableID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// EnqueueDeliveries stores pending deliveries. Enqueueing the deliveries of
+// an event again is a no-op, so events relayed more than once are
+// delivered once.
+func (r *mysqlWebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ctx, span := r.tracer.Start(ctx, "Repository EnqueueDeliveries")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("deliveries.count", len(deliveries)))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("EnqueueDeliveries", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("EnqueueDeliveries", status).Observe(duration)
+	}()
+
+	values := make([]string, 0, len(deliveries))
+	args := make([]interface{}, 0, len(deliveries)*3)
+	for _, d := range deliveries {
+		values = append(values, "(?, ?, ?, ?)")
+		args = append(args, d.SubscriptionID, nullableID(d.EventID), d.EventType, string(d.Payload))
+	}
+
+	// Deliveries of an event that was relayed before are kept as they are.
+	query := "INSERT IGNORE INTO webhook_deliveries (subscription_id, event_id, event_type, payload) VALUES " + strings.Join(values, ", ")
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries leases pending deliveries whose next attempt is due by
+// pushing their next attempt past the lease. Rows locked by another instance
+// are skipped, and a delivery whose worker dies is retried once the lease
+// runs out.
+func (r *mysqlWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ClaimDueDeliveries")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ClaimDueDeliveries", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ClaimDueDeliveries", status).Observe(duration)
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= NOW() ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED",
+		domain.DeliveryPending, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve due webhook deliveries: %w", err)
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			rows.Close()
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(deliveries)+1)
+	args = append(args, int64(lease.Seconds()))
+	for _, d := range deliveries {
+		args = append(args, d.ID)
+	}
+
+	query := "UPDATE webhook_deliveries SET next_attempt_at = NOW() + INTERVAL ? SECOND WHERE id IN (" + placeholders(len(deliveries)) + ")"
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to lease webhook deliveries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("deliveries.count", len(deliveries)))
+	return deliveries, nil
+}
+
+func (r *mysqlWebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	ctx, span := r.tracer.Start(ctx, "Repository MarkDelivered")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("delivery.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("MarkDelivered", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("MarkDelivered", status).Observe(duration)
+	}()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, last_status_code = ?, last_error = NULL, delivered_at = NOW()
+		WHERE id = ?
+	`
+	if _, err := r.db.ExecContext(ctx, query, domain.DeliveryDelivered, statusCode, id); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to mark webhook delivery delivered: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mysqlWebhookRepository) MarkFailed(ctx context.Context, id int64, statusCode int, deliveryErr string, retryIn time.Duration, dead bool) error {
+	ctx, span := r.tracer.Start(ctx, "Repository MarkFailed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("delivery.id", id),
+		attribute.Bool("delivery.dead", dead),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("MarkFailed", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("MarkFailed", status).Observe(duration)
+	}()
+
+	deliveryStatus := domain.DeliveryPending
+	if dead {
+		deliveryStatus = domain.DeliveryDead
+	}
+
+	var code interface{}
+	if statusCode > 0 {
+		code = statusCode
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, last_status_code = ?, last_error = ?, next_attempt_at = NOW() + INTERVAL ? SECOND
+		WHERE id = ?
+	`
+	if _, err := r.db.ExecContext(ctx, query, deliveryStatus, code, deliveryErr, int64(retryIn.Seconds()), id); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to mark webhook delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the most recent deliveries, optionally narrowed to
+// one subscription and one status.
+func (r *mysqlWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, deliveryStatus domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository ListDeliveries")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("webhook.id", subscriptionID),
+		attribute.String("delivery.status", string(deliveryStatus)),
+	)
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("ListDeliveries", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("ListDeliveries", status).Observe(duration)
+	}()
+
+	var conditions []string
+	var args []interface{}
+	if subscriptionID > 0 {
+		conditions = append(conditions, "subscription_id = ?")
+		args = append(args, subscriptionID)
+	}
+	if deliveryStatus != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, deliveryStatus)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+deliveryColumns+" FROM webhook_deliveries "+where+" ORDER BY id DESC LIMIT ?", args...)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to retrieve webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver puts a delivery back into the queue with a fresh retry budget.
+func (r *mysqlWebhookRepository) Redeliver(ctx context.Context, id int64) error {
+	ctx, span := r.tracer.Start(ctx, "Repository Redeliver")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("delivery.id", id))
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.QueryCount.WithLabelValues("Redeliver", status).Inc()
+		r.metrics.QueryDuration.WithLabelValues("Redeliver", status).Observe(duration)
+	}()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE id = ?
+	`
+	result, err := r.db.ExecContext(ctx, query, domain.DeliveryPending, id)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to requeue webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		status = "not_found"
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrAttributeNotFound = errors.New("attribute definition not found")
+	ErrAttributeExists   = errors.New("attribute definition already exists")
+)
+
+type AttributeService interface {
+	ListDefinitions(ctx context.Context, category string) ([]*domain.AttributeDefinition, error)
+	CreateDefinition(ctx context.Context, def *domain.AttributeDefinition) (*domain.AttributeDefinition, error)
+	DeleteDefinition(ctx context.Context, category string, id int64) error
+}
+
+type attributeService struct {
+	repository repository.AttributeRepository
+	metrics    *metrics.ServiceMetrics
+	tracer     trace.Tracer
+}
+
+func NewAttributeService(repository repository.AttributeRepository, metrics *metrics.ServiceMetrics) AttributeService {
+	tracer := otel.Tracer("ad-service/service")
+	return &attributeService{
+		repository: repository,
+		metrics:    metrics,
+		tracer:     tracer,
+	}
+}
+
+func (s *attributeService) ListDefinitions(ctx context.Context, category string) ([]*domain.AttributeDefinition, error) {
+	ctx, span := s.tracer.Start(ctx, "Service ListDefinitions")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListDefinitions", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListDefinitions", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.String("category", category))
+
+	defs, err := s.repository.ListDefinitions(ctx, category)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	return defs, nil
+}
+
+func (s *attributeService) CreateDefinition(ctx context.Context, def *domain.AttributeDefinition) (*domain.AttributeDefinition, error) {
+	ctx, span := s.tracer.Start(ctx, "Service CreateDefinition")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("CreateDefinition", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("CreateDefinition", status).Observe(duration)
+	}()
+
+	if err := validateAttributeDefinition(def); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	created, err := s.repository.CreateDefinition(ctx, def)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			status = "conflict"
+			span.SetAttributes(attribute.String("error", "attribute definition already exists"))
+			return nil, ErrAttributeExists
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int64("attribute.id", created.ID),
+		attribute.String("attribute.name", created.Name),
+	)
+	return created, nil
+}
+
+func (s *attributeService) DeleteDefinition(ctx context.Context, category string, id int64) error {
+	if id <= 0 {
+		return ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service DeleteDefinition")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("DeleteDefinition", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("DeleteDefinition", status).Observe(duration)
+	}()
+
+	err := s.repository.DeleteDefinition(ctx, category, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "attribute definition not found"))
+			return ErrAttributeNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int64("attribute.id", id))
+	return nil
+}
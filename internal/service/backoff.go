@@ -0,0 +1,19 @@
+package service
+
+import (
+	mathrand "math/rand"
+	"time"
+)
+
+// expBackoff doubles initial with every attempt up to max and adds up to 20%
+// jitter so failing work is not retried in lockstep.
+func expBackoff(initial, max time.Duration, attempts int) time.Duration {
+	delay := initial
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay + time.Duration(mathrand.Int63n(int64(delay)/5+1))
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		base     time.Duration
+	}{
+		{attempts: 1, base: 10 * time.Second},
+		{attempts: 2, base: 20 * time.Second},
+		{attempts: 3, base: 40 * time.Second},
+		{attempts: 4, base: time.Minute},
+		{attempts: 50, base: time.Minute},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			delay := expBackoff(10*time.Second, time.Minute, tt.attempts)
+			if delay < tt.base || delay > tt.base+tt.base/5 {
+				t.Fatalf("expBackoff(%d) = %s, want between %s and %s", tt.attempts, delay, tt.base, tt.base+tt.base/5)
+			}
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+// ResumeCampaignAdsJobType is the type of the background jobs that resume
+// the ads of a campaign paused for its daily budget.
+const ResumeCampaignAdsJobType = "campaigns.resume_ads"
+
+const resumeCampaignAdsAttempts = 5
+
+type resumeCampaignAdsPayload struct {
+	CampaignID int64 `json:"campaign_id"`
+}
+
+// BillingOptions holds the prices charged to a campaign per click and per
+// thousand impressions.
+type BillingOptions struct {
+	CPC float64
+	CPM float64
+}
+
+type CampaignService interface {
+	ListCampaigns(ctx context.Context, limit int, offset int) ([]*domain.Campaign, error)
+	GetCampaignByID(ctx context.Context, id int64) (*domain.Campaign, error)
+	CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error)
+	UpdateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error)
+	DeleteCampaign(ctx context.Context, id int64) error
+	RecordSpend(ctx context.Context, events []*domain.TrackingEvent) error
+}
+
+type campaignService struct {
+	repository repository.CampaignRepository
+	ads        AdService
+	jobs       JobService
+	billing    BillingOptions
+	metrics    *metrics.ServiceMetrics
+	tracer     trace.Tracer
+}
+
+func NewCampaignService(repository repository.CampaignRepository, ads AdService, jobs JobService, billing BillingOptions, metrics *metrics.ServiceMetrics) CampaignService {
+	tracer := otel.Tracer("ad-service/service")
+	s := &campaignService{
+		repository: repository,
+		ads:        ads,
+		jobs:       jobs,
+		billing:    billing,
+		metrics:    metrics,
+		tracer:     tracer,
+	}
+	if jobs != nil {
+		jobs.Register(ResumeCampaignAdsJobType, s.runResumeJob, resumeCampaignAdsAttempts)
+	}
+	return s
+}
+
+func (s *campaignService) ListCampaigns(ctx context.Context, limit int, offset int) ([]*domain.Campaign, error) {
+	ctx, span := s.tracer.Start(ctx, "Service ListCampaigns")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListCampaigns", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListCampaigns", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int("campaigns.limit", limit),
+		attribute.Int("campaigns.offset", offset),
+	)
+
+	campaigns, err := s.repository.ListCampaigns(ctx, limit, offset)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+func (s *campaignService) GetCampaignByID(ctx context.Context, id int64) (*domain.Campaign, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service GetCampaignByID")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("GetCampaignByID", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("GetCampaignByID", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("campaign.id", id))
+
+	campaign, err := s.repository.GetCampaignByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "campaign not found"))
+			return nil, ErrCampaignNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func (s *campaignService) CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error) {
+	ctx, span := s.tracer.Start(ctx, "Service CreateCampaign")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("CreateCampaign", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("CreateCampaign", status).Observe(duration)
+	}()
+
+	if err := validateCampaign(campaign); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	created, err := s.repository.CreateCampaign(ctx, campaign)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("campaign.id", created.ID))
+	return created, nil
+}
+
+func (s *campaignService) UpdateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error) {
+	if campaign.ID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service UpdateCampaign")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("UpdateCampaign", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("UpdateCampaign", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("campaign.id", campaign.ID))
+
+	if err := validateCampaign(campaign); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	updated, err := s.repository.UpdateCampaign(ctx, campaign)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "campaign not found"))
+			return nil, ErrCampaignNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// A lowered budget may already be spent.
+	if err := s.enforceBudget(ctx, updated.ID); err != nil {
+		span.RecordError(err)
+	}
+
+	return updated, nil
+}
+
+func (s *campaignService) DeleteCampaign(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service DeleteCampaign")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("DeleteCampaign", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("DeleteCampaign", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("campaign.id", id))
+
+	err := s.repository.DeleteCampaign(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "campaign not found"))
+			return ErrCampaignNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// RecordSpend charges the campaigns of the given ads for tracked clicks and
+// impressions and pauses the ads of every campaign whose budget ran out.
+// Events are charged once by their id, so a batch can be recorded again.
+func (s *campaignService) RecordSpend(ctx context.Context, events []*domain.TrackingEvent) error {
+	ctx, span := s.tracer.Start(ctx, "Service RecordSpend")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("RecordSpend", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("RecordSpend", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int("events.count", len(events)))
+
+	adIDs := make([]int64, 0, len(events))
+	seen := make(map[int64]struct{}, len(events))
+	for _, event := range events {
+		if _, ok := seen[event.AdID]; !ok {
+			seen[event.AdID] = struct{}{}
+			adIDs = append(adIDs, event.AdID)
+		}
+	}
+
+	adCampaigns, err := s.repository.GetAdCampaigns(ctx, adIDs)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+
+	type spendKey struct {
+		campaignID int64
+		day        time.Time
+	}
+	spend := make(map[spendKey]map[string]float64)
+	for _, event := range events {
+		campaignID, ok := adCampaigns[event.AdID]
+		if !ok {
+			continue
+		}
+		var price float64
+		switch event.Type {
+		case domain.TrackingClick:
+			price = s.billing.CPC
+		case domain.TrackingImpression:
+			price = s.billing.CPM / 1000
+		}
+		if price <= 0 {
+			continue
+		}
+		key := spendKey{campaignID: campaignID, day: event.OccurredAt.UTC().Truncate(24 * time.Hour)}
+		if spend[key] == nil {
+			spend[key] = make(map[string]float64)
+		}
+		spend[key][event.ID] = price
+	}
+
+	keys := make([]spendKey, 0, len(spend))
+	for key := range spend {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].campaignID != keys[j].campaignID {
+			return keys[i].campaignID < keys[j].campaignID
+		}
+		return keys[i].day.Before(keys[j].day)
+	})
+
+	charged := make(map[int64]struct{})
+	for _, key := range keys {
+		amount, err := s.repository.AddSpend(ctx, key.campaignID, key.day, spend[key])
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return err
+		}
+		if amount > 0 {
+			charged[key.campaignID] = struct{}{}
+		}
+	}
+
+	for campaignID := range charged {
+		if err := s.enforceBudget(ctx, campaignID); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	span.SetAttributes(attribute.Int("campaigns.charged", len(charged)))
+	return nil
+}
+
+// enforceBudget pauses the ads of the campaign if its budget ran out. Ads
+// paused for the daily budget are resumed by a job on the next UTC day.
+func (s *campaignService) enforceBudget(ctx context.Context, campaignID int64) error {
+	paused, err := s.ads.EnforceCampaignBudget(ctx, campaignID)
+	if err != nil || len(paused) == 0 || s.jobs == nil {
+		return err
+	}
+
+	campaign, err := s.repository.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	if campaign.TotalBudgetExhausted() {
+		return nil
+	}
+
+	payload, err := json.Marshal(resumeCampaignAdsPayload{CampaignID: campaignID})
+	if err != nil {
+		return err
+	}
+	tomorrow := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	_, err = s.jobs.Enqueue(ctx, &domain.Job{Type: ResumeCampaignAdsJobType, Payload: payload, RunAt: tomorrow})
+	return err
+}
+
+func (s *campaignService) runResumeJob(ctx context.Context, job *domain.Job, progress ProgressFunc) (interface{}, error) {
+	var payload resumeCampaignAdsPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, Permanent(fmt.Errorf("invalid resume payload: %w", err))
+	}
+
+	resumed, err := s.ads.ResumeCampaignAds(ctx, payload.CampaignID)
+	if errors.Is(err, ErrCampaignNotFound) {
+		return nil, Permanent(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"resumed": len(resumed)}, nil
+}
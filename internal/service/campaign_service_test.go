@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/repository"
+)
+
+// fakeSpendRepository charges every event id once, as the campaign_charges
+// table does.
+type fakeSpendRepository struct {
+	repository.CampaignRepository
+
+	campaign *domain.Campaign
+	charged  map[string]struct{}
+}
+
+func (r *fakeSpendRepository) GetAdCampaigns(ctx context.Context, adIDs []int64) (map[int64]int64, error) {
+	campaigns := make(map[int64]int64, len(adIDs))
+	for _, id := range adIDs {
+		campaigns[id] = r.campaign.ID
+	}
+	return campaigns, nil
+}
+
+func (r *fakeSpendRepository) GetCampaignByID(ctx context.Context, id int64) (*domain.Campaign, error) {
+	campaign := *r.campaign
+	return &campaign, nil
+}
+
+func (r *fakeSpendRepository) AddSpend(ctx context.Context, campaignID int64, day time.Time, charges map[string]float64) (float64, error) {
+	var amount float64
+	for eventID, charge := range charges {
+		if _, ok := r.charged[eventID]; ok {
+			continue
+		}
+		r.charged[eventID] = struct{}{}
+		amount += charge
+	}
+	r.campaign.Spent += amount
+	r.campaign.SpentToday += amount
+	return amount, nil
+}
+
+type fakeBudgetAdService struct {
+	AdService
+
+	repository *fakeSpendRepository
+	paused     bool
+}
+
+func (s *fakeBudgetAdService) EnforceCampaignBudget(ctx context.Context, campaignID int64) ([]int64, error) {
+	if s.paused || !s.repository.campaign.BudgetExhausted() {
+		return nil, nil
+	}
+	s.paused = true
+	return []int64{1}, nil
+}
+
+type fakeJobService struct {
+	JobService
+
+	enqueued []*domain.Job
+}
+
+func (s *fakeJobService) Register(jobType string, fn JobFunc, maxAttempts int) {}
+
+func (s *fakeJobService) Enqueue(ctx context.Context, job *domain.Job) (*domain.Job, error) {
+	s.enqueued = append(s.enqueued, job)
+	return job, nil
+}
+
+func TestRecordSpendChargesEventsOnce(t *testing.T) {
+	repo := &fakeSpendRepository{
+		campaign: &domain.Campaign{ID: 7, TotalBudget: 100},
+		charged:  make(map[string]struct{}),
+	}
+	svc := NewCampaignService(repo, &fakeBudgetAdService{repository: repo}, nil, BillingOptions{CPC: 0.5}, testMetrics)
+
+	now := time.Now().UTC()
+	events := []*domain.TrackingEvent{
+		{ID: "a", AdID: 1, Type: domain.TrackingClick, OccurredAt: now},
+		{ID: "b", AdID: 1, Type: domain.TrackingClick, OccurredAt: now},
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.RecordSpend(context.Background(), events); err != nil {
+			t.Fatalf("RecordSpend() error = %v", err)
+		}
+	}
+
+	if repo.campaign.Spent != 1 {
+		t.Errorf("spent = %v, want 1", repo.campaign.Spent)
+	}
+}
+
+func TestRecordSpendSchedulesResume(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   float64
+		daily   float64
+		resumes int
+	}{
+		{name: "total budget spent", total: 1, resumes: 0},
+		{name: "daily budget spent", total: 100, daily: 1, resumes: 1},
+		{name: "budget left", total: 100, daily: 10, resumes: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSpendRepository{
+				campaign: &domain.Campaign{ID: 7, TotalBudget: tt.total, DailyBudget: tt.daily},
+				charged:  make(map[string]struct{}),
+			}
+			jobs := &fakeJobService{}
+			svc := NewCampaignService(repo, &fakeBudgetAdService{repository: repo}, jobs, BillingOptions{CPC: 1}, testMetrics)
+
+			events := []*domain.TrackingEvent{{ID: "a", AdID: 1, Type: domain.TrackingClick, OccurredAt: time.Now().UTC()}}
+			if err := svc.RecordSpend(context.Background(), events); err != nil {
+				t.Fatalf("RecordSpend() error = %v", err)
+			}
+
+			if len(jobs.enqueued) != tt.resumes {
+				t.Fatalf("enqueued %d resume jobs, want %d", len(jobs.enqueued), tt.resumes)
+			}
+			for _, job := range jobs.enqueued {
+				if job.Type != ResumeCampaignAdsJobType || !job.RunAt.After(time.Now()) {
+					t.Errorf("job = %s at %v, want %s tomorrow", job.Type, job.RunAt, ResumeCampaignAdsJobType)
+				}
+			}
+		})
+	}
+}
+
+// fakePausingAdRepository saves ads as given and pauses every active ad of
+// the campaign.
+type fakePausingAdRepository struct {
+	repository.AdRepository
+
+	ad    *domain.Ad
+	until *time.Time
+}
+
+func (r *fakePausingAdRepository) UpdateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error) {
+	saved := *ad
+	r.ad = &saved
+	return &saved, nil
+}
+
+func (r *fakePausingAdRepository) PauseAdsByCampaign(ctx context.Context, campaignID int64, until *time.Time) ([]int64, error) {
+	if !r.ad.Active {
+		return nil, nil
+	}
+	r.ad.Active = false
+	r.until = until
+	return []int64{r.ad.ID}, nil
+}
+
+func TestUpdateAdKeepsSpentCampaignPaused(t *testing.T) {
+	tests := []struct {
+		name   string
+		total  float64
+		daily  float64
+		active bool
+		until  bool
+	}{
+		{name: "daily budget spent", total: 100, daily: 1, active: false, until: true},
+		{name: "total budget spent", total: 1, active: false},
+		{name: "budget left", total: 100, daily: 10, active: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaigns := &fakeSpendRepository{
+				campaign: &domain.Campaign{ID: 7, TotalBudget: tt.total, DailyBudget: tt.daily, Spent: 1, SpentToday: 1},
+			}
+			ads := &fakePausingAdRepository{}
+			svc := NewAdService(ads, nil, campaigns, testMetrics)
+
+			campaignID := int64(7)
+			updated, err := svc.UpdateAd(context.Background(), &domain.Ad{ID: 1, Title: "Bike", CampaignID: &campaignID, Active: true})
+			if err != nil {
+				t.Fatalf("UpdateAd() error = %v", err)
+			}
+
+			if updated.Active != tt.active || ads.ad.Active != tt.active {
+				t.Errorf("active = %v, stored %v, want %v", updated.Active, ads.ad.Active, tt.active)
+			}
+			if got := ads.until != nil; got != tt.until {
+				t.Errorf("paused until the next day = %v, want %v", got, tt.until)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Publisher receives ad events relayed from the outbox. An error makes the
+// relay retry the event, so publishers must tolerate duplicates.
+type Publisher interface {
+	Publish(ctx context.Context, event *domain.AdEvent) error
+}
+
+type NamedPublisher struct {
+	Name      string
+	Publisher Publisher
+}
+
+// Publishers fans every event out to all of its publishers. The relay
+// retries an event until every publisher accepted it, so one failing
+// publisher holds back the outbox for all of them. Publishers remembers
+// which publishers already accepted an event and only retries the others;
+// failures are counted per publisher.
+type Publishers struct {
+	publishers []NamedPublisher
+	metrics    *metrics.ServiceMetrics
+
+	mu       sync.Mutex
+	accepted map[int64][]string
+}
+
+func NewPublishers(metrics *metrics.ServiceMetrics, publishers ...NamedPublisher) *Publishers {
+	return &Publishers{
+		publishers: publishers,
+		metrics:    metrics,
+		accepted:   make(map[int64][]string),
+	}
+}
+
+func (p *Publishers) Publish(ctx context.Context, event *domain.AdEvent) error {
+	p.mu.Lock()
+	accepted := p.accepted[event.ID]
+	p.mu.Unlock()
+
+	var errs []error
+	for _, publisher := range p.publishers {
+		if slices.Contains(accepted, publisher.Name) {
+			continue
+		}
+		if err := publisher.Publisher.Publish(ctx, event); err != nil {
+			p.metrics.OutboxFailures.WithLabelValues(publisher.Name).Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", publisher.Name, err))
+			continue
+		}
+		accepted = append(accepted, publisher.Name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(errs) > 0 {
+		p.accepted[event.ID] = accepted
+		return errors.Join(errs...)
+	}
+	// Events are relayed in id order; progress on older events that are
+	// not retried, say after a relay takeover, is not needed anymore.
+	for id := range p.accepted {
+		if id <= event.ID {
+			delete(p.accepted, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ad-service/internal/domain"
+)
+
+type countingPublisher struct {
+	failures int
+	calls    int
+}
+
+func (p *countingPublisher) Publish(ctx context.Context, event *domain.AdEvent) error {
+	p.calls++
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestPublishersRetryOnlyFailedPublishers(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		attempts      int
+		wantErr       bool
+		wantHealthy   int
+		wantUnhealthy int
+	}{
+		{name: "all accept", failures: 0, attempts: 1, wantHealthy: 1, wantUnhealthy: 1},
+		{name: "recovers", failures: 2, attempts: 3, wantHealthy: 1, wantUnhealthy: 3},
+		{name: "still failing", failures: 5, attempts: 2, wantErr: true, wantHealthy: 1, wantUnhealthy: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy := &countingPublisher{}
+			unhealthy := &countingPublisher{failures: tt.failures}
+			publishers := NewPublishers(testMetrics,
+				NamedPublisher{Name: "healthy", Publisher: healthy},
+				NamedPublisher{Name: "unhealthy", Publisher: unhealthy},
+			)
+
+			event := &domain.AdEvent{ID: 10}
+			var err error
+			for i := 0; i < tt.attempts; i++ {
+				err = publishers.Publish(context.Background(), event)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Publish() = %v, want error %v", err, tt.wantErr)
+			}
+			if healthy.calls != tt.wantHealthy {
+				t.Errorf("healthy publisher called %d times, want %d", healthy.calls, tt.wantHealthy)
+			}
+			if unhealthy.calls != tt.wantUnhealthy {
+				t.Errorf("unhealthy publisher called %d times, want %d", unhealthy.calls, tt.wantUnhealthy)
+			}
+		})
+	}
+}
+
+func TestPublishersForgetProgressOnceDone(t *testing.T) {
+	failing := &countingPublisher{failures: 1}
+	publishers := NewPublishers(testMetrics, NamedPublisher{Name: "failing", Publisher: failing})
+
+	if err := publishers.Publish(context.Background(), &domain.AdEvent{ID: 1}); err == nil {
+		t.Fatal("first publish succeeded")
+	}
+	if err := publishers.Publish(context.Background(), &domain.AdEvent{ID: 2}); err != nil {
+		t.Fatalf("Publish() = %v", err)
+	}
+	if len(publishers.accepted) != 0 {
+		t.Fatalf("progress of %d events is kept", len(publishers.accepted))
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrFavoriteNotFound = errors.New("favorite not found")
+	ErrInvalidUserID    = errors.New("invalid user id")
+)
+
+type FavoriteService interface {
+	AddFavorite(ctx context.Context, userID string, adID int64) (bool, error)
+	RemoveFavorite(ctx context.Context, userID string, adID int64) error
+	ListFavorites(ctx context.Context, userID string, limit int, offset int) ([]*domain.Favorite, error)
+}
+
+type favoriteService struct {
+	repository repository.FavoriteRepository
+	ads        repository.AdRepository
+	metrics    *metrics.ServiceMetrics
+	tracer     trace.Tracer
+}
+
+func NewFavoriteService(repository repository.FavoriteRepository, ads repository.AdRepository, metrics *metrics.ServiceMetrics) FavoriteService {
+	tracer := otel.Tracer("ad-service/service")
+	return &favoriteService{
+		repository: repository,
+		ads:        ads,
+		metrics:    metrics,
+		tracer:     tracer,
+	}
+}
+
+func (s *favoriteService) AddFavorite(ctx context.Context, userID string, adID int64) (bool, error) {
+	if userID == "" {
+		return false, ErrInvalidUserID
+	}
+	if adID <= 0 {
+		return false, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service AddFavorite")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("AddFavorite", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("AddFavorite", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	if _, err := s.ads.GetAdByID(ctx, adID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "ad not found"))
+			return false, ErrAdNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return false, err
+	}
+
+	created, err := s.repository.AddFavorite(ctx, userID, adID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return false, err
+	}
+
+	span.SetAttributes(attribute.Bool("favorite.created", created))
+	return created, nil
+}
+
+func (s *favoriteService) RemoveFavorite(ctx context.Context, userID string, adID int64) error {
+	if userID == "" {
+		return ErrInvalidUserID
+	}
+	if adID <= 0 {
+		return ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service RemoveFavorite")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("RemoveFavorite", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("RemoveFavorite", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	if err := s.repository.RemoveFavorite(ctx, userID, adID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "favorite not found"))
+			return ErrFavoriteNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (s *favoriteService) ListFavorites(ctx context.Context, userID string, limit int, offset int) ([]*domain.Favorite, error) {
+	if userID == "" {
+		return nil, ErrInvalidUserID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service ListFavorites")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListFavorites", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListFavorites", status).Observe(duration)
+	}()
+
+	favorites, err := s.repository.ListFavorites(ctx, userID, limit, offset)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if favorites == nil {
+		favorites = []*domain.Favorite{}
+	}
+	return favorites, nil
+}
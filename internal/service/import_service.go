@@ -0,0 +1,687 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrImportNotFound = errors.New("import not found")
+
+// ImportJobType is the type of the background jobs that run imports.
+const ImportJobType = "ads.import"
+
+type ImportOptions struct {
+	SpoolDir          string
+	BatchSize         int
+	MaxAttempts       int
+	MaxReportedErrors int
+}
+
+// ImportRow is one ad read from an import. Number counts the data rows of
+// the import from 1.
+type ImportRow struct {
+	Number int
+	Ad     *domain.Ad
+}
+
+// AdReader reads the rows of an import. Next returns io.EOF after the last
+// row and a *RowError for a row that cannot be read, after which reading
+// continues with the next row. Any other error ends the import.
+type AdReader interface {
+	Next() (ImportRow, error)
+}
+
+// NewAdReader opens an AdReader on the body of an import.
+type NewAdReader func(body io.Reader) AdReader
+
+// ImportReaders returns the reader for a format in the representation of an
+// API version. Background imports look their reader up when they run, which
+// may be on another instance than the one that accepted the upload.
+type ImportReaders func(format string, version int) (NewAdReader, error)
+
+// RowError reports a row of an import that was skipped.
+type RowError struct {
+	Row         int
+	ExternalRef string
+	Field       string
+	Message     string
+}
+
+func (e *RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d: %s: %s", e.Row, e.Field, e.Message)
+}
+
+type ImportService interface {
+	SubmitImport(ctx context.Context, format string, version int, dryRun bool, body io.Reader) (*domain.ImportJob, error)
+	RunImport(ctx context.Context, format string, dryRun bool, reader AdReader) (*domain.ImportJob, error)
+	GetImportJob(ctx context.Context, id string) (*domain.ImportJob, error)
+	StreamImportErrors(ctx context.Context, id string, fn func(domain.ImportRowError) error) error
+}
+
+// importService upserts ads from CSV or NDJSON uploads. Uploads are spooled
+// to disk and imported by background jobs, in batches of one transaction
+// each; rows that fail validation are skipped and reported instead of
+// failing the import. Ads are matched on their external reference, so
+// running an import again updates the ads it created.
+type importService struct {
+	ads        repository.AdRepository
+	attributes repository.AttributeRepository
+	campaigns  repository.CampaignRepository
+	repository repository.ImportRepository
+	jobs       JobService
+	readers    ImportReaders
+	metrics    *metrics.ServiceMetrics
+	logger     *logger.Loggers
+	tracer     trace.Tracer
+	options    ImportOptions
+}
+
+// importPayload is the payload of an import job. The spool directory must
+// be shared by the instances that run jobs.
+type importPayload struct {
+	Path    string `json:"path"`
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+// NewImportService registers the import job with jobs. jobs and readers may
+// be nil when imports only run through RunImport.
+func NewImportService(ads repository.AdRepository, attributes repository.AttributeRepository, campaigns repository.CampaignRepository, imports repository.ImportRepository, jobs JobService, readers ImportReaders, metrics *metrics.ServiceMetrics, logger *logger.Loggers, options ImportOptions) ImportService {
+	if options.SpoolDir == "" {
+		options.SpoolDir = os.TempDir()
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 500
+	}
+	if options.MaxReportedErrors <= 0 {
+		options.MaxReportedErrors = 10000
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	s := &importService{
+		ads:        ads,
+		attributes: attributes,
+		campaigns:  campaigns,
+		repository: imports,
+		jobs:       jobs,
+		readers:    readers,
+		metrics:    metrics,
+		logger:     logger,
+		tracer:     tracer,
+		options:    options,
+	}
+	if jobs != nil {
+		jobs.Register(ImportJobType, s.runJob, options.MaxAttempts)
+	}
+	return s
+}
+
+// SubmitImport spools body to disk and enqueues its import. The returned
+// job is pending; its progress is read with GetImportJob.
+func (s *importService) SubmitImport(ctx context.Context, format string, version int, dryRun bool, body io.Reader) (*domain.ImportJob, error) {
+	ctx, span := s.tracer.Start(ctx, "Service SubmitImport")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("SubmitImport", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("SubmitImport", status).Observe(duration)
+	}()
+
+	job, err := newImportJob(format, dryRun)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.String("import.id", job.ID),
+		attribute.String("import.format", format),
+		attribute.Bool("import.dry_run", dryRun),
+	)
+
+	path, err := s.spool(body)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	payload, err := json.Marshal(importPayload{Path: path, Format: format, Version: version, DryRun: dryRun})
+	if err != nil {
+		os.Remove(path)
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.repository.CreateJob(ctx, job); err != nil {
+		os.Remove(path)
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// The import shares its id with its job.
+	if _, err := s.jobs.Enqueue(ctx, &domain.Job{ID: job.ID, Type: ImportJobType, Payload: payload}); err != nil {
+		os.Remove(path)
+		s.finish(job, err)
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *importService) spool(body io.Reader) (string, error) {
+	file, err := os.CreateTemp(s.options.SpoolDir, "import-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to spool import: %w", err)
+	}
+	_, err = io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to spool import: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// RunImport imports from reader before returning, for the import command.
+func (s *importService) RunImport(ctx context.Context, format string, dryRun bool, reader AdReader) (*domain.ImportJob, error) {
+	job, err := newImportJob(format, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	err = s.process(ctx, job, reader)
+	s.finish(job, err)
+	return job, err
+}
+
+func (s *importService) GetImportJob(ctx context.Context, id string) (*domain.ImportJob, error) {
+	if id == "" {
+		return nil, ErrImportNotFound
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service GetImportJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("GetImportJob", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("GetImportJob", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.String("import.id", id))
+
+	job, err := s.repository.GetJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", ErrImportNotFound.Error()))
+			return nil, ErrImportNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if !job.Status.IsFinished() && s.jobs != nil {
+		if job, err = s.settleAbandoned(ctx, job); err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+// settleAbandoned fails an unfinished import whose job ended without
+// running it to the end, which happens when the job was cancelled before it
+// started or was abandoned by its workers.
+func (s *importService) settleAbandoned(ctx context.Context, job *domain.ImportJob) (*domain.ImportJob, error) {
+	background, err := s.jobs.GetJob(ctx, job.ID)
+	if errors.Is(err, ErrJobNotFound) {
+		// Imports run by the import command have no job.
+		return job, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if background.Status != domain.JobFailed && background.Status != domain.JobCancelled {
+		return job, nil
+	}
+
+	// The job may have settled the import since it was read.
+	if job, err = s.repository.GetJob(ctx, job.ID); err != nil {
+		return nil, err
+	}
+	if job.Status.IsFinished() {
+		return job, nil
+	}
+
+	var payload importPayload
+	if err := json.Unmarshal(background.Payload, &payload); err == nil && payload.Path != "" {
+		os.Remove(payload.Path)
+	}
+	reason := ErrJobCancelled
+	if background.Status == domain.JobFailed {
+		reason = errors.New(background.Error)
+	}
+	s.finish(job, reason)
+	return job, nil
+}
+
+// StreamImportErrors calls fn for every reported row error of an import in
+// row order.
+func (s *importService) StreamImportErrors(ctx context.Context, id string, fn func(domain.ImportRowError) error) error {
+	if _, err := s.GetImportJob(ctx, id); err != nil {
+		return err
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service StreamImportErrors")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("StreamImportErrors", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("StreamImportErrors", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.String("import.id", id))
+
+	if err := s.repository.StreamRowErrors(ctx, id, fn); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// runJob runs the import of a spooled upload. Every attempt starts the
+// import over; ads are upserted by external reference, so the rows an
+// earlier attempt wrote are updated rather than duplicated. Progress is
+// reported in bytes of the upload.
+func (s *importService) runJob(ctx context.Context, background *domain.Job, progress ProgressFunc) (interface{}, error) {
+	var payload importPayload
+	if err := json.Unmarshal(background.Payload, &payload); err != nil {
+		return nil, Permanent(fmt.Errorf("invalid import payload: %w", err))
+	}
+
+	job, err := s.repository.GetJob(ctx, background.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			os.Remove(payload.Path)
+			return nil, Permanent(ErrImportNotFound)
+		}
+		return nil, err
+	}
+
+	err = s.runSpooled(ctx, job, payload, progress)
+	cause := context.Cause(ctx)
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		s.finish(job, nil)
+		os.Remove(payload.Path)
+		return job, nil
+	case errors.Is(cause, ErrJobInterrupted):
+		s.requeue(job, nil)
+	case errors.Is(cause, ErrJobCancelled), errors.As(err, &permanent), background.Attempts >= background.MaxAttempts:
+		if cause != nil {
+			err = cause
+		}
+		s.finish(job, err)
+		os.Remove(payload.Path)
+	default:
+		s.logger.ErrorLogger.Error("Import failed, retrying", "import_id", job.ID, "attempt", background.Attempts, utils.Err(err))
+		s.requeue(job, err)
+	}
+	return nil, err
+}
+
+func (s *importService) runSpooled(ctx context.Context, job *domain.ImportJob, payload importPayload, progress ProgressFunc) error {
+	newReader, err := s.readers(payload.Format, payload.Version)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	file, err := os.Open(payload.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Permanent(errors.New("the upload is no longer available, submit the import again"))
+		}
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	job.Total, job.Created, job.Updated, job.Failed = 0, 0, 0, 0
+	job.Error = ""
+	job.FinishedAt = nil
+	if err := s.repository.DeleteRowErrors(ctx, job.ID); err != nil {
+		return err
+	}
+
+	progress(0, info.Size())
+	body := &progressReader{reader: file, total: info.Size(), progress: progress}
+	return s.process(ctx, job, newReader(body))
+}
+
+// process reads, validates and writes every row of an import, saving the
+// progress of the job after each batch.
+func (s *importService) process(ctx context.Context, job *domain.ImportJob, reader AdReader) (err error) {
+	ctx, span := s.tracer.Start(ctx, "Service RunImport")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("RunImport", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("RunImport", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.String("import.id", job.ID),
+		attribute.String("import.format", job.Format),
+		attribute.Bool("import.dry_run", job.DryRun),
+	)
+
+	defer func() {
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+		}
+		span.SetAttributes(
+			attribute.Int("import.total", job.Total),
+			attribute.Int("import.failed", job.Failed),
+		)
+	}()
+
+	job.Status = domain.ImportRunning
+	if job.StartedAt == nil {
+		now := time.Now()
+		job.StartedAt = &now
+	}
+	if err := s.repository.UpdateJob(ctx, job); err != nil {
+		return err
+	}
+
+	run := &importRun{service: s, job: job, campaigns: make(map[int64]bool), definitions: make(map[string][]*domain.AttributeDefinition)}
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		job.Total++
+
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			run.reject(rowErr.Row, rowErr.ExternalRef, rowErr.Field, rowErr.Message)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := run.validate(ctx, row.Ad); err != nil {
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				return err
+			}
+			run.reject(row.Number, row.Ad.ExternalRef, validationErr.Field, validationErr.Message)
+			continue
+		}
+
+		run.batch = append(run.batch, row)
+		if len(run.batch) >= s.options.BatchSize {
+			if err := run.flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return run.flush(ctx)
+}
+
+// finish records the outcome of a job. It runs after the job context may
+// have been cancelled, so it saves with a context of its own.
+func (s *importService) finish(job *domain.ImportJob, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = domain.ImportSucceeded
+	job.Error = ""
+	if err != nil {
+		job.Status = domain.ImportFailed
+		job.Error = err.Error()
+		switch {
+		case errors.Is(err, ErrJobCancelled):
+			job.Error = "the import was cancelled"
+		case errors.Is(err, context.Canceled):
+			job.Error = "the import was interrupted"
+		}
+	}
+	s.save(job)
+}
+
+// requeue marks an import pending again while its job waits for the next
+// attempt. err is the failure of the attempt, if any.
+func (s *importService) requeue(job *domain.ImportJob, err error) {
+	job.Status = domain.ImportPending
+	job.Error = ""
+	if err != nil {
+		job.Error = err.Error() + ", retrying"
+	}
+	s.save(job)
+}
+
+func (s *importService) save(job *domain.ImportJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.repository.UpdateJob(ctx, job); err != nil {
+		s.logger.ErrorLogger.Error("Failed to save import job", "import_id", job.ID, utils.Err(err))
+	}
+}
+
+// progressReader reports the bytes read from an upload as job progress.
+type progressReader struct {
+	reader   io.Reader
+	done     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.done += int64(n)
+	r.progress(r.done, r.total)
+	return n, err
+}
+
+// importRun holds the state of one import between batches. Campaigns and
+// attribute definitions are looked up once per import rather than per row.
+type importRun struct {
+	service     *importService
+	job         *domain.ImportJob
+	batch       []ImportRow
+	rowErrors   []domain.ImportRowError
+	reported    int
+	campaigns   map[int64]bool
+	definitions map[string][]*domain.AttributeDefinition
+}
+
+func (r *importRun) validate(ctx context.Context, ad *domain.Ad) error {
+	ad.Title = strings.TrimSpace(ad.Title)
+	if ad.Title == "" {
+		return &ValidationError{Field: "title", Message: "is required"}
+	}
+	if ad.Price < 0 {
+		return &ValidationError{Field: "price", Message: "must not be negative"}
+	}
+	if err := validateAdFields(ad); err != nil {
+		return err
+	}
+
+	if ad.CampaignID != nil {
+		exists, ok := r.campaigns[*ad.CampaignID]
+		if !ok {
+			_, err := r.service.campaigns.GetCampaignByID(ctx, *ad.CampaignID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			exists = err == nil
+			r.campaigns[*ad.CampaignID] = exists
+		}
+		if !exists {
+			return &ValidationError{Field: "campaign_id", Message: "campaign not found"}
+		}
+	}
+
+	if ad.Category == "" {
+		return validateAdAttributes(nil, ad)
+	}
+	defs, ok := r.definitions[ad.Category]
+	if !ok {
+		var err error
+		if defs, err = r.service.attributes.ListDefinitions(ctx, ad.Category); err != nil {
+			return err
+		}
+		r.definitions[ad.Category] = defs
+	}
+	return validateAdAttributes(defs, ad)
+}
+
+// reject counts a skipped row. Only the first MaxReportedErrors rows make it
+// into the error report.
+func (r *importRun) reject(row int, externalRef, field, message string) {
+	r.job.Failed++
+	if r.reported >= r.service.options.MaxReportedErrors {
+		return
+	}
+	r.reported++
+	r.rowErrors = append(r.rowErrors, domain.ImportRowError{Row: row, ExternalRef: externalRef, Field: field, Message: message})
+}
+
+// flush writes the batch in one transaction. When the transaction fails,
+// its rows are written one at a time to find the rows at fault.
+func (r *importRun) flush(ctx context.Context) error {
+	if len(r.batch) > 0 {
+		ads := make([]*domain.Ad, len(r.batch))
+		for i, row := range r.batch {
+			ads[i] = row.Ad
+		}
+
+		campaigns := make(map[int64]struct{})
+		for _, ad := range ads {
+			if ad.Active && ad.CampaignID != nil {
+				campaigns[*ad.CampaignID] = struct{}{}
+			}
+		}
+
+		created, updated, err := r.service.ads.ImportAds(ctx, ads, r.job.DryRun)
+		if err == nil {
+			r.job.Created += created
+			r.job.Updated += updated
+		} else {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			for _, row := range r.batch {
+				created, updated, err := r.service.ads.ImportAds(ctx, []*domain.Ad{row.Ad}, r.job.DryRun)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					r.service.logger.ErrorLogger.Error("Failed to import row", "import_id", r.job.ID, "row", row.Number, utils.Err(err))
+					r.reject(row.Number, row.Ad.ExternalRef, "", "the ad could not be saved")
+					continue
+				}
+				r.job.Created += created
+				r.job.Updated += updated
+			}
+		}
+		r.batch = r.batch[:0]
+
+		if !r.job.DryRun {
+			if err := r.enforceBudgets(ctx, campaigns); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := r.service.repository.AddRowErrors(ctx, r.job.ID, r.rowErrors); err != nil {
+		return err
+	}
+	r.rowErrors = r.rowErrors[:0]
+	return r.service.repository.UpdateJob(ctx, r.job)
+}
+
+// enforceBudgets pauses the ads of the campaigns that cannot spend, which
+// the import may have saved as active.
+func (r *importRun) enforceBudgets(ctx context.Context, campaigns map[int64]struct{}) error {
+	for id := range campaigns {
+		campaign, err := r.service.campaigns.GetCampaignByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := pauseSpentCampaign(ctx, r.service.ads, campaign); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newImportJob(format string, dryRun bool) (*domain.ImportJob, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	return &domain.ImportJob{
+		ID:        hex.EncodeToString(buf),
+		Status:    domain.ImportPending,
+		Format:    format,
+		DryRun:    dryRun,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
@@ -0,0 +1,664 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrJobNotFound     = errors.New("job not found")
+	ErrJobFinished     = errors.New("the job has already finished")
+	ErrJobNotRetryable = errors.New("only failed or cancelled jobs can be retried")
+	// ErrJobCancelled is the cause of the context of a job that was
+	// cancelled through CancelJob.
+	ErrJobCancelled = errors.New("the job was cancelled")
+	// ErrJobInterrupted is the cause of the context of a job whose worker
+	// stopped or lost the lease. The job runs again, from the start.
+	ErrJobInterrupted = errors.New("the job was interrupted")
+)
+
+var errJobLeaseLost = fmt.Errorf("%w: the lease was lost", ErrJobInterrupted)
+
+type JobOptions struct {
+	Workers        int
+	PollInterval   time.Duration
+	Lease          time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxAttempts    int
+	Retention      time.Duration
+	InstanceID     string
+}
+
+// ProgressFunc reports how much of its work a job has done. Progress is
+// saved with the next heartbeat of the job.
+type ProgressFunc func(done, total int64)
+
+// JobFunc runs a job of one type and returns its result, which is saved as
+// JSON. The context is cancelled with ErrJobCancelled or ErrJobInterrupted
+// as its cause when the job has to stop. A job can run more than once, so
+// it must be safe to run again after a failure.
+type JobFunc func(ctx context.Context, job *domain.Job, progress ProgressFunc) (interface{}, error)
+
+// Permanent marks a job error that retrying cannot fix, so the job fails
+// without using its remaining attempts.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+type JobService interface {
+	Register(jobType string, fn JobFunc, maxAttempts int)
+	Enqueue(ctx context.Context, job *domain.Job) (*domain.Job, error)
+	GetJob(ctx context.Context, id string) (*domain.Job, error)
+	ListJobs(ctx context.Context, filter domain.JobFilter, limit int) ([]*domain.Job, error)
+	CancelJob(ctx context.Context, id string) (*domain.Job, error)
+	RetryJob(ctx context.Context, id string) (*domain.Job, error)
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// jobService runs background jobs that outlive a request. Jobs are stored in
+// the database and leased by one worker at a time; the lease is extended by
+// a heartbeat while the job runs, so the jobs of an instance that died are
+// taken over by the others once their lease expires. Failed jobs are retried
+// with exponential backoff until they run out of attempts.
+type jobService struct {
+	repository repository.JobRepository
+	metrics    *metrics.ServiceMetrics
+	logger     *logger.Loggers
+	tracer     trace.Tracer
+	options    JobOptions
+
+	handlers map[string]registeredJob
+	types    []string
+
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+
+	wake     chan struct{}
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+type registeredJob struct {
+	fn          JobFunc
+	maxAttempts int
+}
+
+func NewJobService(repository repository.JobRepository, metrics *metrics.ServiceMetrics, logger *logger.Loggers, options JobOptions) JobService {
+	if options.Workers <= 0 {
+		options.Workers = 2
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = time.Second
+	}
+	if options.Lease < 3*time.Second {
+		options.Lease = time.Minute
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = 10 * time.Second
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 10 * time.Minute
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 3
+	}
+	if options.Retention <= 0 {
+		options.Retention = 7 * 24 * time.Hour
+	}
+	if options.InstanceID == "" {
+		hostname, _ := os.Hostname()
+		options.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	return &jobService{
+		repository: repository,
+		metrics:    metrics,
+		logger:     logger,
+		tracer:     tracer,
+		options:    options,
+		handlers:   make(map[string]registeredJob),
+		running:    make(map[string]context.CancelCauseFunc),
+		wake:       make(chan struct{}, 1),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Register makes the workers run jobs of jobType with fn, giving up after
+// maxAttempts, or the default attempts when it is 0. It must be called
+// before Start.
+func (s *jobService) Register(jobType string, fn JobFunc, maxAttempts int) {
+	if maxAttempts <= 0 {
+		maxAttempts = s.options.MaxAttempts
+	}
+	if _, ok := s.handlers[jobType]; !ok {
+		s.types = append(s.types, jobType)
+	}
+	s.handlers[jobType] = registeredJob{fn: fn, maxAttempts: maxAttempts}
+}
+
+// Enqueue stores a pending job. ID, MaxAttempts and RunAt are filled in
+// when they are empty.
+func (s *jobService) Enqueue(ctx context.Context, job *domain.Job) (*domain.Job, error) {
+	ctx, span := s.tracer.Start(ctx, "Service EnqueueJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("EnqueueJob", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("EnqueueJob", status).Observe(duration)
+	}()
+
+	registered, ok := s.handlers[job.Type]
+	if !ok {
+		status = "error"
+		err := fmt.Errorf("unknown job type %q", job.Type)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if job.ID == "" {
+		id, err := newJobID()
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, err
+		}
+		job.ID = id
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = registered.maxAttempts
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	job.Status = domain.JobPending
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	span.SetAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("job.type", job.Type),
+	)
+
+	if err := s.repository.CreateJob(ctx, job); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (s *jobService) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	if id == "" {
+		return nil, ErrJobNotFound
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service GetJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("GetJob", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("GetJob", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	job, err := s.repository.GetJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", ErrJobNotFound.Error()))
+			return nil, ErrJobNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *jobService) ListJobs(ctx context.Context, filter domain.JobFilter, limit int) ([]*domain.Job, error) {
+	ctx, span := s.tracer.Start(ctx, "Service ListJobs")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListJobs", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListJobs", status).Observe(duration)
+	}()
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		status = "invalid"
+		err := &ValidationError{Field: "status", Message: "must be pending, running, succeeded, failed or cancelled"}
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	jobs, err := s.repository.ListJobs(ctx, filter, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// CancelJob cancels a pending job, or asks a running one to stop; it stops
+// at once when it runs on this instance and with the next heartbeat
+// otherwise.
+func (s *jobService) CancelJob(ctx context.Context, id string) (*domain.Job, error) {
+	ctx, span := s.tracer.Start(ctx, "Service CancelJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("CancelJob", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("CancelJob", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	job, err := s.repository.CancelJob(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			status = "not_found"
+			return nil, ErrJobNotFound
+		case errors.Is(err, repository.ErrJobStatus):
+			status = "conflict"
+			return nil, ErrJobFinished
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	cancel, ok := s.running[id]
+	s.mu.Unlock()
+	if ok {
+		cancel(ErrJobCancelled)
+	}
+	return job, nil
+}
+
+// RetryJob runs a failed or cancelled job again with all of its attempts.
+func (s *jobService) RetryJob(ctx context.Context, id string) (*domain.Job, error) {
+	ctx, span := s.tracer.Start(ctx, "Service RetryJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("RetryJob", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("RetryJob", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.String("job.id", id))
+
+	job, err := s.repository.RetryJob(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			status = "not_found"
+			return nil, ErrJobNotFound
+		case errors.Is(err, repository.ErrJobStatus):
+			status = "conflict"
+			return nil, ErrJobNotRetryable
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (s *jobService) Start() {
+	s.wg.Add(s.options.Workers + 1)
+	for i := 0; i < s.options.Workers; i++ {
+		go s.work()
+	}
+	go s.cleanup()
+}
+
+// Stop interrupts the running jobs and hands them back, so another instance
+// or the next start runs them again.
+func (s *jobService) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *jobService) work() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.claim() {
+			select {
+			case <-s.stopCh:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// claim runs the next due job and reports whether there was one.
+func (s *jobService) claim() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	job, err := s.repository.ClaimJob(ctx, s.types, s.options.InstanceID, s.options.Lease)
+	cancel()
+	if err != nil {
+		s.logger.ErrorLogger.Error("Failed to claim job", utils.Err(err))
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	s.run(job)
+	return true
+}
+
+func (s *jobService) run(job *domain.Job) {
+	ctx, span := s.tracer.Start(context.Background(), "Service RunJob")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("RunJob", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("RunJob", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("job.type", job.Type),
+		attribute.Int("job.attempt", job.Attempts),
+	)
+
+	var err error
+	var result interface{}
+	switch {
+	case job.CancelRequested:
+		// The job was cancelled while its previous worker went away.
+		err = ErrJobCancelled
+	case job.Attempts > job.MaxAttempts:
+		// Every attempt ended without the worker reporting back, which is
+		// what a job that crashes the process looks like.
+		err = Permanent(errors.New("the job was abandoned by its worker on every attempt"))
+	default:
+		result, err = s.execute(ctx, job)
+	}
+
+	status = s.settle(ctx, job, result, err)
+	if status != "success" && err != nil {
+		span.RecordError(err)
+	}
+}
+
+// execute runs the job while a heartbeat keeps its lease, saves its progress
+// and watches for cancellation. Errors caused by the job being stopped are
+// replaced with the cause.
+func (s *jobService) execute(ctx context.Context, job *domain.Job) (interface{}, error) {
+	registered := s.handlers[job.Type]
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	s.mu.Lock()
+	s.running[job.ID] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, job.ID)
+		s.mu.Unlock()
+	}()
+
+	progress := &jobProgress{progress: job.Progress}
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		s.heartbeat(job, progress, cancel, stopHeartbeat)
+	}()
+
+	result, err := callJob(ctx, registered.fn, job, progress.report)
+	close(stopHeartbeat)
+	<-heartbeatDone
+
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			err = cause
+		}
+	}
+	job.Progress = progress.get()
+	return result, err
+}
+
+func callJob(ctx context.Context, fn JobFunc, job *domain.Job, progress ProgressFunc) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("the job panicked: %v", recovered)
+		}
+	}()
+	return fn(ctx, job, progress)
+}
+
+func (s *jobService) heartbeat(job *domain.Job, progress *jobProgress, cancel context.CancelCauseFunc, stop <-chan struct{}) {
+	ticker := time.NewTicker(s.options.Lease / 3)
+	defer ticker.Stop()
+
+	shutdown := s.stopCh
+	for {
+		select {
+		case <-stop:
+			return
+		case <-shutdown:
+			cancel(ErrJobInterrupted)
+			shutdown = nil
+		case <-ticker.C:
+			ctx, cancelHeartbeat := context.WithTimeout(context.Background(), 10*time.Second)
+			cancelRequested, err := s.repository.Heartbeat(ctx, job.ID, s.options.InstanceID, progress.get(), s.options.Lease)
+			cancelHeartbeat()
+			if errors.Is(err, repository.ErrLeaseLost) {
+				s.logger.ErrorLogger.Error("Job lease lost", "job_id", job.ID, "job_type", job.Type)
+				cancel(errJobLeaseLost)
+				return
+			}
+			if err != nil {
+				s.logger.ErrorLogger.Error("Failed to extend job lease", "job_id", job.ID, utils.Err(err))
+				continue
+			}
+			if cancelRequested {
+				cancel(ErrJobCancelled)
+			}
+		}
+	}
+}
+
+// settle records the outcome of a run and returns it as the metrics status.
+// It runs after the job context may have been cancelled, so it saves with a
+// context of its own.
+func (s *jobService) settle(ctx context.Context, job *domain.Job, result interface{}, err error) string {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	owner := s.options.InstanceID
+	var status string
+	var saveErr error
+	switch {
+	case err == nil:
+		status = "success"
+		var data []byte
+		if result != nil {
+			if data, saveErr = json.Marshal(result); saveErr != nil {
+				break
+			}
+		}
+		progress := job.Progress
+		if progress.Total > 0 {
+			progress.Done = progress.Total
+		}
+		saveErr = s.repository.MarkSucceeded(ctx, job.ID, owner, progress, data)
+	case errors.Is(err, errJobLeaseLost):
+		// Another worker owns the job now.
+		return "lease_lost"
+	case errors.Is(err, ErrJobCancelled):
+		status = "cancelled"
+		saveErr = s.repository.MarkCancelled(ctx, job.ID, owner)
+	case errors.Is(err, ErrJobInterrupted):
+		status = "interrupted"
+		saveErr = s.repository.Release(ctx, job.ID, owner)
+	default:
+		var permanent *permanentError
+		final := errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts
+		status = "retry"
+		if final {
+			status = "error"
+		}
+		s.logger.ErrorLogger.Error("Job failed", "job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts, "final", final, utils.Err(err))
+		saveErr = s.repository.MarkFailed(ctx, job.ID, owner, err.Error(), expBackoff(s.options.InitialBackoff, s.options.MaxBackoff, job.Attempts), final)
+	}
+
+	if errors.Is(saveErr, repository.ErrLeaseLost) {
+		s.logger.ErrorLogger.Error("Job lease lost before its outcome was saved", "job_id", job.ID, "job_type", job.Type)
+		return "lease_lost"
+	}
+	if saveErr != nil {
+		s.logger.ErrorLogger.Error("Failed to save job outcome", "job_id", job.ID, "job_type", job.Type, utils.Err(saveErr))
+		return "error"
+	}
+	return status
+}
+
+func (s *jobService) cleanup() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			deleted, err := s.repository.DeleteFinishedJobs(ctx, time.Now().Add(-s.options.Retention))
+			cancel()
+			if err != nil {
+				s.logger.ErrorLogger.Error("Failed to clean up finished jobs", utils.Err(err))
+				continue
+			}
+			if deleted > 0 {
+				s.logger.InfoLogger.Info("Cleaned up finished jobs", "deleted", deleted)
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// jobProgress hands the progress reported by a job to its heartbeat.
+type jobProgress struct {
+	mu       sync.Mutex
+	progress domain.JobProgress
+}
+
+func (p *jobProgress) report(done, total int64) {
+	p.mu.Lock()
+	p.progress = domain.JobProgress{Done: done, Total: total}
+	p.mu.Unlock()
+}
+
+func (p *jobProgress) get() domain.JobProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.progress
+}
+
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
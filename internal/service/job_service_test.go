@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/repository"
+)
+
+// fakeJobRepository holds a single job and enforces its lease like the
+// MySQL repository: only the owner may extend or settle it.
+type fakeJobRepository struct {
+	repository.JobRepository
+
+	mu      sync.Mutex
+	job     *domain.Job
+	owner   string
+	retryIn time.Duration
+}
+
+func (r *fakeJobRepository) ClaimJob(ctx context.Context, types []string, owner string, lease time.Duration) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.job.Status != domain.JobPending || r.job.RunAt.After(time.Now()) || !slices.Contains(types, r.job.Type) {
+		return nil, nil
+	}
+	r.job.Status = domain.JobRunning
+	r.job.Attempts++
+	r.owner = owner
+	claimed := *r.job
+	return &claimed, nil
+}
+
+func (r *fakeJobRepository) Heartbeat(ctx context.Context, id string, owner string, progress domain.JobProgress, lease time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.owner != owner {
+		return false, repository.ErrLeaseLost
+	}
+	r.job.Progress = progress
+	return r.job.CancelRequested, nil
+}
+
+func (r *fakeJobRepository) settle(owner string, status domain.JobStatus, update func(job *domain.Job)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.owner != owner || r.job.Status != domain.JobRunning {
+		return repository.ErrLeaseLost
+	}
+	r.job.Status = status
+	r.owner = ""
+	if update != nil {
+		update(r.job)
+	}
+	return nil
+}
+
+func (r *fakeJobRepository) MarkSucceeded(ctx context.Context, id string, owner string, progress domain.JobProgress, result []byte) error {
+	return r.settle(owner, domain.JobSucceeded, func(job *domain.Job) {
+		job.Progress = progress
+		job.Result = result
+	})
+}
+
+func (r *fakeJobRepository) MarkFailed(ctx context.Context, id string, owner string, jobErr string, retryIn time.Duration, final bool) error {
+	status := domain.JobPending
+	if final {
+		status = domain.JobFailed
+	}
+	return r.settle(owner, status, func(job *domain.Job) {
+		job.Error = jobErr
+		job.RunAt = time.Now().Add(retryIn)
+		r.retryIn = retryIn
+	})
+}
+
+func (r *fakeJobRepository) MarkCancelled(ctx context.Context, id string, owner string) error {
+	return r.settle(owner, domain.JobCancelled, nil)
+}
+
+func (r *fakeJobRepository) Release(ctx context.Context, id string, owner string) error {
+	return r.settle(owner, domain.JobPending, nil)
+}
+
+// update changes the stored job as another instance or API call would.
+func (r *fakeJobRepository) update(fn func(r *fakeJobRepository)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r)
+}
+
+func TestJobLeasing(t *testing.T) {
+	waitForStop := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	tests := []struct {
+		name            string
+		attempts        int
+		cancelRequested bool
+		run             func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error)
+		ran             bool
+		status          domain.JobStatus
+		result          string
+		errContains     string
+		retried         bool
+	}{
+		{
+			name: "succeeded",
+			run: func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error) {
+				progress(3, 10)
+				return map[string]int{"imported": 10}, nil
+			},
+			ran:    true,
+			status: domain.JobSucceeded,
+			result: `{"imported":10}`,
+		},
+		{
+			name: "retried",
+			run: func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error) {
+				return nil, errors.New("database is down")
+			},
+			ran:         true,
+			status:      domain.JobPending,
+			errContains: "database is down",
+			retried:     true,
+		},
+		{
+			name:     "out of attempts",
+			attempts: 2,
+			run: func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error) {
+				return nil, errors.New("database is down")
+			},
+			ran:         true,
+			status:      domain.JobFailed,
+			errContains: "database is down",
+		},
+		{
+			name: "permanent",
+			run: func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error) {
+				return nil, Permanent(errors.New("spool file is gone"))
+			},
+			ran:         true,
+			status:      domain.JobFailed,
+			errContains: "spool file is gone",
+		},
+		{
+			name: "panicked",
+			run: func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error) {
+				panic("nil map")
+			},
+			ran:         true,
+			status:      domain.JobPending,
+			errContains: "the job panicked: nil map",
+			retried:     true,
+		},
+		{
+			name: "cancelled while running",
+			run: func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error) {
+				repo.update(func(r *fakeJobRepository) { r.job.CancelRequested = true })
+				return waitForStop(ctx)
+			},
+			ran:    true,
+			status: domain.JobCancelled,
+		},
+		{
+			name: "lease lost",
+			run: func(ctx context.Context, repo *fakeJobRepository, progress ProgressFunc) (interface{}, error) {
+				repo.update(func(r *fakeJobRepository) { r.owner = "other-instance" })
+				return waitForStop(ctx)
+			},
+			ran:    true,
+			status: domain.JobRunning,
+		},
+		{
+			name:            "cancelled before running",
+			cancelRequested: true,
+			status:          domain.JobCancelled,
+		},
+		{
+			name:        "abandoned on every attempt",
+			attempts:    3,
+			status:      domain.JobFailed,
+			errContains: "abandoned",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeJobRepository{job: &domain.Job{
+				ID:              "job-1",
+				Type:            "test",
+				Status:          domain.JobPending,
+				Attempts:        tt.attempts,
+				MaxAttempts:     3,
+				CancelRequested: tt.cancelRequested,
+			}}
+			svc := NewJobService(repo, testMetrics, testLoggers(), JobOptions{Lease: 3 * time.Second, InstanceID: "instance"}).(*jobService)
+			ran := false
+			svc.Register("test", func(ctx context.Context, job *domain.Job, progress ProgressFunc) (interface{}, error) {
+				ran = true
+				return tt.run(ctx, repo, progress)
+			}, 3)
+
+			if !svc.claim() {
+				t.Fatal("claim() found no job")
+			}
+			if svc.claim() {
+				t.Fatal("claim() ran a job twice")
+			}
+
+			if ran != tt.ran {
+				t.Errorf("job ran = %v, want %v", ran, tt.ran)
+			}
+			job := repo.job
+			if job.Status != tt.status {
+				t.Errorf("status = %s, want %s", job.Status, tt.status)
+			}
+			if string(job.Result) != tt.result {
+				t.Errorf("result = %s, want %s", job.Result, tt.result)
+			}
+			if tt.result != "" && job.Progress != (domain.JobProgress{Done: 10, Total: 10}) {
+				t.Errorf("progress = %+v, want the job done", job.Progress)
+			}
+			if !strings.Contains(job.Error, tt.errContains) {
+				t.Errorf("error = %q, want it to contain %q", job.Error, tt.errContains)
+			}
+			if tt.retried && repo.retryIn < svc.options.InitialBackoff {
+				t.Errorf("retry in %s, want at least %s", repo.retryIn, svc.options.InitialBackoff)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OutboxOptions struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	RetryBackoff   time.Duration
+	PublishTimeout time.Duration
+	Retention      time.Duration
+}
+
+type OutboxRelay interface {
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// outboxRelay publishes outbox messages in id order. A message is only
+// marked published after the publisher accepted it, and a failure stops the
+// batch so later events of the same ad never overtake it: delivery is at
+// least once and ordered per ad. A failure of any one publisher therefore
+// holds back the events of all of them; see Publishers. Only the instance
+// holding the relay lock publishes.
+type outboxRelay struct {
+	repository repository.OutboxRepository
+	publisher  Publisher
+	metrics    *metrics.ServiceMetrics
+	logger     *logger.Loggers
+	tracer     trace.Tracer
+	options    OutboxOptions
+
+	lock     *repository.RelayLock
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func NewOutboxRelay(repository repository.OutboxRepository, publisher Publisher, metrics *metrics.ServiceMetrics, logger *logger.Loggers, options OutboxOptions) OutboxRelay {
+	if options.PollInterval <= 0 {
+		options.PollInterval = 500 * time.Millisecond
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 100
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = 5 * time.Second
+	}
+	if options.PublishTimeout <= 0 {
+		options.PublishTimeout = 5 * time.Second
+	}
+	if options.Retention <= 0 {
+		options.Retention = 24 * time.Hour
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	return &outboxRelay{
+		repository: repository,
+		publisher:  publisher,
+		metrics:    metrics,
+		logger:     logger,
+		tracer:     tracer,
+		options:    options,
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+func (r *outboxRelay) Start() {
+	go r.run()
+}
+
+func (r *outboxRelay) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	select {
+	case <-r.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *outboxRelay) run() {
+	defer close(r.doneCh)
+	defer func() {
+		if r.lock != nil {
+			r.lock.Release()
+		}
+	}()
+
+	ticker := time.NewTicker(r.options.PollInterval)
+	defer ticker.Stop()
+
+	cleanup := time.NewTicker(10 * time.Minute)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.leading() {
+				continue
+			}
+			if !r.relay() {
+				r.wait(r.options.RetryBackoff)
+			}
+		case <-cleanup.C:
+			if r.lock != nil {
+				r.cleanup()
+			}
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+func (r *outboxRelay) wait(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-r.stopCh:
+	}
+}
+
+// leading makes sure this instance holds the relay lock, taking it over when
+// the previous holder went away.
+func (r *outboxRelay) leading() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if r.lock != nil {
+		if r.lock.Held(ctx) {
+			return true
+		}
+		r.logger.ErrorLogger.Error("Outbox relay lock lost")
+		r.lock.Release()
+		r.lock = nil
+	}
+
+	lock, err := r.repository.AcquireRelayLock(ctx)
+	if err != nil {
+		r.logger.ErrorLogger.Error("Failed to acquire outbox relay lock", utils.Err(err))
+		return false
+	}
+	if lock == nil {
+		return false
+	}
+
+	r.logger.InfoLogger.Info("Outbox relay lock acquired")
+	r.lock = lock
+	return true
+}
+
+// relay drains the outbox and reports false when publishing has to be
+// retried later.
+func (r *outboxRelay) relay() bool {
+	for {
+		published, more, ok := r.relayBatch()
+		if !ok {
+			return false
+		}
+		if !more || published == 0 {
+			return true
+		}
+		select {
+		case <-r.stopCh:
+			return true
+		default:
+		}
+	}
+}
+
+func (r *outboxRelay) relayBatch() (int, bool, bool) {
+	ctx, span := r.tracer.Start(context.Background(), "Service RelayOutbox")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		r.metrics.MethodCount.WithLabelValues("RelayOutbox", status).Inc()
+		r.metrics.MethodDuration.WithLabelValues("RelayOutbox", status).Observe(duration)
+	}()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	messages, err := r.repository.FetchUnpublished(fetchCtx, r.options.BatchSize)
+	cancel()
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		r.logger.ErrorLogger.Error("Failed to fetch outbox messages", utils.Err(err))
+		return 0, false, false
+	}
+
+	if len(messages) == 0 {
+		r.metrics.OutboxBacklogAge.Set(0)
+		return 0, false, true
+	}
+	r.metrics.OutboxBacklogAge.Set(messages[0].Age.Seconds())
+	fetchedAt := time.Now()
+
+	ids := make([]int64, 0, len(messages))
+	var publishErr error
+	for _, message := range messages {
+		var event domain.AdEvent
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			// A payload that cannot be decoded never will be, skip it
+			// instead of blocking the outbox.
+			r.logger.ErrorLogger.Error("Skipping undecodable outbox message", utils.Err(err), "outbox_id", message.ID)
+			ids = append(ids, message.ID)
+			continue
+		}
+		event.ID = message.ID
+
+		publishCtx, cancel := context.WithTimeout(ctx, r.options.PublishTimeout)
+		publishErr = r.publisher.Publish(publishCtx, &event)
+		cancel()
+		if publishErr != nil {
+			r.logger.ErrorLogger.Error("Failed to publish outbox message", utils.Err(publishErr), "outbox_id", message.ID, "ad_id", message.AggregateID)
+			break
+		}
+
+		ids = append(ids, message.ID)
+		r.metrics.OutboxLag.Observe((message.Age + time.Since(fetchedAt)).Seconds())
+	}
+
+	markCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	err = r.repository.MarkPublished(markCtx, ids)
+	cancel()
+	if err != nil {
+		// The messages stay unpublished and are relayed again, which the
+		// at-least-once contract allows.
+		status = "error"
+		span.RecordError(err)
+		r.logger.ErrorLogger.Error("Failed to mark outbox messages published", utils.Err(err))
+		return len(ids), false, false
+	}
+
+	span.SetAttributes(attribute.Int("outbox.published", len(ids)))
+	if publishErr != nil {
+		status = "error"
+		span.RecordError(publishErr)
+		return len(ids), false, false
+	}
+	return len(ids), len(messages) == r.options.BatchSize, true
+}
+
+func (r *outboxRelay) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deleted, err := r.repository.DeletePublished(ctx, r.options.Retention)
+	if err != nil {
+		r.logger.ErrorLogger.Error("Failed to clean up published outbox messages", utils.Err(err))
+		return
+	}
+	if deleted > 0 {
+		r.logger.InfoLogger.Info("Cleaned up published outbox messages", "deleted", deleted)
+	}
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/infrastructure/notifier"
+	"ad-service/internal/repository"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+type SavedSearchOptions struct {
+	QueueSize int
+	// RetryInterval is how long a notification stays pending before it is
+	// sent again.
+	RetryInterval time.Duration
+	MaxAttempts   int
+}
+
+type SavedSearchService interface {
+	Publisher
+	ListSavedSearches(ctx context.Context, userID string) ([]*domain.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, search *domain.SavedSearch) (*domain.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, userID string, id int64) error
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// savedSearchService matches stored and updated ads against saved searches
+// as the outbox relays them and notifies the owners from a background
+// worker, so ad writes never wait for notifications. A match is recorded as
+// pending before its owner is notified and notifications that fail are sent
+// again until MaxAttempts.
+type savedSearchService struct {
+	repository repository.SavedSearchRepository
+	notifier   notifier.Notifier
+	metrics    *metrics.ServiceMetrics
+	logger     *logger.Loggers
+	tracer     trace.Tracer
+	options    SavedSearchOptions
+
+	queue    chan *domain.SearchNotification
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func NewSavedSearchService(repository repository.SavedSearchRepository, notifier notifier.Notifier, metrics *metrics.ServiceMetrics, logger *logger.Loggers, options SavedSearchOptions) SavedSearchService {
+	if options.QueueSize <= 0 {
+		options.QueueSize = 1000
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = time.Minute
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 10
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	return &savedSearchService{
+		repository: repository,
+		notifier:   notifier,
+		metrics:    metrics,
+		logger:     logger,
+		tracer:     tracer,
+		options:    options,
+		queue:      make(chan *domain.SearchNotification, options.QueueSize),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+func (s *savedSearchService) ListSavedSearches(ctx context.Context, userID string) ([]*domain.SavedSearch, error) {
+	if userID == "" {
+		return nil, ErrInvalidUserID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service ListSavedSearches")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListSavedSearches", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListSavedSearches", status).Observe(duration)
+	}()
+
+	searches, err := s.repository.ListSavedSearches(ctx, userID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if searches == nil {
+		searches = []*domain.SavedSearch{}
+	}
+	return searches, nil
+}
+
+func (s *savedSearchService) CreateSavedSearch(ctx context.Context, search *domain.SavedSearch) (*domain.SavedSearch, error) {
+	if search.UserID == "" {
+		return nil, ErrInvalidUserID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service CreateSavedSearch")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("CreateSavedSearch", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("CreateSavedSearch", status).Observe(duration)
+	}()
+
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" || len(search.Name) > 100 {
+		status = "invalid"
+		err := &ValidationError{Field: "name", Message: "must be between 1 and 100 characters"}
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+	if err := validateAdFilter(&search.Filter); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	created, err := s.repository.CreateSavedSearch(ctx, search)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("saved_search.id", created.ID))
+	return created, nil
+}
+
+func (s *savedSearchService) DeleteSavedSearch(ctx context.Context, userID string, id int64) error {
+	if userID == "" {
+		return ErrInvalidUserID
+	}
+	if id <= 0 {
+		return ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service DeleteSavedSearch")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("DeleteSavedSearch", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("DeleteSavedSearch", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("saved_search.id", id))
+
+	if err := s.repository.DeleteSavedSearch(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "saved search not found"))
+			return ErrSavedSearchNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// Publish matches created and updated ads against the saved searches and
+// records every new match as pending before it returns, so an event the
+// relay marks published is never lost. Owners are notified by the
+// background worker.
+func (s *savedSearchService) Publish(ctx context.Context, event *domain.AdEvent) error {
+	if event.Ad == nil || (event.Type != domain.AdCreated && event.Type != domain.AdUpdated) {
+		return nil
+	}
+
+	notifications, err := s.match(ctx, event)
+	for _, notification := range notifications {
+		select {
+		case s.queue <- notification:
+		default:
+			// Still pending, retryPending sends it.
+			s.metrics.MethodCount.WithLabelValues("NotifySavedSearch", "deferred").Inc()
+		}
+	}
+	return err
+}
+
+func (s *savedSearchService) Start() {
+	go s.run()
+}
+
+func (s *savedSearchService) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	select {
+	case <-s.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *savedSearchService) run() {
+	defer close(s.doneCh)
+
+	retry := time.NewTicker(s.options.RetryInterval)
+	defer retry.Stop()
+
+	for {
+		select {
+		case notification := <-s.queue:
+			s.send(notification)
+		case <-retry.C:
+			s.retryPending()
+		case <-s.stopCh:
+			// Queued notifications are pending and sent after a restart.
+			return
+		}
+	}
+}
+
+// match records the matches of the ad that are new and returns their
+// notifications.
+func (s *savedSearchService) match(ctx context.Context, event *domain.AdEvent) ([]*domain.SearchNotification, error) {
+	ctx, span := s.tracer.Start(ctx, "Service MatchSavedSearches")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("MatchSavedSearches", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("MatchSavedSearches", status).Observe(duration)
+	}()
+
+	ad := event.Ad
+	span.SetAttributes(
+		attribute.Int64("ad.id", ad.ID),
+		attribute.String("event.type", string(event.Type)),
+	)
+
+	if !ad.Active {
+		return nil, nil
+	}
+
+	searches, err := s.repository.ListSearchesForCategory(ctx, ad.Category)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var notifications []*domain.SearchNotification
+	for _, search := range searches {
+		if !search.Matches(ad) {
+			continue
+		}
+
+		notification := &domain.SearchNotification{
+			UserID:          search.UserID,
+			SavedSearchID:   search.ID,
+			SavedSearchName: search.Name,
+			Ad:              ad,
+			MatchedAt:       time.Now().UTC(),
+		}
+		first, err := s.repository.RecordMatch(ctx, notification)
+		if err != nil {
+			// Matches recorded so far are skipped when the event is
+			// published again.
+			status = "error"
+			span.RecordError(err)
+			return notifications, err
+		}
+		if first {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("saved_search.matched", len(notifications)))
+	return notifications, nil
+}
+
+// send notifies the owner of a freshly recorded match.
+func (s *savedSearchService) send(notification *domain.SearchNotification) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx, span := s.tracer.Start(ctx, "Service NotifySavedSearch")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("NotifySavedSearch", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("NotifySavedSearch", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("saved_search.id", notification.SavedSearchID),
+		attribute.Int64("ad.id", notification.Ad.ID),
+	)
+
+	if err := s.notify(ctx, notification); err != nil {
+		status = "error"
+		span.RecordError(err)
+	}
+}
+
+// notify sends a recorded match to the owner of the search. A match that
+// cannot be sent stays pending for retryPending.
+func (s *savedSearchService) notify(ctx context.Context, notification *domain.SearchNotification) error {
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		s.logger.ErrorLogger.Error("Failed to notify saved search owner", utils.Err(err), "saved_search_id", notification.SavedSearchID, "ad_id", notification.Ad.ID)
+		if err := s.repository.RecordNotifyFailure(ctx, notification.SavedSearchID, notification.Ad.ID); err != nil {
+			s.logger.ErrorLogger.Error("Failed to record saved search notification failure", utils.Err(err), "saved_search_id", notification.SavedSearchID, "ad_id", notification.Ad.ID)
+		}
+		return err
+	}
+	if err := s.repository.MarkNotified(ctx, notification.SavedSearchID, notification.Ad.ID); err != nil {
+		// The match stays pending and its owner may be notified twice.
+		s.logger.ErrorLogger.Error("Failed to mark saved search match notified", utils.Err(err), "saved_search_id", notification.SavedSearchID, "ad_id", notification.Ad.ID)
+		return err
+	}
+	return nil
+}
+
+// retryPending sends the notifications that stayed pending for at least
+// RetryInterval.
+func (s *savedSearchService) retryPending() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx, span := s.tracer.Start(ctx, "Service RetrySearchNotifications")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("RetrySearchNotifications", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("RetrySearchNotifications", status).Observe(duration)
+	}()
+
+	notifications, err := s.repository.ListPendingMatches(ctx, time.Now().Add(-s.options.RetryInterval), s.options.MaxAttempts, 100)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		s.logger.ErrorLogger.Error("Failed to load pending saved search matches", utils.Err(err))
+		return
+	}
+
+	notified := 0
+	for _, notification := range notifications {
+		if err := s.notify(ctx, notification); err != nil {
+			status = "error"
+			span.RecordError(err)
+			continue
+		}
+		notified++
+	}
+
+	span.SetAttributes(
+		attribute.Int("saved_search.pending", len(notifications)),
+		attribute.Int("saved_search.notified", notified),
+	)
+}
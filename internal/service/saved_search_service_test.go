@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/repository"
+)
+
+type matchKey struct{ searchID, adID int64 }
+
+type pendingMatch struct {
+	notification *domain.SearchNotification
+	attempts     int
+	notified     bool
+}
+
+type fakeSavedSearchRepository struct {
+	repository.SavedSearchRepository
+
+	mu        sync.Mutex
+	searches  []*domain.SavedSearch
+	matches   map[matchKey]*pendingMatch
+	recordErr error
+}
+
+func (r *fakeSavedSearchRepository) ListSearchesForCategory(ctx context.Context, category string) ([]*domain.SavedSearch, error) {
+	return r.searches, nil
+}
+
+func (r *fakeSavedSearchRepository) RecordMatch(ctx context.Context, notification *domain.SearchNotification) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recordErr != nil {
+		return false, r.recordErr
+	}
+	key := matchKey{notification.SavedSearchID, notification.Ad.ID}
+	if _, ok := r.matches[key]; ok {
+		return false, nil
+	}
+	r.matches[key] = &pendingMatch{notification: notification}
+	return true, nil
+}
+
+func (r *fakeSavedSearchRepository) MarkNotified(ctx context.Context, searchID int64, adID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	match := r.matches[matchKey{searchID, adID}]
+	match.attempts++
+	match.notified = true
+	return nil
+}
+
+func (r *fakeSavedSearchRepository) RecordNotifyFailure(ctx context.Context, searchID int64, adID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.matches[matchKey{searchID, adID}].attempts++
+	return nil
+}
+
+func (r *fakeSavedSearchRepository) ListPendingMatches(ctx context.Context, matchedBefore time.Time, maxAttempts int, limit int) ([]*domain.SearchNotification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []*domain.SearchNotification
+	for _, match := range r.matches {
+		if !match.notified && match.attempts < maxAttempts {
+			pending = append(pending, match.notification)
+		}
+	}
+	return pending, nil
+}
+
+type fakeNotifier struct {
+	err  error
+	sent []*domain.SearchNotification
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notification *domain.SearchNotification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// publish relays the event and sends the notifications it queued, as the
+// worker would.
+func publish(t *testing.T, svc *savedSearchService, event *domain.AdEvent) {
+	t.Helper()
+	if err := svc.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() = %v", err)
+	}
+	for {
+		select {
+		case notification := <-svc.queue:
+			svc.send(notification)
+		default:
+			return
+		}
+	}
+}
+
+func TestSavedSearchPublishFailsWithoutRecordedMatch(t *testing.T) {
+	repo := &fakeSavedSearchRepository{
+		searches:  []*domain.SavedSearch{{ID: 1}},
+		matches:   make(map[matchKey]*pendingMatch),
+		recordErr: errors.New("database down"),
+	}
+	svc := NewSavedSearchService(repo, &fakeNotifier{}, testMetrics, testLoggers(), SavedSearchOptions{}).(*savedSearchService)
+
+	tests := []struct {
+		name    string
+		event   *domain.AdEvent
+		wantErr bool
+	}{
+		{name: "created", event: &domain.AdEvent{Type: domain.AdCreated, Ad: &domain.Ad{ID: 7, Active: true}}, wantErr: true},
+		{name: "inactive", event: &domain.AdEvent{Type: domain.AdUpdated, Ad: &domain.Ad{ID: 7}}},
+		{name: "deleted", event: &domain.AdEvent{Type: domain.AdDeleted, Ad: &domain.Ad{ID: 7, Active: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.Publish(context.Background(), tt.event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Publish() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSavedSearchNotifyFailureStaysPending(t *testing.T) {
+	repo := &fakeSavedSearchRepository{
+		searches: []*domain.SavedSearch{{ID: 1, UserID: "u1", Name: "cars"}},
+		matches:  make(map[matchKey]*pendingMatch),
+	}
+	notifier := &fakeNotifier{err: errors.New("gateway down")}
+	svc := NewSavedSearchService(repo, notifier, testMetrics, testLoggers(), SavedSearchOptions{MaxAttempts: 3}).(*savedSearchService)
+
+	event := &domain.AdEvent{Type: domain.AdCreated, AdID: 7, Ad: &domain.Ad{ID: 7, Active: true}}
+	publish(t, svc, event)
+	if len(notifier.sent) != 0 {
+		t.Fatalf("sent %d notifications while the notifier fails", len(notifier.sent))
+	}
+
+	// An update of the ad must not count as notified.
+	publish(t, svc, event)
+	if match := repo.matches[matchKey{1, 7}]; match.notified {
+		t.Fatal("failed notification was marked notified")
+	}
+
+	notifier.err = nil
+	svc.retryPending()
+	if len(notifier.sent) != 1 {
+		t.Fatalf("retry sent %d notifications, want 1", len(notifier.sent))
+	}
+	if match := repo.matches[matchKey{1, 7}]; !match.notified {
+		t.Fatal("retried notification was not marked notified")
+	}
+
+	svc.retryPending()
+	if len(notifier.sent) != 1 {
+		t.Fatalf("notified match was sent again")
+	}
+}
+
+func TestSavedSearchRetryGivesUp(t *testing.T) {
+	repo := &fakeSavedSearchRepository{
+		searches: []*domain.SavedSearch{{ID: 1}},
+		matches:  make(map[matchKey]*pendingMatch),
+	}
+	notifier := &fakeNotifier{err: errors.New("gateway down")}
+	svc := NewSavedSearchService(repo, notifier, testMetrics, testLoggers(), SavedSearchOptions{MaxAttempts: 2}).(*savedSearchService)
+
+	publish(t, svc, &domain.AdEvent{Type: domain.AdCreated, AdID: 7, Ad: &domain.Ad{ID: 7, Active: true}})
+	for i := 0; i < 3; i++ {
+		svc.retryPending()
+	}
+	if attempts := repo.matches[matchKey{1, 7}].attempts; attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -15,8 +16,9 @@ import (
 )
 
 var (
-	ErrInvalidID  = errors.New("invalid ad ID")
-	ErrAdNotFound = errors.New("ad not found")
+	ErrInvalidID         = errors.New("invalid ad ID")
+	ErrAdNotFound        = errors.New("ad not found")
+	ErrExternalRefExists = errors.New("another ad has the same external reference")
 )
 
 type PaginationResult struct {
@@ -28,29 +30,38 @@ type PaginationResult struct {
 }
 
 type AdService interface {
-	GetAllAds(ctx context.Context, limit int, offset int, sortBy string, order string) (*PaginationResult, error)
+	GetAllAds(ctx context.Context, limit int, offset int, sortBy string, order string, filter domain.AdFilter) (*PaginationResult, error)
 	GetAdByID(ctx context.Context, id int64) (*domain.Ad, error)
+	GetAdsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Ad, error)
 	CreateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error)
 	UpdateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error)
 	DeleteAd(ctx context.Context, id int64) error
+	EnforceCampaignBudget(ctx context.Context, campaignID int64) ([]int64, error)
+	ResumeCampaignAds(ctx context.Context, campaignID int64) ([]int64, error)
+	ExportAds(ctx context.Context, filter domain.AdFilter, fn func(*domain.Ad) error) error
+	ExpandAds(ctx context.Context, ads []*domain.Ad, expand []string) (*domain.AdRelations, error)
 }
 
 type adService struct {
 	repository repository.AdRepository
+	attributes repository.AttributeRepository
+	campaigns  repository.CampaignRepository
 	metrics    *metrics.ServiceMetrics
 	tracer     trace.Tracer
 }
 
-func NewAdService(repository repository.AdRepository, metrics *metrics.ServiceMetrics) AdService {
+func NewAdService(repository repository.AdRepository, attributes repository.AttributeRepository, campaigns repository.CampaignRepository, metrics *metrics.ServiceMetrics) AdService {
 	tracer := otel.Tracer("ad-service/service")
 	return &adService{
 		repository: repository,
+		attributes: attributes,
+		campaigns:  campaigns,
 		metrics:    metrics,
 		tracer:     tracer,
 	}
 }
 
-func (s *adService) GetAllAds(ctx context.Context, limit int, offset int, sortBy string, order string) (*PaginationResult, error) {
+func (s *adService) GetAllAds(ctx context.Context, limit int, offset int, sortBy string, order string, filter domain.AdFilter) (*PaginationResult, error) {
 	ctx, span := s.tracer.Start(ctx, "Service GetAllAds")
 	defer span.End()
 
@@ -63,14 +74,26 @@ func (s *adService) GetAllAds(ctx context.Context, limit int, offset int, sortBy
 		s.metrics.MethodDuration.WithLabelValues("GetAllAds", status).Observe(duration)
 	}()
 
-	ads, err := s.repository.GetAllAds(ctx, limit, offset, sortBy, order)
+	if err := validateSort(sortBy, &order); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := validateAdFilter(&filter); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	ads, err := s.repository.GetAllAds(ctx, limit, offset, sortBy, order, filter)
 	if err != nil {
 		status = "error"
 		span.RecordError(err)
 		return nil, err
 	}
 
-	totalCount, err := s.repository.CountAds(ctx)
+	totalCount, err := s.repository.CountAds(ctx, filter)
 	if err != nil {
 		status = "error"
 		span.RecordError(err)
@@ -105,6 +128,100 @@ func (s *adService) GetAllAds(ctx context.Context, limit int, offset int, sortBy
 	}, nil
 }
 
+// ExportAds passes every ad matching the filter to fn in id order without
+// holding them in memory. Audience targeting does not apply to exports.
+func (s *adService) ExportAds(ctx context.Context, filter domain.AdFilter, fn func(*domain.Ad) error) error {
+	ctx, span := s.tracer.Start(ctx, "Service ExportAds")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ExportAds", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ExportAds", status).Observe(duration)
+	}()
+
+	filter.Audience = nil
+	if err := validateAdFilter(&filter); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return err
+	}
+
+	if err := s.repository.StreamAds(ctx, filter, fn); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// ExpandAds loads the resources related to ads that expand names, such as
+// their campaigns. Ads without the relation are skipped.
+func (s *adService) ExpandAds(ctx context.Context, ads []*domain.Ad, expand []string) (*domain.AdRelations, error) {
+	ctx, span := s.tracer.Start(ctx, "Service ExpandAds")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ExpandAds", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ExpandAds", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.StringSlice("ads.expand", expand))
+
+	relations := &domain.AdRelations{}
+	for _, name := range expand {
+		switch name {
+		case domain.AdExpandCampaign:
+			var ids []int64
+			for _, ad := range ads {
+				if ad.CampaignID != nil && !slices.Contains(ids, *ad.CampaignID) {
+					ids = append(ids, *ad.CampaignID)
+				}
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			campaigns, err := s.campaigns.GetCampaignsByIDs(ctx, ids)
+			if err != nil {
+				status = "error"
+				span.RecordError(err)
+				return nil, err
+			}
+			relations.Campaigns = campaigns
+		case domain.AdExpandCategory:
+			relations.Categories = make(map[string][]*domain.AttributeDefinition)
+			for _, ad := range ads {
+				if ad.Category == "" {
+					continue
+				}
+				if _, ok := relations.Categories[ad.Category]; ok {
+					continue
+				}
+				defs, err := s.attributes.ListDefinitions(ctx, ad.Category)
+				if err != nil {
+					status = "error"
+					span.RecordError(err)
+					return nil, err
+				}
+				relations.Categories[ad.Category] = defs
+			}
+		default:
+			err := &ValidationError{Field: "expand", Message: "unknown expansion " + name}
+			status = "invalid"
+			span.SetAttributes(attribute.String("error", err.Error()))
+			return nil, err
+		}
+	}
+	return relations, nil
+}
+
 func (s *adService) GetAdByID(ctx context.Context, id int64) (*domain.Ad, error) {
 	if id <= 0 {
 		err := ErrInvalidID
@@ -140,6 +257,42 @@ func (s *adService) GetAdByID(ctx context.Context, id int64) (*domain.Ad, error)
 	return ad, nil
 }
 
+// GetAdsByIDs loads a batch of ads keyed by id. Unknown ids are left out
+// rather than reported as errors.
+func (s *adService) GetAdsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Ad, error) {
+	ctx, span := s.tracer.Start(ctx, "Service GetAdsByIDs")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("GetAdsByIDs", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("GetAdsByIDs", status).Observe(duration)
+	}()
+
+	for _, id := range ids {
+		if id <= 0 {
+			status = "invalid"
+			return nil, ErrInvalidID
+		}
+	}
+
+	ads, err := s.repository.GetAdsByIDs(ctx, ids)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("ads.requested", len(ids)),
+		attribute.Int("ads.found", len(ads)),
+	)
+	return ads, nil
+}
+
 func (s *adService) CreateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, error) {
 	ctx, span := s.tracer.Start(ctx, "Service CreateAd")
 	defer span.End()
@@ -153,13 +306,34 @@ func (s *adService) CreateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, er
 		s.metrics.MethodDuration.WithLabelValues("CreateAd", status).Observe(duration)
 	}()
 
+	if err := s.validateAd(ctx, ad); err != nil {
+		if errors.As(err, new(*ValidationError)) {
+			status = "invalid"
+			span.SetAttributes(attribute.String("error", err.Error()))
+		} else {
+			status = "error"
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+
 	createdAd, err := s.repository.CreateAd(ctx, ad)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			status = "conflict"
+			span.SetAttributes(attribute.String("error", ErrExternalRefExists.Error()))
+			return nil, ErrExternalRefExists
+		}
 		status = "error"
 		span.RecordError(err)
 		span.SetAttributes(attribute.String("error", "failed to create ad"))
 		return nil, err
 	}
+	if err := s.enforceAdBudget(ctx, createdAd); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.Int64("ad.id", createdAd.ID),
@@ -187,6 +361,17 @@ func (s *adService) UpdateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, er
 		s.metrics.MethodDuration.WithLabelValues("UpdateAd", status).Observe(duration)
 	}()
 
+	if err := s.validateAd(ctx, ad); err != nil {
+		if errors.As(err, new(*ValidationError)) {
+			status = "invalid"
+			span.SetAttributes(attribute.String("error", err.Error()))
+		} else {
+			status = "error"
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+
 	updatedAd, err := s.repository.UpdateAd(ctx, ad)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -194,11 +379,21 @@ func (s *adService) UpdateAd(ctx context.Context, ad *domain.Ad) (*domain.Ad, er
 			span.SetAttributes(attribute.String("error", "ad not found"))
 			return nil, ErrAdNotFound
 		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			status = "conflict"
+			span.SetAttributes(attribute.String("error", ErrExternalRefExists.Error()))
+			return nil, ErrExternalRefExists
+		}
 		status = "error"
 		span.RecordError(err)
 		span.SetAttributes(attribute.String("error", "failed to update ad"))
 		return nil, err
 	}
+	if err := s.enforceAdBudget(ctx, updatedAd); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.Int64("ad.id", updatedAd.ID),
@@ -242,3 +437,171 @@ func (s *adService) DeleteAd(ctx context.Context, id int64) error {
 	span.SetAttributes(attribute.Int64("ad.id", id))
 	return nil
 }
+
+// EnforceCampaignBudget pauses every active ad of the campaign once its
+// budget is spent and returns the ids of the paused ads. Ads paused for the
+// daily budget are paused until the next UTC day only.
+func (s *adService) EnforceCampaignBudget(ctx context.Context, campaignID int64) ([]int64, error) {
+	if campaignID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service EnforceCampaignBudget")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("EnforceCampaignBudget", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("EnforceCampaignBudget", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("campaign.id", campaignID))
+
+	campaign, err := s.campaigns.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "campaign not found"))
+			return nil, ErrCampaignNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	paused, err := pauseSpentCampaign(ctx, s.repository, campaign)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("ads.paused", len(paused)))
+	return paused, nil
+}
+
+// pauseSpentCampaign pauses the active ads of a campaign that has no budget
+// left, until the next day when only the daily budget is spent.
+func pauseSpentCampaign(ctx context.Context, ads repository.AdRepository, campaign *domain.Campaign) ([]int64, error) {
+	if !campaign.BudgetExhausted() {
+		return nil, nil
+	}
+
+	var until *time.Time
+	if !campaign.TotalBudgetExhausted() {
+		tomorrow := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		until = &tomorrow
+	}
+	return ads.PauseAdsByCampaign(ctx, campaign.ID, until)
+}
+
+// enforceAdBudget pauses an ad that was just saved as active when its
+// campaign cannot spend, so that writing an ad does not bring back one the
+// budget paused.
+func (s *adService) enforceAdBudget(ctx context.Context, ad *domain.Ad) error {
+	if !ad.Active || ad.CampaignID == nil {
+		return nil
+	}
+	paused, err := s.EnforceCampaignBudget(ctx, *ad.CampaignID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(paused, ad.ID) {
+		ad.Active = false
+	}
+	return nil
+}
+
+// ResumeCampaignAds reactivates the ads EnforceCampaignBudget paused for the
+// daily budget once their pause has run out and returns their ids.
+func (s *adService) ResumeCampaignAds(ctx context.Context, campaignID int64) ([]int64, error) {
+	if campaignID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service ResumeCampaignAds")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ResumeCampaignAds", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ResumeCampaignAds", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("campaign.id", campaignID))
+
+	campaign, err := s.campaigns.GetCampaignByID(ctx, campaignID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "campaign not found"))
+			return nil, ErrCampaignNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// The total budget may have run out while the ads were paused.
+	if campaign.TotalBudgetExhausted() {
+		return nil, nil
+	}
+
+	resumed, err := s.repository.ResumeAdsByCampaign(ctx, campaignID)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("ads.resumed", len(resumed)))
+	return resumed, nil
+}
+
+func (s *adService) validateAd(ctx context.Context, ad *domain.Ad) error {
+	if err := validateAdFields(ad); err != nil {
+		return err
+	}
+	if ad.CampaignID != nil {
+		if _, err := s.campaigns.GetCampaignByID(ctx, *ad.CampaignID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return &ValidationError{Field: "campaign_id", Message: "campaign not found"}
+			}
+			return err
+		}
+	}
+
+	if ad.Category == "" {
+		return validateAdAttributes(nil, ad)
+	}
+
+	defs, err := s.attributes.ListDefinitions(ctx, ad.Category)
+	if err != nil {
+		return err
+	}
+	return validateAdAttributes(defs, ad)
+}
+
+// validateAdFields checks what can be checked without looking up other
+// records.
+func validateAdFields(ad *domain.Ad) error {
+	if err := validateExternalRef(ad); err != nil {
+		return err
+	}
+	if err := validateAdTags(ad); err != nil {
+		return err
+	}
+	if err := validateTargetURL(ad); err != nil {
+		return err
+	}
+	if err := validateAdWeight(ad); err != nil {
+		return err
+	}
+	return validateTargeting(ad)
+}
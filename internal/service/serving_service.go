@@ -0,0 +1,295 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/cache"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+)
+
+const maxServeCount = 10
+
+type ServingOptions struct {
+	FrequencyCap    int
+	FrequencyWindow time.Duration
+	CandidateTTL    time.Duration
+	MaxCandidates   int
+}
+
+type ServeRequest struct {
+	// Slot names the placement the ads are shown in. It does not affect the
+	// selection and only labels the response and its tracking links.
+	Slot     string
+	ViewerID string
+	Count    int
+	Exclude  []int64
+	Filter   domain.AdFilter
+}
+
+type ServingService interface {
+	Serve(ctx context.Context, req ServeRequest) ([]*domain.Ad, error)
+}
+
+type servingCandidates struct {
+	ads       []*domain.Ad
+	campaigns map[int64]*domain.Campaign
+	loadedAt  time.Time
+}
+
+// servingService picks ads for display with weighted random rotation. The
+// eligible candidates are cached in memory for a short time so selection
+// does not hit the database on every request; per-viewer frequency caps
+// live in Redis so they hold across instances.
+type servingService struct {
+	ads       repository.AdRepository
+	campaigns repository.CampaignRepository
+	cache     cache.Cache
+	metrics   *metrics.ServiceMetrics
+	tracer    trace.Tracer
+	options   ServingOptions
+
+	// mu guards candidates, which are swapped whole once a reload is done.
+	// Concurrent reloads share one query through loads.
+	mu         sync.Mutex
+	candidates *servingCandidates
+	loads      singleflight.Group
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+func NewServingService(ads repository.AdRepository, campaigns repository.CampaignRepository, cache cache.Cache, metrics *metrics.ServiceMetrics, options ServingOptions) ServingService {
+	if options.FrequencyCap <= 0 {
+		options.FrequencyCap = 3
+	}
+	if options.FrequencyWindow <= 0 {
+		options.FrequencyWindow = 24 * time.Hour
+	}
+	if options.CandidateTTL <= 0 {
+		options.CandidateTTL = 30 * time.Second
+	}
+	if options.MaxCandidates <= 0 {
+		options.MaxCandidates = 1000
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	return &servingService{
+		ads:       ads,
+		campaigns: campaigns,
+		cache:     cache,
+		metrics:   metrics,
+		tracer:    tracer,
+		options:   options,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (s *servingService) Serve(ctx context.Context, req ServeRequest) ([]*domain.Ad, error) {
+	ctx, span := s.tracer.Start(ctx, "Service Serve")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("Serve", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("Serve", status).Observe(duration)
+	}()
+
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	if req.Count > maxServeCount {
+		req.Count = maxServeCount
+	}
+
+	span.SetAttributes(
+		attribute.String("serve.slot", req.Slot),
+		attribute.Int("serve.count", req.Count),
+	)
+
+	if err := validateAdFilter(&req.Filter); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	candidates, err := s.loadCandidates(ctx)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	excluded := make(map[int64]struct{}, len(req.Exclude))
+	for _, id := range req.Exclude {
+		excluded[id] = struct{}{}
+	}
+
+	now := time.Now().UTC()
+	eligible := make([]*domain.Ad, 0, len(candidates.ads))
+	for _, ad := range candidates.ads {
+		if _, ok := excluded[ad.ID]; ok {
+			continue
+		}
+		if !req.Filter.Matches(ad) {
+			continue
+		}
+		if ad.CampaignID != nil {
+			campaign, ok := candidates.campaigns[*ad.CampaignID]
+			if !ok || !campaign.CanSpend(now) {
+				continue
+			}
+		}
+		eligible = append(eligible, ad)
+	}
+
+	// Anonymous viewers cannot be told apart, so they are neither capped
+	// nor counted.
+	if req.ViewerID != "" {
+		eligible = s.applyFrequencyCap(ctx, span, req.ViewerID, eligible)
+	}
+	selected := s.pickWeighted(eligible, req.Count)
+
+	if req.ViewerID != "" {
+		for _, ad := range selected {
+			if _, err := s.cache.Increment(ctx, frequencyKey(req.ViewerID, ad.ID), s.options.FrequencyWindow); err != nil {
+				span.RecordError(err)
+			}
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("serve.eligible", len(eligible)),
+		attribute.Int("serve.selected", len(selected)),
+	)
+	return selected, nil
+}
+
+func (s *servingService) loadCandidates(ctx context.Context) (*servingCandidates, error) {
+	s.mu.Lock()
+	candidates := s.candidates
+	s.mu.Unlock()
+	if candidates != nil && time.Since(candidates.loadedAt) < s.options.CandidateTTL {
+		return candidates, nil
+	}
+
+	loaded, err, _ := s.loads.Do("candidates", func() (interface{}, error) {
+		// The reload is shared, so it must not fail because the request
+		// that happened to start it went away.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		candidates, err := s.queryCandidates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.candidates = candidates
+		s.mu.Unlock()
+		return candidates, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loaded.(*servingCandidates), nil
+}
+
+func (s *servingService) queryCandidates(ctx context.Context) (*servingCandidates, error) {
+	ads, err := s.ads.GetServableAds(ctx, s.options.MaxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]struct{})
+	var campaignIDs []int64
+	for _, ad := range ads {
+		if ad.CampaignID == nil {
+			continue
+		}
+		if _, ok := seen[*ad.CampaignID]; !ok {
+			seen[*ad.CampaignID] = struct{}{}
+			campaignIDs = append(campaignIDs, *ad.CampaignID)
+		}
+	}
+
+	campaigns, err := s.campaigns.GetCampaignsByIDs(ctx, campaignIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &servingCandidates{ads: ads, campaigns: campaigns, loadedAt: time.Now()}, nil
+}
+
+// applyFrequencyCap drops ads the viewer has already seen too often. When
+// Redis is unavailable ads are served uncapped rather than not at all.
+func (s *servingService) applyFrequencyCap(ctx context.Context, span trace.Span, viewerID string, ads []*domain.Ad) []*domain.Ad {
+	if len(ads) == 0 {
+		return ads
+	}
+
+	keys := make([]string, len(ads))
+	for i, ad := range ads {
+		keys[i] = frequencyKey(viewerID, ad.ID)
+	}
+
+	counts, err := s.cache.GetMany(ctx, keys)
+	if err != nil {
+		span.RecordError(err)
+		return ads
+	}
+
+	capped := ads[:0:0]
+	for i, ad := range ads {
+		if n, err := strconv.Atoi(counts[keys[i]]); err == nil && n >= s.options.FrequencyCap {
+			continue
+		}
+		capped = append(capped, ad)
+	}
+	return capped
+}
+
+// pickWeighted draws up to count distinct ads, each with a probability
+// proportional to its weight.
+func (s *servingService) pickWeighted(ads []*domain.Ad, count int) []*domain.Ad {
+	pool := make([]*domain.Ad, len(ads))
+	copy(pool, ads)
+
+	total := 0
+	for _, ad := range pool {
+		total += ad.Weight
+	}
+
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+
+	selected := make([]*domain.Ad, 0, count)
+	for len(selected) < count && len(pool) > 0 && total > 0 {
+		target := s.rand.Intn(total)
+		for i, ad := range pool {
+			target -= ad.Weight
+			if target < 0 {
+				selected = append(selected, ad)
+				total -= ad.Weight
+				pool = append(pool[:i], pool[i+1:]...)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+func frequencyKey(viewerID string, adID int64) string {
+	return fmt.Sprintf("freq:%s:%d", viewerID, adID)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/cache"
+	"ad-service/internal/repository"
+)
+
+type fakeServableAdRepository struct {
+	repository.AdRepository
+
+	ads     []*domain.Ad
+	queries atomic.Int32
+	release chan struct{}
+}
+
+func (r *fakeServableAdRepository) GetServableAds(ctx context.Context, limit int) ([]*domain.Ad, error) {
+	r.queries.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.ads, nil
+}
+
+type fakeCampaignRepository struct {
+	repository.CampaignRepository
+}
+
+func (fakeCampaignRepository) GetCampaignsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Campaign, error) {
+	return map[int64]*domain.Campaign{}, nil
+}
+
+type fakeCache struct {
+	cache.Cache
+
+	increments atomic.Int32
+}
+
+func (c *fakeCache) GetMany(ctx context.Context, keys []string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (c *fakeCache) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	c.increments.Add(1)
+	return 1, nil
+}
+
+func TestServe(t *testing.T) {
+	ads := []*domain.Ad{
+		{ID: 1, Weight: 1, Category: "cars"},
+		{ID: 2, Weight: 5, Category: "bikes"},
+		{ID: 3, Weight: 1, Category: "cars"},
+	}
+	tests := []struct {
+		name  string
+		req   ServeRequest
+		count int
+	}{
+		{name: "without slot", req: ServeRequest{Count: 2}, count: 2},
+		{name: "with slot", req: ServeRequest{Slot: "sidebar", Count: 5}, count: 3},
+		{name: "excluded", req: ServeRequest{Count: 5, Exclude: []int64{2}}, count: 2},
+		{name: "filtered", req: ServeRequest{Count: 5, Filter: domain.AdFilter{Category: "bikes"}}, count: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewServingService(&fakeServableAdRepository{ads: ads}, fakeCampaignRepository{}, &fakeCache{}, testMetrics, ServingOptions{})
+			selected, err := svc.Serve(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Serve() error = %v", err)
+			}
+			if len(selected) != tt.count {
+				t.Errorf("Serve() selected %d ads, want %d", len(selected), tt.count)
+			}
+		})
+	}
+}
+
+func TestServeSharesCandidateReload(t *testing.T) {
+	repo := &fakeServableAdRepository{ads: []*domain.Ad{{ID: 1, Weight: 1}}, release: make(chan struct{})}
+	svc := NewServingService(repo, fakeCampaignRepository{}, &fakeCache{}, testMetrics, ServingOptions{}).(*servingService)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.Serve(context.Background(), ServeRequest{}); err != nil {
+				t.Errorf("Serve() error = %v", err)
+			}
+		}()
+	}
+
+	for repo.queries.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// Selection must not wait for the reload in progress.
+	picked := make(chan []*domain.Ad)
+	go func() { picked <- svc.pickWeighted([]*domain.Ad{{ID: 9, Weight: 1}}, 1) }()
+	select {
+	case ads := <-picked:
+		if len(ads) != 1 {
+			t.Errorf("pickWeighted() picked %d ads, want 1", len(ads))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pickWeighted() blocked on the candidate reload")
+	}
+
+	close(repo.release)
+	wg.Wait()
+	if n := repo.queries.Load(); n != 1 {
+		t.Errorf("candidates were queried %d times, want 1", n)
+	}
+}
+
+func TestServeCountsViewers(t *testing.T) {
+	ads := []*domain.Ad{{ID: 1, Weight: 1}, {ID: 2, Weight: 1}}
+	tests := []struct {
+		name       string
+		viewer     string
+		increments int32
+	}{
+		{name: "anonymous", increments: 0},
+		{name: "known viewer", viewer: "viewer", increments: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeCache{}
+			svc := NewServingService(&fakeServableAdRepository{ads: ads}, fakeCampaignRepository{}, c, testMetrics, ServingOptions{})
+			if _, err := svc.Serve(context.Background(), ServeRequest{ViewerID: tt.viewer, Count: 2}); err != nil {
+				t.Fatalf("Serve() error = %v", err)
+			}
+			if got := c.increments.Load(); got != tt.increments {
+				t.Errorf("counted %d impressions, want %d", got, tt.increments)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/infrastructure/pubsub"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrStreamClosed = errors.New("event stream is closed")
+
+type StreamOptions struct {
+	Channel      string
+	BufferSize   int
+	ClientBuffer int
+}
+
+// StreamSubscription receives live ad events. Replay holds buffered events
+// newer than the requested resume point; Gap is set when older events were
+// already evicted and the subscriber has to reload its state. Events is
+// closed when the subscriber falls behind or the stream shuts down.
+type StreamSubscription struct {
+	Events <-chan *domain.AdEvent
+	Replay []*domain.AdEvent
+	Gap    bool
+
+	id     uint64
+	events chan *domain.AdEvent
+	filter domain.StreamFilter
+	stream *streamService
+}
+
+func (s *StreamSubscription) Close() {
+	s.stream.unsubscribe(s)
+}
+
+type StreamService interface {
+	Publisher
+	Subscribe(ctx context.Context, lastEventID int64, filter domain.StreamFilter) (*StreamSubscription, error)
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// streamService fans ad events out to live subscribers on every instance.
+// The outbox relay publishes events to Redis, each instance receives them
+// back, keeps the most recent ones in a ring buffer for resuming clients and
+// forwards them to its local subscribers.
+type streamService struct {
+	pubsub  pubsub.PubSub
+	metrics *metrics.ServiceMetrics
+	logger  *logger.Loggers
+	tracer  trace.Tracer
+	options StreamOptions
+
+	mu          sync.Mutex
+	ring        *eventRing
+	subscribers map[uint64]*StreamSubscription
+	nextID      uint64
+	closed      bool
+
+	subscription pubsub.Subscription
+	doneCh       chan struct{}
+}
+
+func NewStreamService(pubsub pubsub.PubSub, metrics *metrics.ServiceMetrics, logger *logger.Loggers, options StreamOptions) StreamService {
+	if options.Channel == "" {
+		options.Channel = "ad-events"
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = 1000
+	}
+	if options.ClientBuffer <= 0 {
+		options.ClientBuffer = 64
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	return &streamService{
+		pubsub:      pubsub,
+		metrics:     metrics,
+		logger:      logger,
+		tracer:      tracer,
+		options:     options,
+		ring:        newEventRing(options.BufferSize),
+		subscribers: make(map[uint64]*StreamSubscription),
+		doneCh:      make(chan struct{}),
+	}
+}
+
+// Publish sends an event to all instances. It is called by the outbox relay
+// of the instance holding the relay lock.
+func (s *streamService) Publish(ctx context.Context, event *domain.AdEvent) error {
+	ctx, span := s.tracer.Start(ctx, "Service PublishStreamEvent")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("PublishStreamEvent", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("PublishStreamEvent", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("event.id", event.ID),
+		attribute.String("event.type", string(event.Type)),
+	)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to encode stream event: %w", err)
+	}
+
+	if err := s.pubsub.Publish(ctx, s.options.Channel, payload); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to publish stream event: %w", err)
+	}
+	return nil
+}
+
+func (s *streamService) Subscribe(ctx context.Context, lastEventID int64, filter domain.StreamFilter) (*StreamSubscription, error) {
+	_, span := s.tracer.Start(ctx, "Service SubscribeStream")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("SubscribeStream", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("SubscribeStream", status).Observe(duration)
+	}()
+
+	if err := validateStreamFilter(&filter); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	events := make(chan *domain.AdEvent, s.options.ClientBuffer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		status = "error"
+		return nil, ErrStreamClosed
+	}
+
+	s.nextID++
+	sub := &StreamSubscription{
+		Events: events,
+		id:     s.nextID,
+		events: events,
+		filter: filter,
+		stream: s,
+	}
+	if lastEventID > 0 {
+		replay, gap := s.ring.since(lastEventID)
+		sub.Gap = gap
+		for _, event := range replay {
+			if filter.Matches(event) {
+				sub.Replay = append(sub.Replay, event)
+			}
+		}
+	}
+	s.subscribers[sub.id] = sub
+
+	span.SetAttributes(
+		attribute.Int64("stream.last_event_id", lastEventID),
+		attribute.Int("stream.replayed", len(sub.Replay)),
+	)
+	return sub, nil
+}
+
+func (s *streamService) unsubscribe(sub *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub.id]; ok {
+		delete(s.subscribers, sub.id)
+		close(sub.events)
+	}
+}
+
+func (s *streamService) Start() {
+	s.subscription = s.pubsub.Subscribe(context.Background(), s.options.Channel)
+	go s.run()
+}
+
+func (s *streamService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	alreadyClosed := s.closed
+	s.closed = true
+	for id, sub := range s.subscribers {
+		delete(s.subscribers, id)
+		close(sub.events)
+	}
+	s.mu.Unlock()
+
+	if !alreadyClosed && s.subscription != nil {
+		if err := s.subscription.Close(); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-s.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *streamService) run() {
+	defer close(s.doneCh)
+
+	for payload := range s.subscription.Messages() {
+		var event domain.AdEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			s.logger.ErrorLogger.Error("Failed to decode stream event", utils.Err(err))
+			continue
+		}
+		s.broadcast(&event)
+	}
+}
+
+// broadcast forwards an event to every matching subscriber. Subscribers that
+// cannot keep up are disconnected and resume with Last-Event-ID.
+func (s *streamService) broadcast(event *domain.AdEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ring.push(event) {
+		// Relayed again after a failed publish or a relay takeover.
+		return
+	}
+
+	for id, sub := range s.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(s.subscribers, id)
+			close(sub.events)
+			s.metrics.MethodCount.WithLabelValues("StreamEvent", "dropped").Inc()
+		}
+	}
+}
+
+// eventRing keeps the most recent events in the order they were relayed.
+// Outbox ids are assigned when a row is inserted, not when it commits, so
+// an event can arrive after one with a higher id; the ring remembers the
+// ids it holds instead of only the highest one.
+type eventRing struct {
+	events []*domain.AdEvent
+	start  int
+	count  int
+	seen   map[int64]struct{}
+	lastID int64
+	// floor is the newest id that may be missing from the ring: events up
+	// to it were evicted or happened before the first one seen.
+	floor int64
+	// evicted is the highest id that left the ring. Older events can no
+	// longer be told apart from duplicates.
+	evicted int64
+}
+
+func newEventRing(size int) *eventRing {
+	return &eventRing{events: make([]*domain.AdEvent, size), seen: make(map[int64]struct{}, size)}
+}
+
+// push adds an event and reports whether it is new.
+func (r *eventRing) push(event *domain.AdEvent) bool {
+	if _, ok := r.seen[event.ID]; ok || event.ID <= r.evicted {
+		return false
+	}
+	if r.lastID == 0 {
+		r.floor = event.ID - 1
+	}
+	if event.ID > r.lastID {
+		r.lastID = event.ID
+	}
+
+	r.seen[event.ID] = struct{}{}
+	if r.count == len(r.events) {
+		oldest := r.events[r.start]
+		delete(r.seen, oldest.ID)
+		r.evicted = max(r.evicted, oldest.ID)
+		r.floor = max(r.floor, oldest.ID)
+		r.events[r.start] = event
+		r.start = (r.start + 1) % len(r.events)
+		return true
+	}
+	r.events[(r.start+r.count)%len(r.events)] = event
+	r.count++
+	return true
+}
+
+// since returns the buffered events a client that last saw id has missed
+// and whether events before the oldest buffered one may have been lost.
+// When id is still buffered, everything relayed after it is returned,
+// including events with lower ids that committed late. An instance that
+// has not seen any event yet cannot tell and reports a gap.
+func (r *eventRing) since(id int64) ([]*domain.AdEvent, bool) {
+	var events []*domain.AdEvent
+	if _, ok := r.seen[id]; ok {
+		found := false
+		for i := 0; i < r.count; i++ {
+			event := r.events[(r.start+i)%len(r.events)]
+			if found {
+				events = append(events, event)
+			}
+			found = found || event.ID == id
+		}
+		return events, false
+	}
+
+	for i := 0; i < r.count; i++ {
+		event := r.events[(r.start+i)%len(r.events)]
+		if event.ID > id {
+			events = append(events, event)
+		}
+	}
+	return events, r.lastID == 0 || id < r.floor
+}
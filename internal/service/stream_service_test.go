@@ -0,0 +1,75 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"ad-service/internal/domain"
+)
+
+func eventIDs(events []*domain.AdEvent) []int64 {
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func TestEventRingPush(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		pushed []int64
+		kept   []int64
+	}{
+		{name: "in order", size: 4, pushed: []int64{1, 2, 3}, kept: []int64{1, 2, 3}},
+		{name: "late commit", size: 4, pushed: []int64{11, 10, 12}, kept: []int64{11, 10, 12}},
+		{name: "duplicate", size: 4, pushed: []int64{1, 2, 2, 1}, kept: []int64{1, 2}},
+		{name: "evicted", size: 2, pushed: []int64{1, 2, 3, 1}, kept: []int64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := newEventRing(tt.size)
+			for _, id := range tt.pushed {
+				ring.push(&domain.AdEvent{ID: id})
+			}
+			events, _ := ring.since(0)
+			if got := eventIDs(events); !slices.Equal(got, tt.kept) {
+				t.Errorf("ring holds %v, want %v", got, tt.kept)
+			}
+		})
+	}
+}
+
+func TestEventRingSince(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		pushed []int64
+		since  int64
+		want   []int64
+		gap    bool
+	}{
+		{name: "empty ring", size: 4, since: 5, gap: true},
+		{name: "resume", size: 4, pushed: []int64{1, 2, 3}, since: 1, want: []int64{2, 3}},
+		{name: "late commit after resume point", size: 4, pushed: []int64{11, 12, 10}, since: 12, want: []int64{10}},
+		{name: "unknown id", size: 4, pushed: []int64{5, 6}, since: 3, want: []int64{5, 6}, gap: true},
+		{name: "evicted", size: 2, pushed: []int64{1, 2, 3, 4}, since: 1, want: []int64{3, 4}, gap: true},
+		{name: "before first seen", size: 4, pushed: []int64{5, 6}, since: 4, want: []int64{5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring := newEventRing(tt.size)
+			for _, id := range tt.pushed {
+				ring.push(&domain.AdEvent{ID: id})
+			}
+			events, gap := ring.since(tt.since)
+			if got := eventIDs(events); !slices.Equal(got, tt.want) {
+				t.Errorf("since(%d) = %v, want %v", tt.since, got, tt.want)
+			}
+			if gap != tt.gap {
+				t.Errorf("since(%d) gap = %v, want %v", tt.since, gap, tt.gap)
+			}
+		})
+	}
+}
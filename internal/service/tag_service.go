@@ -0,0 +1,56 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TagService interface {
+	ListTags(ctx context.Context, limit int) ([]*domain.TagCount, error)
+}
+
+type tagService struct {
+	repository repository.TagRepository
+	metrics    *metrics.ServiceMetrics
+	tracer     trace.Tracer
+}
+
+func NewTagService(repository repository.TagRepository, metrics *metrics.ServiceMetrics) TagService {
+	tracer := otel.Tracer("ad-service/service")
+	return &tagService{
+		repository: repository,
+		metrics:    metrics,
+		tracer:     tracer,
+	}
+}
+
+func (s *tagService) ListTags(ctx context.Context, limit int) ([]*domain.TagCount, error) {
+	ctx, span := s.tracer.Start(ctx, "Service ListTags")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListTags", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListTags", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int("tags.limit", limit))
+
+	tags, err := s.repository.ListTagCounts(ctx, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrInvalidEventType   = errors.New("invalid tracking event type")
+	ErrTrackingBufferFull = errors.New("tracking buffer is full")
+	ErrTrackingStopped    = errors.New("tracking is stopped")
+)
+
+type TrackingOptions struct {
+	FlushInterval time.Duration
+	BatchSize     int
+	BufferSize    int
+	DedupWindow   time.Duration
+	// MaxFlushAttempts is how often a batch is written, and how often its
+	// spend is recorded, before it is dropped.
+	MaxFlushAttempts int
+}
+
+// SpendRecorder is notified about every batch of tracked events once it has
+// been persisted. A batch whose spend failed to be recorded is recorded
+// again, so recording must be idempotent.
+type SpendRecorder interface {
+	RecordSpend(ctx context.Context, events []*domain.TrackingEvent) error
+}
+
+type unspentBatch struct {
+	events   []*domain.TrackingEvent
+	attempts int
+}
+
+type TrackingService interface {
+	Track(ctx context.Context, event *domain.TrackingEvent) (bool, error)
+	GetStats(ctx context.Context, adID int64, days int) (*domain.AdStats, error)
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// trackingService buffers impressions and clicks in memory and writes them
+// to the database in batches from a background worker. Repeated events of
+// the same viewer for the same ad are dropped within the dedup window.
+type trackingService struct {
+	repository repository.TrackingRepository
+	ads        repository.AdRepository
+	spend      SpendRecorder
+	metrics    *metrics.ServiceMetrics
+	logger     *logger.Loggers
+	tracer     trace.Tracer
+	options    TrackingOptions
+
+	mu     sync.Mutex
+	buffer []*domain.TrackingEvent
+	seen   map[string]time.Time
+	// failedFlushes counts the failed writes of the batch at the front of
+	// the buffer.
+	failedFlushes int
+	// stopped is set under mu by Stop, so no event is added to the buffer
+	// after the last flush.
+	stopped bool
+
+	// unspent holds the persisted batches whose spend is not recorded yet.
+	// Only the worker touches it.
+	unspent []*unspentBatch
+
+	flushCh  chan struct{}
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func NewTrackingService(repository repository.TrackingRepository, ads repository.AdRepository, spend SpendRecorder, metrics *metrics.ServiceMetrics, logger *logger.Loggers, options TrackingOptions) TrackingService {
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = 5 * time.Second
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 500
+	}
+	if options.BufferSize < options.BatchSize {
+		options.BufferSize = options.BatchSize * 20
+	}
+	if options.DedupWindow <= 0 {
+		options.DedupWindow = 30 * time.Minute
+	}
+	if options.MaxFlushAttempts <= 0 {
+		options.MaxFlushAttempts = 5
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	return &trackingService{
+		repository: repository,
+		ads:        ads,
+		spend:      spend,
+		metrics:    metrics,
+		logger:     logger,
+		tracer:     tracer,
+		options:    options,
+		seen:       make(map[string]time.Time),
+		flushCh:    make(chan struct{}, 1),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+func (s *trackingService) Track(ctx context.Context, event *domain.TrackingEvent) (bool, error) {
+	if event.AdID <= 0 {
+		return false, ErrInvalidID
+	}
+	if event.Type != domain.TrackingImpression && event.Type != domain.TrackingClick {
+		return false, ErrInvalidEventType
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service Track")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("Track", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("Track", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", event.AdID),
+		attribute.String("event.type", string(event.Type)),
+	)
+
+	if _, err := s.ads.GetAdByID(ctx, event.AdID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "ad not found"))
+			return false, ErrAdNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return false, err
+	}
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if event.ID == "" {
+		id, err := newTrackingEventID()
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return false, err
+		}
+		event.ID = id
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		status = "stopped"
+		span.SetAttributes(attribute.String("error", "tracking is stopped"))
+		return false, ErrTrackingStopped
+	}
+	key := trackingKey(event)
+	if last, ok := s.seen[key]; ok && event.OccurredAt.Sub(last) < s.options.DedupWindow {
+		s.mu.Unlock()
+		status = "duplicate"
+		return false, nil
+	}
+	if len(s.buffer) >= s.options.BufferSize {
+		s.mu.Unlock()
+		status = "dropped"
+		span.SetAttributes(attribute.String("error", "tracking buffer is full"))
+		return false, ErrTrackingBufferFull
+	}
+	s.seen[key] = event.OccurredAt
+	s.buffer = append(s.buffer, event)
+	full := len(s.buffer) >= s.options.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flushCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return true, nil
+}
+
+func (s *trackingService) GetStats(ctx context.Context, adID int64, days int) (*domain.AdStats, error) {
+	if adID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service GetStats")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("GetStats", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("GetStats", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.Int("stats.days", days),
+	)
+
+	if _, err := s.ads.GetAdByID(ctx, adID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "ad not found"))
+			return nil, ErrAdNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	daily, err := s.repository.GetDailyStats(ctx, adID, since)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	stats := &domain.AdStats{AdID: adID, Days: daily}
+	if stats.Days == nil {
+		stats.Days = []*domain.DailyAdStats{}
+	}
+	for _, day := range daily {
+		stats.Impressions += day.Impressions
+		stats.Clicks += day.Clicks
+	}
+	stats.CTR = domain.ClickThroughRate(stats.Impressions, stats.Clicks)
+
+	return stats, nil
+}
+
+func (s *trackingService) Start() {
+	go s.run()
+}
+
+func (s *trackingService) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		s.mu.Lock()
+		s.stopped = true
+		s.mu.Unlock()
+		close(s.stopCh)
+	})
+	select {
+	case <-s.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *trackingService) run() {
+	defer close(s.doneCh)
+
+	ticker := time.NewTicker(s.options.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+			s.pruneSeen()
+		case <-s.flushCh:
+			s.flush()
+		case <-s.stopCh:
+			s.flush()
+			return
+		}
+	}
+}
+
+func (s *trackingService) flush() {
+	defer s.recordSpend()
+
+	for {
+		s.mu.Lock()
+		n := len(s.buffer)
+		if n == 0 {
+			s.mu.Unlock()
+			return
+		}
+		if n > s.options.BatchSize {
+			n = s.options.BatchSize
+		}
+		batch := make([]*domain.TrackingEvent, n)
+		copy(batch, s.buffer[:n])
+		s.buffer = s.buffer[n:]
+		s.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := s.repository.InsertEvents(ctx, batch)
+		cancel()
+		if err != nil {
+			s.logger.ErrorLogger.Error("Failed to flush tracking events", utils.Err(err), "events", len(batch))
+			s.requeue(batch)
+			return
+		}
+
+		s.mu.Lock()
+		s.failedFlushes = 0
+		s.mu.Unlock()
+
+		s.unspent = append(s.unspent, &unspentBatch{events: batch})
+	}
+}
+
+// recordSpend records the spend of the persisted batches in order. A batch
+// that fails is kept for the next flush until MaxFlushAttempts.
+func (s *trackingService) recordSpend() {
+	for len(s.unspent) > 0 {
+		batch := s.unspent[0]
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := s.spend.RecordSpend(ctx, batch.events)
+		cancel()
+		if err != nil {
+			batch.attempts++
+			s.logger.ErrorLogger.Error("Failed to record campaign spend", utils.Err(err), "events", len(batch.events), "attempts", batch.attempts)
+			if batch.attempts < s.options.MaxFlushAttempts {
+				return
+			}
+			s.logger.ErrorLogger.Error("Dropping campaign spend, recording keeps failing", "events", len(batch.events))
+			s.metrics.TrackingEventsDropped.WithLabelValues("spend_failed").Add(float64(len(batch.events)))
+		}
+		s.unspent = s.unspent[1:]
+	}
+}
+
+// requeue puts a failed batch back in front of the buffer so it is retried on
+// the next flush. The batch is dropped after MaxFlushAttempts or if newer
+// events already filled the buffer.
+func (s *trackingService) requeue(batch []*domain.TrackingEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failedFlushes++
+	reason := "flush_failed"
+	switch {
+	case s.failedFlushes >= s.options.MaxFlushAttempts:
+		s.logger.ErrorLogger.Error("Dropping tracking events, flush keeps failing", "events", len(batch), "attempts", s.failedFlushes)
+	case len(s.buffer)+len(batch) > s.options.BufferSize:
+		reason = "buffer_full"
+		s.logger.ErrorLogger.Error("Dropping tracking events, buffer is full", "events", len(batch))
+	default:
+		s.buffer = append(batch, s.buffer...)
+		return
+	}
+
+	s.failedFlushes = 0
+	s.metrics.TrackingEventsDropped.WithLabelValues(reason).Add(float64(len(batch)))
+	// Dropped events were never counted, so they must not hold back the
+	// next event of the same viewer.
+	for _, event := range batch {
+		key := trackingKey(event)
+		if last, ok := s.seen[key]; ok && last.Equal(event.OccurredAt) {
+			delete(s.seen, key)
+		}
+	}
+}
+
+func newTrackingEventID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func trackingKey(event *domain.TrackingEvent) string {
+	return fmt.Sprintf("%s:%d:%s", event.Type, event.AdID, event.ViewerID)
+}
+
+func (s *trackingService) pruneSeen() {
+	cutoff := time.Now().UTC().Add(-s.options.DedupWindow)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, last := range s.seen {
+		if last.Before(cutoff) {
+			delete(s.seen, key)
+		}
+	}
+}
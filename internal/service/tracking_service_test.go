@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/repository"
+)
+
+type fakeTrackingRepository struct {
+	repository.TrackingRepository
+
+	failures int
+	inserted []*domain.TrackingEvent
+}
+
+func (r *fakeTrackingRepository) InsertEvents(ctx context.Context, events []*domain.TrackingEvent) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("insert failed")
+	}
+	r.inserted = append(r.inserted, events...)
+	return nil
+}
+
+type fakeAdRepository struct {
+	repository.AdRepository
+}
+
+func (r *fakeAdRepository) GetAdByID(ctx context.Context, id int64) (*domain.Ad, error) {
+	return &domain.Ad{ID: id}, nil
+}
+
+type fakeSpendRecorder struct{}
+
+func (fakeSpendRecorder) RecordSpend(ctx context.Context, events []*domain.TrackingEvent) error {
+	return nil
+}
+
+// flakySpendRecorder fails the first failures calls and counts the events
+// it recorded.
+type flakySpendRecorder struct {
+	failures int
+	recorded int
+}
+
+func (r *flakySpendRecorder) RecordSpend(ctx context.Context, events []*domain.TrackingEvent) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("spend failed")
+	}
+	r.recorded += len(events)
+	return nil
+}
+
+func TestTrackingFlushRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		flushes  int
+		inserted int
+		buffered int
+		tracked  bool
+	}{
+		{name: "written", failures: 0, flushes: 1, inserted: 1},
+		{name: "retried", failures: 2, flushes: 3, inserted: 1},
+		{name: "still failing", failures: 2, flushes: 2, buffered: 1},
+		{name: "dropped", failures: 3, flushes: 3, tracked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTrackingRepository{failures: tt.failures}
+			svc := NewTrackingService(repo, &fakeAdRepository{}, fakeSpendRecorder{}, testMetrics, testLoggers(), TrackingOptions{MaxFlushAttempts: 3}).(*trackingService)
+
+			event := &domain.TrackingEvent{AdID: 1, Type: domain.TrackingImpression, ViewerID: "viewer"}
+			if ok, err := svc.Track(context.Background(), event); !ok || err != nil {
+				t.Fatalf("Track() = %v, %v", ok, err)
+			}
+			for i := 0; i < tt.flushes; i++ {
+				svc.flush()
+			}
+
+			if len(repo.inserted) != tt.inserted {
+				t.Errorf("inserted %d events, want %d", len(repo.inserted), tt.inserted)
+			}
+			if len(svc.buffer) != tt.buffered {
+				t.Errorf("buffer holds %d events, want %d", len(svc.buffer), tt.buffered)
+			}
+			again := &domain.TrackingEvent{AdID: 1, Type: domain.TrackingImpression, ViewerID: "viewer"}
+			ok, err := svc.Track(context.Background(), again)
+			if err != nil {
+				t.Fatalf("Track() error = %v", err)
+			}
+			if ok != tt.tracked {
+				t.Errorf("Track() after flush = %v, want %v", ok, tt.tracked)
+			}
+		})
+	}
+}
+
+func TestTrackingStopTwice(t *testing.T) {
+	repo := &fakeTrackingRepository{}
+	svc := NewTrackingService(repo, &fakeAdRepository{}, fakeSpendRecorder{}, testMetrics, testLoggers(), TrackingOptions{})
+	svc.Start()
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if err := svc.Stop(ctx); err != nil {
+			t.Errorf("Stop() #%d error = %v", i+1, err)
+		}
+		cancel()
+	}
+}
+
+func TestTrackingSpendRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		flushes  int
+		recorded int
+		unspent  int
+	}{
+		{name: "recorded", failures: 0, flushes: 1, recorded: 1},
+		{name: "retried", failures: 2, flushes: 3, recorded: 1},
+		{name: "still failing", failures: 2, flushes: 2, unspent: 1},
+		{name: "dropped", failures: 3, flushes: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spend := &flakySpendRecorder{failures: tt.failures}
+			svc := NewTrackingService(&fakeTrackingRepository{}, &fakeAdRepository{}, spend, testMetrics, testLoggers(), TrackingOptions{MaxFlushAttempts: 3}).(*trackingService)
+
+			event := &domain.TrackingEvent{AdID: 1, Type: domain.TrackingClick, ViewerID: "viewer"}
+			if ok, err := svc.Track(context.Background(), event); !ok || err != nil {
+				t.Fatalf("Track() = %v, %v", ok, err)
+			}
+			for i := 0; i < tt.flushes; i++ {
+				svc.flush()
+			}
+
+			if spend.recorded != tt.recorded {
+				t.Errorf("recorded spend of %d events, want %d", spend.recorded, tt.recorded)
+			}
+			if len(svc.unspent) != tt.unspent {
+				t.Errorf("%d batches wait for their spend, want %d", len(svc.unspent), tt.unspent)
+			}
+		})
+	}
+}
+
+func TestTrackAfterStop(t *testing.T) {
+	repo := &fakeTrackingRepository{}
+	svc := NewTrackingService(repo, &fakeAdRepository{}, fakeSpendRecorder{}, testMetrics, testLoggers(), TrackingOptions{})
+	svc.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := svc.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	event := &domain.TrackingEvent{AdID: 1, Type: domain.TrackingClick, ViewerID: "viewer"}
+	if _, err := svc.Track(context.Background(), event); !errors.Is(err, ErrTrackingStopped) {
+		t.Errorf("Track() error = %v, want %v", err, ErrTrackingStopped)
+	}
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxTagsPerAd = 20
+	maxAdWeight  = 1000
+)
+
+var (
+	attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	categoryPattern      = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,99}$`)
+	tagPattern           = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+	localePattern        = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+	countryPattern       = regexp.MustCompile(`^[A-Z]{2}$`)
+	segmentKeyPattern    = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+)
+
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func validateAttributeDefinition(def *domain.AttributeDefinition) error {
+	if !categoryPattern.MatchString(def.Category) {
+		return &ValidationError{Field: "category", Message: "must be a lowercase slug"}
+	}
+	if !attributeNamePattern.MatchString(def.Name) {
+		return &ValidationError{Field: "name", Message: "must start with a letter and contain only lowercase letters, digits and underscores"}
+	}
+	if !def.Type.IsValid() {
+		return &ValidationError{Field: "type", Message: fmt.Sprintf("unsupported type %q", def.Type)}
+	}
+	if def.Type == domain.AttributeTypeEnum && len(def.EnumValues) == 0 {
+		return &ValidationError{Field: "enum_values", Message: "required for enum attributes"}
+	}
+	if def.Type != domain.AttributeTypeEnum && len(def.EnumValues) > 0 {
+		return &ValidationError{Field: "enum_values", Message: "only allowed for enum attributes"}
+	}
+	if (def.Min != nil || def.Max != nil) && !def.Type.IsNumeric() {
+		return &ValidationError{Field: "min", Message: "range is only allowed for numeric attributes"}
+	}
+	if def.Min != nil && def.Max != nil && *def.Min > *def.Max {
+		return &ValidationError{Field: "min", Message: "must not be greater than max"}
+	}
+	return nil
+}
+
+func validateAdAttributes(defs []*domain.AttributeDefinition, ad *domain.Ad) error {
+	if ad.Category == "" {
+		if len(ad.Attributes) > 0 {
+			return &ValidationError{Field: "attributes", Message: "attributes require a category"}
+		}
+		return nil
+	}
+	if !categoryPattern.MatchString(ad.Category) {
+		return &ValidationError{Field: "category", Message: "must be a lowercase slug"}
+	}
+
+	byName := make(map[string]*domain.AttributeDefinition, len(defs))
+	for _, def := range defs {
+		byName[def.Name] = def
+	}
+
+	names := make([]string, 0, len(ad.Attributes))
+	for name := range ad.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			return &ValidationError{Field: "attributes." + name, Message: fmt.Sprintf("unknown attribute for category %q", ad.Category)}
+		}
+	}
+
+	for _, def := range defs {
+		value, ok := ad.Attributes[def.Name]
+		if !ok || value == nil {
+			if def.Required {
+				return &ValidationError{Field: "attributes." + def.Name, Message: "is required"}
+			}
+			continue
+		}
+		if msg := checkAttributeValue(def, value); msg != "" {
+			return &ValidationError{Field: "attributes." + def.Name, Message: msg}
+		}
+	}
+	return nil
+}
+
+func checkAttributeValue(def *domain.AttributeDefinition, value interface{}) string {
+	switch def.Type {
+	case domain.AttributeTypeString:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case domain.AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case domain.AttributeTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		for _, allowed := range def.EnumValues {
+			if s == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %v", def.EnumValues)
+	case domain.AttributeTypeNumber, domain.AttributeTypeInteger:
+		n, ok := value.(float64)
+		if !ok {
+			return "must be a number"
+		}
+		if def.Type == domain.AttributeTypeInteger && n != math.Trunc(n) {
+			return "must be an integer"
+		}
+		if def.Min != nil && n < *def.Min {
+			return fmt.Sprintf("must be at least %v", *def.Min)
+		}
+		if def.Max != nil && n > *def.Max {
+			return fmt.Sprintf("must be at most %v", *def.Max)
+		}
+	}
+	return ""
+}
+
+// normalizeTags lowercases, trims, deduplicates and sorts tags so that the
+// same set of tags is always stored and compared the same way.
+func normalizeTags(field string, tags []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if !tagPattern.MatchString(tag) {
+			return nil, &ValidationError{Field: field, Message: fmt.Sprintf("invalid tag %q", tag)}
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func validateAdTags(ad *domain.Ad) error {
+	tags, err := normalizeTags("tags", ad.Tags)
+	if err != nil {
+		return err
+	}
+	if len(tags) > maxTagsPerAd {
+		return &ValidationError{Field: "tags", Message: fmt.Sprintf("at most %d tags are allowed", maxTagsPerAd)}
+	}
+	ad.Tags = tags
+	return nil
+}
+
+// maxExternalRefLength matches the indexed ads.external_ref column.
+const maxExternalRefLength = 191
+
+func validateExternalRef(ad *domain.Ad) error {
+	ad.ExternalRef = strings.TrimSpace(ad.ExternalRef)
+	if utf8.RuneCountInString(ad.ExternalRef) > maxExternalRefLength {
+		return &ValidationError{Field: "external_ref", Message: fmt.Sprintf("must be at most %d characters", maxExternalRefLength)}
+	}
+	return nil
+}
+
+func validateTargetURL(ad *domain.Ad) error {
+	if ad.TargetURL == "" {
+		return nil
+	}
+	u, err := url.Parse(ad.TargetURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ValidationError{Field: "target_url", Message: "must be an absolute http or https URL"}
+	}
+	return nil
+}
+
+func validateCampaign(campaign *domain.Campaign) error {
+	if strings.TrimSpace(campaign.Name) == "" {
+		return &ValidationError{Field: "name", Message: "is required"}
+	}
+	if campaign.TotalBudget <= 0 {
+		return &ValidationError{Field: "total_budget", Message: "must be greater than zero"}
+	}
+	if campaign.DailyBudget < 0 {
+		return &ValidationError{Field: "daily_budget", Message: "must not be negative"}
+	}
+	if campaign.DailyBudget > campaign.TotalBudget {
+		return &ValidationError{Field: "daily_budget", Message: "must not exceed total_budget"}
+	}
+	if campaign.PacingMode == "" {
+		campaign.PacingMode = domain.PacingStandard
+	}
+	if !campaign.PacingMode.IsValid() {
+		return &ValidationError{Field: "pacing_mode", Message: "must be standard or accelerated"}
+	}
+	if campaign.StartDate.IsZero() {
+		campaign.StartDate = time.Now().UTC()
+	}
+	if campaign.EndDate != nil && !campaign.EndDate.After(campaign.StartDate) {
+		return &ValidationError{Field: "end_date", Message: "must be after start_date"}
+	}
+	return nil
+}
+
+func validateAdWeight(ad *domain.Ad) error {
+	if ad.Weight == 0 {
+		ad.Weight = 1
+	}
+	if ad.Weight < 0 || ad.Weight > maxAdWeight {
+		return &ValidationError{Field: "weight", Message: fmt.Sprintf("must be between 1 and %d", maxAdWeight)}
+	}
+	return nil
+}
+
+// validateTargeting normalizes the targeting of an ad in place: locales are
+// lowercased, countries uppercased, and empty targeting is dropped.
+func validateTargeting(ad *domain.Ad) error {
+	t := ad.Targeting
+	if t == nil {
+		return nil
+	}
+
+	for i, locale := range t.Locales {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		if !localePattern.MatchString(locale) {
+			return &ValidationError{Field: "targeting.locales", Message: fmt.Sprintf("invalid locale %q", locale)}
+		}
+		t.Locales[i] = locale
+	}
+	for i, country := range t.Countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if !countryPattern.MatchString(country) {
+			return &ValidationError{Field: "targeting.countries", Message: fmt.Sprintf("invalid country code %q", country)}
+		}
+		t.Countries[i] = country
+	}
+	for _, device := range t.Devices {
+		if !device.IsValid() {
+			return &ValidationError{Field: "targeting.devices", Message: fmt.Sprintf("unsupported device type %q", device)}
+		}
+	}
+	for _, window := range t.Hours {
+		if window.Start < 0 || window.Start > 23 || window.End < 0 || window.End > 24 || window.Start == window.End {
+			return &ValidationError{Field: "targeting.hours", Message: "windows need distinct start and end hours between 0 and 24"}
+		}
+	}
+	if t.Timezone != "" {
+		if _, err := time.LoadLocation(t.Timezone); err != nil {
+			return &ValidationError{Field: "targeting.timezone", Message: fmt.Sprintf("unknown timezone %q", t.Timezone)}
+		}
+	}
+	for key, values := range t.Segments {
+		if !segmentKeyPattern.MatchString(key) {
+			return &ValidationError{Field: "targeting.segments", Message: fmt.Sprintf("invalid segment key %q", key)}
+		}
+		if len(values) == 0 {
+			return &ValidationError{Field: "targeting.segments." + key, Message: "needs at least one value"}
+		}
+	}
+
+	if t.IsEmpty() {
+		ad.Targeting = nil
+	}
+	return nil
+}
+
+func validateVariant(variant *domain.Variant) error {
+	if !attributeNamePattern.MatchString(variant.Name) {
+		return &ValidationError{Field: "name", Message: "must start with a letter and contain only lowercase letters, digits and underscores"}
+	}
+	if strings.TrimSpace(variant.Title) == "" {
+		return &ValidationError{Field: "title", Message: "is required"}
+	}
+	if variant.Weight == 0 {
+		variant.Weight = 1
+	}
+	if variant.Weight < 0 || variant.Weight > maxAdWeight {
+		return &ValidationError{Field: "weight", Message: fmt.Sprintf("must be between 1 and %d", maxAdWeight)}
+	}
+	return nil
+}
+
+func validateWebhookSubscription(subscription *domain.WebhookSubscription) error {
+	u, err := url.Parse(subscription.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ValidationError{Field: "url", Message: "must be an absolute http or https URL"}
+	}
+	if len(subscription.URL) > 2048 {
+		return &ValidationError{Field: "url", Message: "must be at most 2048 characters"}
+	}
+	if subscription.Secret != "" && (len(subscription.Secret) < 16 || len(subscription.Secret) > 128) {
+		return &ValidationError{Field: "secret", Message: "must be between 16 and 128 characters"}
+	}
+
+	seen := make(map[domain.AdEventType]bool, len(subscription.Events))
+	events := make([]domain.AdEventType, 0, len(subscription.Events))
+	for _, eventType := range subscription.Events {
+		if !eventType.IsValid() {
+			return &ValidationError{Field: "events", Message: "unknown event " + string(eventType)}
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			events = append(events, eventType)
+		}
+	}
+	subscription.Events = events
+	return nil
+}
+
+func validateStreamFilter(filter *domain.StreamFilter) error {
+	for _, eventType := range filter.Events {
+		if !eventType.IsValid() {
+			return &ValidationError{Field: "events", Message: "unknown event " + string(eventType)}
+		}
+	}
+	for _, id := range filter.AdIDs {
+		if id <= 0 {
+			return &ValidationError{Field: "ad_id", Message: "must contain positive integers"}
+		}
+	}
+	return validateAdFilter(&filter.Filter)
+}
+
+// sortColumns lists the columns ads can be sorted by. The sort column and
+// order are written into the ORDER BY clause as is.
+var sortColumns = map[string]bool{
+	"id":             true,
+	"title":          true,
+	"price":          true,
+	"category":       true,
+	"weight":         true,
+	"favorite_count": true,
+	"created_at":     true,
+	"updated_at":     true,
+}
+
+func validateSort(sortBy string, order *string) error {
+	if !sortColumns[sortBy] {
+		return &ValidationError{Field: "sortBy", Message: "unsupported sort column"}
+	}
+	upper := strings.ToUpper(*order)
+	if upper != "ASC" && upper != "DESC" {
+		return &ValidationError{Field: "order", Message: "must be ASC or DESC"}
+	}
+	*order = upper
+	return nil
+}
+
+func validateAdFilter(filter *domain.AdFilter) error {
+	if filter.Category != "" && !categoryPattern.MatchString(filter.Category) {
+		return &ValidationError{Field: "category", Message: "must be a lowercase slug"}
+	}
+	for _, attr := range filter.Attributes {
+		if !attributeNamePattern.MatchString(attr.Name) {
+			return &ValidationError{Field: "attr." + attr.Name, Message: "invalid attribute name"}
+		}
+	}
+
+	tags, err := normalizeTags("tags", filter.Tags)
+	if err != nil {
+		return err
+	}
+	filter.Tags = tags
+
+	switch filter.TagMode {
+	case "":
+		filter.TagMode = domain.TagMatchAny
+	case domain.TagMatchAny, domain.TagMatchAll:
+	default:
+		return &ValidationError{Field: "tags_mode", Message: "must be any or all"}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// significanceLevel is the alpha used when comparing variants.
+const significanceLevel = 0.05
+
+var (
+	ErrVariantNotFound = errors.New("variant not found")
+	ErrVariantExists   = errors.New("variant already exists")
+	ErrNoVariants      = errors.New("ad has no variants")
+)
+
+type VariantService interface {
+	ListVariants(ctx context.Context, adID int64) ([]*domain.Variant, error)
+	CreateVariant(ctx context.Context, variant *domain.Variant) (*domain.Variant, error)
+	DeleteVariant(ctx context.Context, adID int64, id int64) error
+	AssignVariant(ctx context.Context, adID int64, viewerID string) (*domain.Variant, error)
+	RecordEvent(ctx context.Context, adID int64, id int64, eventType domain.TrackingEventType) error
+	GetResults(ctx context.Context, adID int64) (*domain.VariantResults, error)
+}
+
+type variantService struct {
+	repository repository.VariantRepository
+	ads        repository.AdRepository
+	metrics    *metrics.ServiceMetrics
+	tracer     trace.Tracer
+}
+
+func NewVariantService(repository repository.VariantRepository, ads repository.AdRepository, metrics *metrics.ServiceMetrics) VariantService {
+	tracer := otel.Tracer("ad-service/service")
+	return &variantService{
+		repository: repository,
+		ads:        ads,
+		metrics:    metrics,
+		tracer:     tracer,
+	}
+}
+
+func (s *variantService) ListVariants(ctx context.Context, adID int64) ([]*domain.Variant, error) {
+	if adID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service ListVariants")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListVariants", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListVariants", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	variants, err := s.listVariants(ctx, adID)
+	if err != nil {
+		status = variantErrorStatus(err)
+		span.RecordError(err)
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (s *variantService) CreateVariant(ctx context.Context, variant *domain.Variant) (*domain.Variant, error) {
+	if variant.AdID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service CreateVariant")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("CreateVariant", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("CreateVariant", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", variant.AdID),
+		attribute.String("variant.name", variant.Name),
+	)
+
+	if err := validateVariant(variant); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := s.ensureAd(ctx, variant.AdID); err != nil {
+		status = variantErrorStatus(err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	created, err := s.repository.CreateVariant(ctx, variant)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			status = "conflict"
+			span.SetAttributes(attribute.String("error", "variant already exists"))
+			return nil, ErrVariantExists
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("variant.id", created.ID))
+	return created, nil
+}
+
+func (s *variantService) DeleteVariant(ctx context.Context, adID int64, id int64) error {
+	if adID <= 0 || id <= 0 {
+		return ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service DeleteVariant")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("DeleteVariant", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("DeleteVariant", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.Int64("variant.id", id),
+	)
+
+	if err := s.repository.DeleteVariant(ctx, adID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "variant not found"))
+			return ErrVariantNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (s *variantService) AssignVariant(ctx context.Context, adID int64, viewerID string) (*domain.Variant, error) {
+	if adID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service AssignVariant")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("AssignVariant", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("AssignVariant", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	variants, err := s.listVariants(ctx, adID)
+	if err != nil {
+		status = variantErrorStatus(err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	variant := domain.AssignVariant(adID, viewerID, variants)
+	if variant == nil {
+		status = "not_found"
+		span.SetAttributes(attribute.String("error", "ad has no variants"))
+		return nil, ErrNoVariants
+	}
+
+	span.SetAttributes(attribute.Int64("variant.id", variant.ID))
+	return variant, nil
+}
+
+func (s *variantService) RecordEvent(ctx context.Context, adID int64, id int64, eventType domain.TrackingEventType) error {
+	if adID <= 0 || id <= 0 {
+		return ErrInvalidID
+	}
+	if eventType != domain.TrackingImpression && eventType != domain.TrackingClick {
+		return ErrInvalidEventType
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service RecordVariantEvent")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("RecordVariantEvent", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("RecordVariantEvent", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", adID),
+		attribute.Int64("variant.id", id),
+		attribute.String("event.type", string(eventType)),
+	)
+
+	if err := s.repository.IncrementCounter(ctx, adID, id, eventType); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "variant not found"))
+			return ErrVariantNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (s *variantService) GetResults(ctx context.Context, adID int64) (*domain.VariantResults, error) {
+	if adID <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service GetVariantResults")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("GetVariantResults", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("GetVariantResults", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("ad.id", adID))
+
+	variants, err := s.listVariants(ctx, adID)
+	if err != nil {
+		status = variantErrorStatus(err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return domain.CompareVariants(adID, variants, significanceLevel), nil
+}
+
+func (s *variantService) listVariants(ctx context.Context, adID int64) ([]*domain.Variant, error) {
+	if err := s.ensureAd(ctx, adID); err != nil {
+		return nil, err
+	}
+	variants, err := s.repository.ListVariants(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if variants == nil {
+		variants = []*domain.Variant{}
+	}
+	return variants, nil
+}
+
+func (s *variantService) ensureAd(ctx context.Context, adID int64) error {
+	if _, err := s.ads.GetAdByID(ctx, adID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAdNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func variantErrorStatus(err error) string {
+	if errors.Is(err, ErrAdNotFound) || errors.Is(err, ErrVariantNotFound) {
+		return "not_found"
+	}
+	return "error"
+}
@@ -0,0 +1,553 @@
+package service
+
+import (
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/metrics"
+	"ad-service/internal/infrastructure/webhook"
+	"ad-service/internal/repository"
+	"ad-service/pkg/logger"
+	"ad-service/pkg/utils"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const maxDeliveryErrorLength = 1000
+
+type WebhookOptions struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Lease          time.Duration
+}
+
+type WebhookService interface {
+	Publisher
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID int64) error
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// webhookService turns ad events into durable deliveries, one per matching
+// subscription, and delivers them from a background worker. Failed attempts
+// are retried with exponential backoff until MaxAttempts, after which the
+// delivery is moved to the dead-letter list.
+type webhookService struct {
+	repository repository.WebhookRepository
+	sender     webhook.Sender
+	metrics    *metrics.ServiceMetrics
+	logger     *logger.Loggers
+	tracer     trace.Tracer
+	options    WebhookOptions
+
+	wakeCh   chan struct{}
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewWebhookService(repository repository.WebhookRepository, sender webhook.Sender, metrics *metrics.ServiceMetrics, logger *logger.Loggers, options WebhookOptions) WebhookService {
+	if options.PollInterval <= 0 {
+		options.PollInterval = time.Second
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 50
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 8
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = 10 * time.Second
+	}
+	if options.MaxBackoff < options.InitialBackoff {
+		options.MaxBackoff = time.Hour
+	}
+	if options.Lease <= 0 {
+		options.Lease = time.Minute
+	}
+
+	tracer := otel.Tracer("ad-service/service")
+	return &webhookService{
+		repository: repository,
+		sender:     sender,
+		metrics:    metrics,
+		logger:     logger,
+		tracer:     tracer,
+		options:    options,
+		wakeCh:     make(chan struct{}, 1),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	ctx, span := s.tracer.Start(ctx, "Service ListSubscriptions")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListSubscriptions", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListSubscriptions", status).Observe(duration)
+	}()
+
+	subscriptions, err := s.repository.ListSubscriptions(ctx)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	result := make([]*domain.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, withoutSecret(subscription))
+	}
+	return result, nil
+}
+
+func (s *webhookService) GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service GetSubscription")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("GetSubscription", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("GetSubscription", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("webhook.id", id))
+
+	subscription, err := s.repository.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "webhook subscription not found"))
+			return nil, ErrWebhookNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return withoutSecret(subscription), nil
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	ctx, span := s.tracer.Start(ctx, "Service CreateSubscription")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("CreateSubscription", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("CreateSubscription", status).Observe(duration)
+	}()
+
+	if err := validateWebhookSubscription(subscription); err != nil {
+		status = "invalid"
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+
+	if subscription.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			status = "error"
+			span.RecordError(err)
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+	subscription.Active = true
+
+	created, err := s.repository.CreateSubscription(ctx, subscription)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("webhook.id", created.ID))
+	return created, nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service DeleteSubscription")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("DeleteSubscription", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("DeleteSubscription", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("webhook.id", id))
+
+	if err := s.repository.DeleteSubscription(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "webhook subscription not found"))
+			return ErrWebhookNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, subscriptionID int64, deliveryStatus domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	if subscriptionID < 0 {
+		return nil, ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service ListDeliveries")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("ListDeliveries", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("ListDeliveries", status).Observe(duration)
+	}()
+
+	if deliveryStatus != "" && !deliveryStatus.IsValid() {
+		status = "invalid"
+		err := &ValidationError{Field: "status", Message: "must be pending, delivered or dead"}
+		span.SetAttributes(attribute.String("error", err.Error()))
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	if subscriptionID > 0 {
+		if _, err := s.repository.GetSubscriptionByID(ctx, subscriptionID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				status = "not_found"
+				span.SetAttributes(attribute.String("error", "webhook subscription not found"))
+				return nil, ErrWebhookNotFound
+			}
+			status = "error"
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	deliveries, err := s.repository.ListDeliveries(ctx, subscriptionID, deliveryStatus, limit)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID int64) error {
+	if deliveryID <= 0 {
+		return ErrInvalidID
+	}
+
+	ctx, span := s.tracer.Start(ctx, "Service Redeliver")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("Redeliver", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("Redeliver", status).Observe(duration)
+	}()
+
+	span.SetAttributes(attribute.Int64("delivery.id", deliveryID))
+
+	if err := s.repository.Redeliver(ctx, deliveryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			status = "not_found"
+			span.SetAttributes(attribute.String("error", "webhook delivery not found"))
+			return ErrDeliveryNotFound
+		}
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+
+	s.wake()
+	return nil
+}
+
+// Publish stores a delivery of the event for every subscription that wants
+// it before it returns, so an event the relay marks published is never
+// lost. An error leaves the event to be relayed again.
+func (s *webhookService) Publish(ctx context.Context, event *domain.AdEvent) error {
+	ctx, span := s.tracer.Start(ctx, "Service EnqueueWebhookEvent")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("EnqueueWebhookEvent", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("EnqueueWebhookEvent", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("ad.id", event.AdID),
+		attribute.String("event.type", string(event.Type)),
+	)
+
+	subscriptions, err := s.repository.ListSubscriptions(ctx)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if subscription.Wants(event.Type) {
+			deliveries = append(deliveries, &domain.WebhookDelivery{
+				SubscriptionID: subscription.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Payload:        payload,
+			})
+		}
+	}
+
+	if err := s.repository.EnqueueDeliveries(ctx, deliveries); err != nil {
+		status = "error"
+		span.RecordError(err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("deliveries.count", len(deliveries)))
+	if len(deliveries) > 0 {
+		s.wake()
+	}
+	return nil
+}
+
+func (s *webhookService) Start() {
+	s.wg.Add(1)
+	go s.runDelivery()
+}
+
+func (s *webhookService) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *webhookService) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *webhookService) runDelivery() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deliverDue()
+		case <-s.wakeCh:
+			s.deliverDue()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+func (s *webhookService) deliverDue() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		deliveries, err := s.repository.ClaimDueDeliveries(ctx, s.options.BatchSize, s.options.Lease)
+		cancel()
+		if err != nil {
+			s.logger.ErrorLogger.Error("Failed to claim webhook deliveries", utils.Err(err))
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		subscriptions := make(map[int64]*domain.WebhookSubscription)
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			subscription, ok := subscriptions[delivery.SubscriptionID]
+			if !ok {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				subscription, err = s.repository.GetSubscriptionByID(ctx, delivery.SubscriptionID)
+				cancel()
+				if err != nil {
+					// Deleted subscriptions take their deliveries with them,
+					// anything else is retried after the lease.
+					s.logger.ErrorLogger.Error("Failed to load webhook subscription", utils.Err(err), "delivery_id", delivery.ID)
+					continue
+				}
+				subscriptions[delivery.SubscriptionID] = subscription
+			}
+
+			wg.Add(1)
+			go func(delivery *domain.WebhookDelivery, subscription *domain.WebhookSubscription) {
+				defer wg.Done()
+				s.deliver(delivery, subscription)
+			}(delivery, subscription)
+		}
+		wg.Wait()
+
+		if len(deliveries) < s.options.BatchSize {
+			return
+		}
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+	}
+}
+
+func (s *webhookService) deliver(delivery *domain.WebhookDelivery, subscription *domain.WebhookSubscription) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.Lease)
+	defer cancel()
+
+	ctx, span := s.tracer.Start(ctx, "Service DeliverWebhook")
+	defer span.End()
+
+	startTime := time.Now()
+	status := "success"
+
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		s.metrics.MethodCount.WithLabelValues("DeliverWebhook", status).Inc()
+		s.metrics.MethodDuration.WithLabelValues("DeliverWebhook", status).Observe(duration)
+	}()
+
+	span.SetAttributes(
+		attribute.Int64("delivery.id", delivery.ID),
+		attribute.Int64("webhook.id", subscription.ID),
+		attribute.Int("delivery.attempt", delivery.Attempts+1),
+	)
+
+	statusCode, sendErr := s.sender.Send(ctx, &webhook.Request{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		Event:      string(delivery.EventType),
+		DeliveryID: delivery.ID,
+		Body:       delivery.Payload,
+	})
+	span.SetAttributes(attribute.Int("http.status_code", statusCode))
+
+	if sendErr == nil {
+		if err := s.repository.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+			status = "error"
+			span.RecordError(err)
+			s.logger.ErrorLogger.Error("Failed to mark webhook delivered", utils.Err(err), "delivery_id", delivery.ID)
+		}
+		return
+	}
+
+	status = "error"
+	span.RecordError(sendErr)
+
+	attempts := delivery.Attempts + 1
+	dead := attempts >= s.options.MaxAttempts
+	if dead {
+		status = "dead"
+		s.logger.ErrorLogger.Error("Webhook delivery moved to dead letters", utils.Err(sendErr), "delivery_id", delivery.ID, "attempts", attempts)
+	}
+
+	message := sendErr.Error()
+	if len(message) > maxDeliveryErrorLength {
+		message = message[:maxDeliveryErrorLength]
+	}
+	if err := s.repository.MarkFailed(ctx, delivery.ID, statusCode, message, expBackoff(s.options.InitialBackoff, s.options.MaxBackoff, attempts), dead); err != nil {
+		span.RecordError(err)
+		s.logger.ErrorLogger.Error("Failed to record webhook delivery failure", utils.Err(err), "delivery_id", delivery.ID)
+	}
+}
+
+func withoutSecret(subscription *domain.WebhookSubscription) *domain.WebhookSubscription {
+	copied := *subscription
+	copied.Secret = ""
+	return &copied
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ad-service/internal/domain"
+	"ad-service/internal/infrastructure/webhook"
+	"ad-service/internal/repository"
+)
+
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+
+	mu         sync.Mutex
+	deliveries map[int64]*domain.WebhookDelivery
+	retryIn    time.Duration
+}
+
+func (r *fakeWebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery := r.deliveries[id]
+	delivery.Status = domain.DeliveryDelivered
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	return nil
+}
+
+func (r *fakeWebhookRepository) MarkFailed(ctx context.Context, id int64, statusCode int, deliveryErr string, retryIn time.Duration, dead bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery := r.deliveries[id]
+	delivery.Status = domain.DeliveryPending
+	if dead {
+		delivery.Status = domain.DeliveryDead
+	}
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = deliveryErr
+	r.retryIn = retryIn
+	return nil
+}
+
+func (r *fakeWebhookRepository) Redeliver(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = 0
+	return nil
+}
+
+// receiver answers webhook deliveries with the status codes it is given in
+// turn.
+func receiver(t *testing.T, statusCodes ...int) *httptest.Server {
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get(webhook.SignatureHeader) == "" {
+			t.Errorf("delivery without %s", webhook.SignatureHeader)
+		}
+		w.WriteHeader(statusCodes[0])
+		if len(statusCodes) > 1 {
+			statusCodes = statusCodes[1:]
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookDeliver(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCodes []int
+		attempts    int
+		status      domain.DeliveryStatus
+		retried     bool
+	}{
+		{name: "delivered", statusCodes: []int{http.StatusOK}, attempts: 1, status: domain.DeliveryDelivered},
+		{name: "retried", statusCodes: []int{http.StatusBadGateway}, attempts: 1, status: domain.DeliveryPending, retried: true},
+		{name: "delivered on retry", statusCodes: []int{http.StatusBadGateway, http.StatusOK}, attempts: 2, status: domain.DeliveryDelivered},
+		{name: "dead", statusCodes: []int{http.StatusInternalServerError}, attempts: 3, status: domain.DeliveryDead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := receiver(t, tt.statusCodes...)
+			delivery := &domain.WebhookDelivery{ID: 1, SubscriptionID: 7, EventType: "ad.created", Payload: []byte(`{}`), Status: domain.DeliveryPending}
+			repo := &fakeWebhookRepository{deliveries: map[int64]*domain.WebhookDelivery{1: delivery}}
+			svc := NewWebhookService(repo, webhook.NewHTTPSender(server.Client()), testMetrics, testLoggers(), WebhookOptions{MaxAttempts: 3}).(*webhookService)
+			subscription := &domain.WebhookSubscription{ID: 7, URL: server.URL, Secret: "secret", Active: true}
+
+			for i := 0; i < tt.attempts; i++ {
+				svc.deliver(&domain.WebhookDelivery{ID: 1, EventType: delivery.EventType, Payload: delivery.Payload, Attempts: delivery.Attempts}, subscription)
+			}
+
+			if delivery.Status != tt.status {
+				t.Errorf("status = %s, want %s", delivery.Status, tt.status)
+			}
+			if delivery.Attempts != tt.attempts {
+				t.Errorf("attempts = %d, want %d", delivery.Attempts, tt.attempts)
+			}
+			if tt.retried && repo.retryIn < svc.options.InitialBackoff {
+				t.Errorf("retry in %s, want at least %s", repo.retryIn, svc.options.InitialBackoff)
+			}
+		})
+	}
+}
+
+func TestWebhookRedeliverDeadDelivery(t *testing.T) {
+	server := receiver(t, http.StatusInternalServerError, http.StatusOK)
+	delivery := &domain.WebhookDelivery{ID: 1, SubscriptionID: 7, EventType: "ad.created", Payload: []byte(`{}`), Status: domain.DeliveryPending}
+	repo := &fakeWebhookRepository{deliveries: map[int64]*domain.WebhookDelivery{1: delivery}}
+	svc := NewWebhookService(repo, webhook.NewHTTPSender(server.Client()), testMetrics, testLoggers(), WebhookOptions{MaxAttempts: 1}).(*webhookService)
+	subscription := &domain.WebhookSubscription{ID: 7, URL: server.URL, Secret: "secret", Active: true}
+
+	svc.deliver(&domain.WebhookDelivery{ID: 1, Payload: delivery.Payload}, subscription)
+	if delivery.Status != domain.DeliveryDead {
+		t.Fatalf("status = %s, want %s", delivery.Status, domain.DeliveryDead)
+	}
+
+	if err := svc.Redeliver(context.Background(), 1); err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	if delivery.Status != domain.DeliveryPending || delivery.Attempts != 0 {
+		t.Fatalf("redelivered delivery is %s after %d attempts", delivery.Status, delivery.Attempts)
+	}
+
+	svc.deliver(&domain.WebhookDelivery{ID: 1, Payload: delivery.Payload}, subscription)
+	if delivery.Status != domain.DeliveryDelivered {
+		t.Errorf("status = %s, want %s", delivery.Status, domain.DeliveryDelivered)
+	}
+
+	if err := svc.Redeliver(context.Background(), 2); err != ErrDeliveryNotFound {
+		t.Errorf("Redeliver() of an unknown delivery = %v, want %v", err, ErrDeliveryNotFound)
+	}
+}
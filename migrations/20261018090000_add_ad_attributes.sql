@@ -0,0 +1,27 @@
+-- +goose Up
+
+ALTER TABLE ads
+    ADD COLUMN category VARCHAR(100) NOT NULL DEFAULT '' AFTER price,
+    ADD COLUMN attributes JSON NULL AFTER category;
+
+CREATE INDEX idx_category ON ads(category);
+
+CREATE TABLE attribute_definitions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    category VARCHAR(100) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    enum_values JSON NULL,
+    min_value DOUBLE NULL,
+    max_value DOUBLE NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_category_name (category, name)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS attribute_definitions;
+DROP INDEX idx_category ON ads;
+ALTER TABLE ads
+    DROP COLUMN attributes,
+    DROP COLUMN category;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+CREATE TABLE tags (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(32) NOT NULL,
+    UNIQUE KEY uq_tag_name (name)
+);
+
+CREATE TABLE ad_tags (
+    ad_id INT NOT NULL,
+    tag_id INT NOT NULL,
+    PRIMARY KEY (ad_id, tag_id),
+    KEY idx_ad_tags_tag_id (tag_id),
+    CONSTRAINT fk_ad_tags_ad FOREIGN KEY (ad_id) REFERENCES ads(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ad_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ad_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+ALTER TABLE ads
+    ADD COLUMN target_url VARCHAR(2048) NOT NULL DEFAULT '' AFTER attributes;
+
+CREATE TABLE ad_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    ad_id INT NOT NULL,
+    event_type VARCHAR(16) NOT NULL,
+    viewer_id VARCHAR(64) NOT NULL,
+    occurred_at TIMESTAMP(3) NOT NULL,
+    KEY idx_ad_events_ad_occurred (ad_id, occurred_at),
+    CONSTRAINT fk_ad_events_ad FOREIGN KEY (ad_id) REFERENCES ads(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ad_events;
+ALTER TABLE ads
+    DROP COLUMN target_url;
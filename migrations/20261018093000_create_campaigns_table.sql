@@ -0,0 +1,33 @@
+-- +goose Up
+
+CREATE TABLE campaigns (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    total_budget DECIMAL(12, 2) NOT NULL,
+    daily_budget DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    spent DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    pacing_mode VARCHAR(16) NOT NULL DEFAULT 'standard',
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE campaign_daily_spend (
+    campaign_id INT NOT NULL,
+    day DATE NOT NULL,
+    amount DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    PRIMARY KEY (campaign_id, day),
+    CONSTRAINT fk_campaign_daily_spend_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns(id) ON DELETE CASCADE
+);
+
+ALTER TABLE ads
+    ADD COLUMN campaign_id INT NULL AFTER target_url,
+    ADD CONSTRAINT fk_ads_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE ads
+    DROP FOREIGN KEY fk_ads_campaign,
+    DROP COLUMN campaign_id;
+DROP TABLE IF EXISTS campaign_daily_spend;
+DROP TABLE IF EXISTS campaigns;
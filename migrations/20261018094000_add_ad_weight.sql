@@ -0,0 +1,11 @@
+-- +goose Up
+
+ALTER TABLE ads
+    ADD COLUMN weight INT NOT NULL DEFAULT 1 AFTER campaign_id;
+
+CREATE INDEX idx_active_weight ON ads(active, weight);
+
+-- +goose Down
+DROP INDEX idx_active_weight ON ads;
+ALTER TABLE ads
+    DROP COLUMN weight;
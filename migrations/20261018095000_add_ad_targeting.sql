@@ -0,0 +1,8 @@
+-- +goose Up
+
+ALTER TABLE ads
+    ADD COLUMN targeting JSON NULL AFTER weight;
+
+-- +goose Down
+ALTER TABLE ads
+    DROP COLUMN targeting;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+CREATE TABLE ad_variants (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ad_id INT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    weight INT NOT NULL DEFAULT 1,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_ad_variant_name (ad_id, name),
+    CONSTRAINT fk_ad_variants_ad FOREIGN KEY (ad_id) REFERENCES ads(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ad_variants;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE favorites (
+    user_id VARCHAR(64) NOT NULL,
+    ad_id INT NOT NULL,
+    favorited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_favorites_user_ad UNIQUE (user_id, ad_id),
+    INDEX idx_favorites_ad (ad_id),
+    INDEX idx_favorites_user_time (user_id, favorited_at),
+    CONSTRAINT fk_favorites_ad FOREIGN KEY (ad_id) REFERENCES ads(id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS favorites;
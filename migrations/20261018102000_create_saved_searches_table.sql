@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE saved_searches (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    query TEXT NOT NULL,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    filter JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_saved_searches_user (user_id),
+    INDEX idx_saved_searches_category (category)
+);
+
+CREATE TABLE saved_search_matches (
+    saved_search_id INT NOT NULL,
+    ad_id INT NOT NULL,
+    matched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (saved_search_id, ad_id),
+    CONSTRAINT fk_saved_search_matches_search FOREIGN KEY (saved_search_id) REFERENCES saved_searches(id) ON DELETE CASCADE,
+    CONSTRAINT fk_saved_search_matches_ad FOREIGN KEY (ad_id) REFERENCES ads(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS saved_search_matches;
+DROP TABLE IF EXISTS saved_searches;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    aggregate_id INT NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    payload JSON NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    published_at TIMESTAMP(6) NULL,
+    INDEX idx_outbox_unpublished (published_at, id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,39 @@
+-- +goose Up
+
+ALTER TABLE ads
+    ADD COLUMN external_ref VARCHAR(191) NULL AFTER id,
+    ADD UNIQUE INDEX idx_ads_external_ref (external_ref);
+
+CREATE TABLE import_jobs (
+    id CHAR(32) PRIMARY KEY,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    format VARCHAR(16) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    total_rows INT NOT NULL DEFAULT 0,
+    created_rows INT NOT NULL DEFAULT 0,
+    updated_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    error TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    INDEX idx_import_jobs_status (status)
+);
+
+CREATE TABLE import_errors (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    job_id CHAR(32) NOT NULL,
+    row_num INT NOT NULL,
+    external_ref VARCHAR(191) NULL,
+    field VARCHAR(128) NOT NULL DEFAULT '',
+    message VARCHAR(512) NOT NULL,
+    INDEX idx_import_errors_job (job_id, row_num),
+    CONSTRAINT fk_import_errors_job FOREIGN KEY (job_id) REFERENCES import_jobs(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS import_errors;
+DROP TABLE IF EXISTS import_jobs;
+ALTER TABLE ads
+    DROP INDEX idx_ads_external_ref,
+    DROP COLUMN external_ref;
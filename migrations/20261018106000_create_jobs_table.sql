@@ -0,0 +1,28 @@
+-- +goose Up
+
+CREATE TABLE jobs (
+    id CHAR(32) PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    payload JSON NULL,
+    result JSON NULL,
+    progress_done BIGINT NOT NULL DEFAULT 0,
+    progress_total BIGINT NOT NULL DEFAULT 0,
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 1,
+    error TEXT NULL,
+    cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lease_owner VARCHAR(128) NULL,
+    lease_expires_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    INDEX idx_jobs_due (status, run_at),
+    INDEX idx_jobs_lease (status, lease_expires_at),
+    INDEX idx_jobs_type (type, created_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS jobs;
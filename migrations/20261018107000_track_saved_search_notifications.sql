@@ -0,0 +1,17 @@
+-- +goose Up
+
+ALTER TABLE saved_search_matches
+    ADD COLUMN notification JSON NULL,
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN notified_at TIMESTAMP NULL,
+    ADD INDEX idx_saved_search_matches_pending (notified_at, matched_at);
+
+-- Matches recorded before notifications were tracked were notified inline.
+UPDATE saved_search_matches SET notified_at = matched_at;
+
+-- +goose Down
+ALTER TABLE saved_search_matches
+    DROP INDEX idx_saved_search_matches_pending,
+    DROP COLUMN notified_at,
+    DROP COLUMN attempts,
+    DROP COLUMN notification;
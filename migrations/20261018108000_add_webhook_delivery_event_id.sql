@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- The outbox relays events at least once; the event id makes enqueueing
+-- the deliveries of an event idempotent.
+ALTER TABLE webhook_deliveries
+    ADD COLUMN event_id BIGINT NULL AFTER subscription_id,
+    ADD UNIQUE INDEX uq_webhook_deliveries_event (subscription_id, event_id);
+
+-- +goose Down
+ALTER TABLE webhook_deliveries
+    DROP INDEX uq_webhook_deliveries_event,
+    DROP COLUMN event_id;
@@ -0,0 +1,22 @@
+-- +goose Up
+
+-- Tracked events can be delivered more than once; charging every event
+-- once by its id keeps campaign spend exact.
+CREATE TABLE campaign_charges (
+    event_id CHAR(32) PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    amount DECIMAL(14, 4) NOT NULL,
+    charged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_campaign_charges_campaign (campaign_id),
+    CONSTRAINT fk_campaign_charges_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns(id) ON DELETE CASCADE
+);
+
+-- Ads paused for a spent daily budget are resumed once paused_until has
+-- passed; ads paused for a spent total budget have none.
+ALTER TABLE ads
+    ADD COLUMN paused_until TIMESTAMP NULL AFTER active;
+
+-- +goose Down
+ALTER TABLE ads
+    DROP COLUMN paused_until;
+DROP TABLE IF EXISTS campaign_charges;
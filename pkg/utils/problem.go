@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "/problems/"
+)
+
+// Problem is an RFC 7807 problem details object. Code is a stable identifier
+// clients can branch on; Type is the same identifier as a URI reference.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError points at the part of a request that is wrong: a parameter by
+// name or a body value by JSON pointer.
+type FieldError struct {
+	In      string `json:"in"`
+	Name    string `json:"name,omitempty"`
+	Pointer string `json:"pointer,omitempty"`
+	Message string `json:"message"`
+}
+
+func ProblemType(code string) string {
+	return problemTypePrefix + code
+}
+
+// RespondWithProblem writes p as application/problem+json. The request id
+// is taken from the response headers when p has none.
+func RespondWithProblem(w http.ResponseWriter, p *Problem) {
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get(RequestIDHeader)
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
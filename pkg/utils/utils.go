@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strings"
 )
 
 func Err(err error) slog.Attr {
@@ -13,23 +14,26 @@ func Err(err error) slog.Attr {
 	}
 }
 
-func RespondWithErrorJSON(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-
-	jsonError := struct {
-		Status  int    `json:"status"`
-		Message string `json:"message"`
-	}{
-		Status:  status,
-		Message: message,
-	}
-
-	json.NewEncoder(w).Encode(jsonError)
-}
-
 func RespondWithJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
+
+// AddVary adds the request header names to the Vary header of a response
+// unless they are listed already.
+func AddVary(header http.Header, names ...string) {
+	for _, name := range names {
+		listed := false
+		for _, value := range header.Values("Vary") {
+			for _, field := range strings.Split(value, ",") {
+				if strings.EqualFold(strings.TrimSpace(field), name) {
+					listed = true
+				}
+			}
+		}
+		if !listed {
+			header.Add("Vary", name)
+		}
+	}
+}